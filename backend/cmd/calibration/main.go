@@ -0,0 +1,89 @@
+// Command calibration prints the memory model calibration report: how well the recall probabilities
+// predicted at review time match the actual success rates of past reviews.
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"text/tabwriter"
+	"time"
+
+	"sentencease/backend/internal/config"
+	"sentencease/backend/internal/database"
+	"sentencease/backend/internal/models"
+	"sentencease/backend/internal/srs"
+)
+
+func main() {
+	days := flag.Int("days", 0, "only include reviews from the last N days (0 = all)")
+	bins := flag.Int("bins", srs.DefaultCalibrationBins, "number of calibration curve buckets")
+	asJSON := flag.Bool("json", false, "print the report as JSON")
+	flag.Parse()
+
+	cfg, err := config.Load()
+	if err != nil {
+		log.Fatalf("could not load config: %v", err)
+	}
+
+	db, err := database.Connect(cfg.DatabaseURL)
+	if err != nil {
+		log.Fatalf("could not connect to database: %v", err)
+	}
+	defer db.Close()
+
+	var since *time.Time
+	if *days > 0 {
+		t := time.Now().AddDate(0, 0, -*days)
+		since = &t
+	}
+
+	report, err := srs.Calibration(context.Background(), db, since, *bins)
+	if err != nil {
+		log.Fatalf("could not compute calibration report: %v", err)
+	}
+
+	if *asJSON {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		if err := enc.Encode(report); err != nil {
+			log.Fatalf("could not encode report: %v", err)
+		}
+		return
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', tabwriter.AlignRight)
+	printGroups(w, "Overall", []models.CalibrationGroup{report.Overall})
+	printCurve(w, report.Overall)
+	printGroups(w, "By algorithm", report.ByAlgorithm)
+	printGroups(w, "By difficulty", report.ByDifficulty)
+	printGroups(w, "By earlier reviews", report.ByReviewCount)
+	w.Flush()
+}
+
+func printGroups(w *tabwriter.Writer, title string, groups []models.CalibrationGroup) {
+	fmt.Fprintf(w, "\n%s\n", title)
+	fmt.Fprintln(w, "group\treviews\tpredicted\tactual\tbrier\tlog-loss\t")
+	for _, g := range groups {
+		fmt.Fprintf(w, "%s\t%d\t%.3f\t%.3f\t%.4f\t%.4f\t\n",
+			g.Name, g.Reviews, g.MeanPredicted, g.SuccessRate, g.BrierScore, g.LogLoss)
+	}
+}
+
+func printCurve(w *tabwriter.Writer, g models.CalibrationGroup) {
+	fmt.Fprintf(w, "\nCalibration curve\n")
+	fmt.Fprintln(w, "predicted\treviews\tmean predicted\tactual\t")
+	for _, bin := range g.Curve {
+		fmt.Fprintf(w, "%.2f-%.2f\t%d\t%s\t%s\t\n", bin.Low, bin.High, bin.Reviews, rate(bin.MeanPredicted), rate(bin.SuccessRate))
+	}
+}
+
+func rate(v *float64) string {
+	if v == nil {
+		return "-"
+	}
+	return fmt.Sprintf("%.3f", *v)
+}
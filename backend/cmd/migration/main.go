@@ -1,15 +1,32 @@
+// Command migration applies the SQL files in db/migrations that have not been applied yet, in file
+// name order. Applied files are recorded in schema_migrations; each file runs in its own
+// transaction together with its record, so a failed migration can simply be fixed and re-run.
+//
+// Every migration is also safe to run again (IF NOT EXISTS, ON CONFLICT DO NOTHING, constraints
+// dropped before being re-added). Databases created by the docker-compose init scripts, which run
+// all files without recording them, are brought under the runner by running it once.
 package main
 
 import (
 	"context"
+	"flag"
 	"fmt"
 	"log"
 	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
 	"sentencease/backend/internal/config"
 	"sentencease/backend/internal/database"
+
+	"github.com/jackc/pgx/v5/pgxpool"
 )
 
 func main() {
+	dir := flag.String("dir", "db/migrations", "directory containing the migration files")
+	flag.Parse()
+
 	// 加载配置
 	cfg, err := config.Load()
 	if err != nil {
@@ -25,19 +42,11 @@ func main() {
 
 	ctx := context.Background()
 
-	// 读取迁移文件内容
-	migrationSQL, err := os.ReadFile("db/migrations/0005_add_sspmmc_support.sql")
-	if err != nil {
-		log.Fatalf("Failed to read migration file: %v", err)
-	}
-
-	// 执行迁移
-	_, err = db.Exec(ctx, string(migrationSQL))
+	applied, err := migrate(ctx, db, *dir)
 	if err != nil {
-		log.Fatalf("Failed to execute migration: %v", err)
+		log.Fatalf("Migration failed: %v", err)
 	}
-
-	fmt.Println("Migration completed successfully!")
+	fmt.Printf("Migration completed successfully! %d migration(s) applied.\n", applied)
 
 	// 为现有单词设置默认难度值
 	_, err = db.Exec(ctx, `
@@ -50,3 +59,78 @@ func main() {
 
 	fmt.Println("Default difficulty values set for existing words!")
 }
+
+// migrate applies the pending migrations in dir and returns how many it applied.
+func migrate(ctx context.Context, db *pgxpool.Pool, dir string) (int, error) {
+	_, err := db.Exec(ctx, `
+		CREATE TABLE IF NOT EXISTS schema_migrations (
+			version TEXT PRIMARY KEY,
+			applied_at TIMESTAMPTZ NOT NULL DEFAULT now()
+		)`)
+	if err != nil {
+		return 0, err
+	}
+
+	files, err := filepath.Glob(filepath.Join(dir, "*.sql"))
+	if err != nil {
+		return 0, err
+	}
+	if len(files) == 0 {
+		return 0, fmt.Errorf("no migration files found in %s", dir)
+	}
+	sort.Strings(files)
+
+	rows, err := db.Query(ctx, `SELECT version FROM schema_migrations`)
+	if err != nil {
+		return 0, err
+	}
+	done := make(map[string]bool)
+	for rows.Next() {
+		var version string
+		if err := rows.Scan(&version); err != nil {
+			rows.Close()
+			return 0, err
+		}
+		done[version] = true
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return 0, err
+	}
+
+	applied := 0
+	for _, file := range files {
+		version := strings.TrimSuffix(filepath.Base(file), ".sql")
+		if done[version] {
+			continue
+		}
+		if err := applyMigration(ctx, db, file, version); err != nil {
+			return applied, fmt.Errorf("%s: %w", filepath.Base(file), err)
+		}
+		log.Printf("Applied migration %s", version)
+		applied++
+	}
+	return applied, nil
+}
+
+// applyMigration runs one migration file and records it in the same transaction.
+func applyMigration(ctx context.Context, db *pgxpool.Pool, file, version string) error {
+	migrationSQL, err := os.ReadFile(file)
+	if err != nil {
+		return err
+	}
+
+	tx, err := db.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	if _, err := tx.Exec(ctx, string(migrationSQL)); err != nil {
+		return err
+	}
+	if _, err := tx.Exec(ctx, `INSERT INTO schema_migrations (version) VALUES ($1)`, version); err != nil {
+		return err
+	}
+	return tx.Commit(ctx)
+}
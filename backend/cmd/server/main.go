@@ -2,8 +2,10 @@ package main
 
 import (
 	"log"
+	_ "time/tzdata" // Embed the IANA time zone database; the runtime image has none
 
 	"sentencease/backend/internal/api"
+	"sentencease/backend/internal/auth"
 	"sentencease/backend/internal/config"
 	"sentencease/backend/internal/database"
 
@@ -31,15 +33,28 @@ func main() {
 	// Setup CORS middleware
 	router.Use(cors.New(cors.Config{
 		AllowOrigins:     []string{"*"},
-		AllowMethods:     []string{"GET", "POST", "PUT", "DELETE", "OPTIONS"},
+		AllowMethods:     []string{"GET", "POST", "PUT", "PATCH", "DELETE", "OPTIONS"},
 		AllowHeaders:     []string{"Origin", "Content-Type", "Authorization"},
-		ExposeHeaders:    []string{"Content-Length"},
+		ExposeHeaders:    []string{"Content-Length", "X-Total-Count"},
 		AllowCredentials: true,
 	}))
 
 	// Create API handler instance
 	apiHandler := api.New(dbPool, cfg.JWTSecretKey)
 
+	// Enable login through an external OpenID Connect provider if configured
+	if cfg.OIDCIssuerURL != "" {
+		apiHandler.OIDC = auth.NewOIDCProvider(auth.OIDCConfig{
+			Name:         cfg.OIDCProviderName,
+			IssuerURL:    cfg.OIDCIssuerURL,
+			ClientID:     cfg.OIDCClientID,
+			ClientSecret: cfg.OIDCClientSecret,
+			RedirectURL:  cfg.OIDCRedirectURL,
+		})
+		apiHandler.OIDCPostLoginRedirect = cfg.OIDCPostLoginRedirect
+	}
+	apiHandler.VAPIDPublicKey = cfg.VAPIDPublicKey
+
 	router.GET("/", apiHandler.RootHandler) // Keep a root handler for health checks
 
 	// Group all routes under /api/v1
@@ -50,6 +65,8 @@ func main() {
 		{
 			authRoutes.POST("/register", apiHandler.Register)
 			authRoutes.POST("/login", apiHandler.Login)
+			authRoutes.GET("/oidc/login", apiHandler.OIDCLogin)
+			authRoutes.GET("/oidc/callback", apiHandler.OIDCCallback)
 		}
 
 		// Group for authenticated routes
@@ -60,12 +77,99 @@ func main() {
 			authRequired.GET("/learn/peek-next-word", apiHandler.PeekNextWord)
 			authRequired.POST("/learn/review", apiHandler.ReviewWord)
 			authRequired.GET("/learn/progress", apiHandler.GetLearningProgress)
+			authRequired.GET("/learn/forecast", apiHandler.GetForecast)
+			authRequired.POST("/learn/reschedule-backlog", apiHandler.RescheduleBacklog)
+			authRequired.GET("/learn/leeches", apiHandler.GetLeeches)
+			authRequired.GET("/learn/leeches/next", apiHandler.GetNextLeechRemediation)
+			authRequired.POST("/learn/leeches/answer", apiHandler.AnswerLeechRemediation)
+			authRequired.POST("/cram-sessions", apiHandler.CreateCramSession)
+			authRequired.GET("/cram-sessions/:id", apiHandler.GetCramSession)
+			authRequired.GET("/cram-sessions/:id/next", apiHandler.GetNextCramCard)
+			authRequired.POST("/cram-sessions/:id/review", apiHandler.ReviewCramCard)
+			authRequired.GET("/vacations", apiHandler.ListVacations)
+			authRequired.POST("/vacations", apiHandler.CreateVacation)
+			authRequired.DELETE("/vacations/:id", apiHandler.CancelVacation)
+			authRequired.GET("/cards", apiHandler.ListCards)
+			authRequired.PUT("/cards/:meaningId/state", apiHandler.SetCardState)
 			authRequired.GET("/user/stats", apiHandler.GetUserStats)
+			authRequired.GET("/analytics/heatmap", apiHandler.GetReviewHeatmap)
+			authRequired.GET("/analytics/streaks", apiHandler.GetStudyStreaks)
+			authRequired.GET("/analytics/retention", apiHandler.GetRetention)
+			authRequired.GET("/analytics/time-spent", apiHandler.GetTimeSpent)
+			authRequired.GET("/analytics/mastery", apiHandler.GetSourceMastery)
+			authRequired.GET("/groups", apiHandler.ListStudyGroups)
+			authRequired.POST("/groups", apiHandler.CreateStudyGroup)
+			authRequired.POST("/groups/join", apiHandler.JoinStudyGroup)
+			authRequired.GET("/groups/:id", apiHandler.GetStudyGroup)
+			authRequired.DELETE("/groups/:id", apiHandler.DeleteStudyGroup)
+			authRequired.DELETE("/groups/:id/members/me", apiHandler.LeaveStudyGroup)
+			authRequired.PATCH("/groups/:id/members/:userId", apiHandler.UpdateStudyGroupMember)
+			authRequired.DELETE("/groups/:id/members/:userId", apiHandler.RemoveStudyGroupMember)
+			authRequired.POST("/groups/:id/invite-code", apiHandler.RegenerateInviteCode)
+			authRequired.GET("/groups/:id/leaderboard", apiHandler.GetGroupLeaderboard)
+			authRequired.GET("/groups/:id/progress", apiHandler.GetGroupProgress)
+			authRequired.GET("/groups/:id/assignments", apiHandler.ListAssignments)
+			authRequired.POST("/groups/:id/assignments", apiHandler.CreateAssignment)
+			authRequired.DELETE("/groups/:id/assignments/:assignmentId", apiHandler.DeleteAssignment)
+			authRequired.GET("/groups/:id/assignments/:assignmentId/dashboard", apiHandler.GetAssignmentDashboard)
+			authRequired.GET("/achievements", apiHandler.ListAchievements)
+			authRequired.GET("/notifications/settings", apiHandler.GetNotificationSettings)
+			authRequired.PUT("/notifications/settings", apiHandler.UpdateNotificationSettings)
+			authRequired.GET("/notifications/channels", apiHandler.ListNotificationChannels)
+			authRequired.POST("/notifications/channels", apiHandler.CreateNotificationChannel)
+			authRequired.DELETE("/notifications/channels/:id", apiHandler.DeleteNotificationChannel)
+			authRequired.GET("/notifications/log", apiHandler.ListNotificationLog)
+			authRequired.GET("/notifications/vapid-public-key", apiHandler.GetVAPIDPublicKey)
+			authRequired.GET("/webhooks", apiHandler.ListWebhooks)
+			authRequired.POST("/webhooks", apiHandler.CreateWebhook)
+			authRequired.PATCH("/webhooks/:id", apiHandler.UpdateWebhook)
+			authRequired.DELETE("/webhooks/:id", apiHandler.DeleteWebhook)
+			authRequired.GET("/webhooks/:id/deliveries", apiHandler.ListWebhookDeliveries)
+			authRequired.GET("/webhooks/:id/deliveries/:deliveryId", apiHandler.GetWebhookDelivery)
+			authRequired.POST("/webhooks/:id/deliveries/:deliveryId/redeliver", apiHandler.RedeliverWebhookDelivery)
+			authRequired.GET("/user/profile", apiHandler.GetProfile)
+			authRequired.PATCH("/user/profile", apiHandler.UpdateProfile)
+			authRequired.POST("/user/change-password", apiHandler.ChangePassword)
+			authRequired.GET("/user/export", apiHandler.ExportUserData)
+			authRequired.DELETE("/user", apiHandler.DeleteAccount)
 			authRequired.GET("/srs/info", apiHandler.GetSRSAlgorithmInfo)
 			authRequired.GET("/vocab-sources", apiHandler.GetVocabSources)
 			authRequired.GET("/words/selection", apiHandler.GetWordsForSelection)
 			authRequired.GET("/vocab-sources/:source/words", apiHandler.GetWordsBySource)
+			authRequired.PUT("/vocab-sources/:source/granularity", apiHandler.SetSourceGranularity)
+			authRequired.DELETE("/vocab-sources/:source/granularity", apiHandler.ClearSourceGranularity)
 			authRequired.POST("/daily-plan", apiHandler.CreateDailyPlan)
+			authRequired.GET("/daily-plans", apiHandler.ListDailyPlans)
+			authRequired.POST("/daily-plans", apiHandler.CreateDailyPlan)
+			authRequired.GET("/daily-plans/:id", apiHandler.GetDailyPlan)
+			authRequired.PATCH("/daily-plans/:id", apiHandler.UpdateDailyPlan)
+			authRequired.DELETE("/daily-plans/:id", apiHandler.DeleteDailyPlan)
+			authRequired.POST("/placement-tests", apiHandler.StartPlacementTest)
+			authRequired.GET("/placement-tests/:id", apiHandler.GetPlacementTest)
+			authRequired.POST("/placement-tests/:id/answers", apiHandler.AnswerPlacementQuestion)
+			authRequired.POST("/placement-tests/:id/apply", apiHandler.ApplyPlacementTest)
+			authRequired.GET("/study-plans", apiHandler.ListStudyPlans)
+			authRequired.POST("/study-plans", apiHandler.CreateStudyPlan)
+			authRequired.PATCH("/study-plans/:id", apiHandler.UpdateStudyPlan)
+			authRequired.DELETE("/study-plans/:id", apiHandler.DeleteStudyPlan)
+		}
+
+		// Administrator routes
+		adminRoutes := v1.Group("/admin")
+		adminRoutes.Use(api.AuthMiddleware(cfg.JWTSecretKey), api.AdminMiddleware(dbPool))
+		{
+			adminRoutes.GET("/calibration", apiHandler.GetCalibrationReport)
+			adminRoutes.GET("/experiments", apiHandler.ListExperiments)
+			adminRoutes.POST("/experiments", apiHandler.CreateExperiment)
+			adminRoutes.POST("/experiments/:id/stop", apiHandler.StopExperiment)
+			adminRoutes.GET("/experiments/:id/report", apiHandler.GetExperimentReport)
+			adminRoutes.GET("/webhooks", apiHandler.ListWebhooks)
+			adminRoutes.POST("/webhooks", apiHandler.CreateWebhook)
+			adminRoutes.PATCH("/webhooks/:id", apiHandler.UpdateWebhook)
+			adminRoutes.DELETE("/webhooks/:id", apiHandler.DeleteWebhook)
+			adminRoutes.GET("/webhooks/:id/deliveries", apiHandler.ListWebhookDeliveries)
+			adminRoutes.GET("/webhooks/:id/deliveries/:deliveryId", apiHandler.GetWebhookDelivery)
+			adminRoutes.POST("/webhooks/:id/deliveries/:deliveryId/redeliver", apiHandler.RedeliverWebhookDelivery)
 		}
 
 		// Debug routes - remove in production
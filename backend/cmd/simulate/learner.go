@@ -0,0 +1,177 @@
+package main
+
+import (
+	"container/heap"
+	"math"
+	"math/rand"
+	"time"
+
+	"sentencease/backend/internal/srs"
+)
+
+// population configures the synthetic learners and the ground-truth forgetting model.
+type population struct {
+	Learners     int
+	Cards        int     // Cards in each learner's deck
+	NewPerDay    int     // New cards introduced per day
+	MaxPerDay    int     // Review limit per day, 0 for none
+	AbilitySD    float64 // Spread of learner ability (log-scale memory strength)
+	PriorKnown   float64 // Probability that a new card is already known
+	InitialHours float64 // Half-life after first studying a card of difficulty 0.5, for an average learner
+	Seed         int64
+	Fuzz         bool // Apply interval fuzz as in production
+
+	NewSeconds     float64 // Cost of studying a new card
+	SuccessSeconds float64 // Cost of a successful review
+	FailSeconds    float64 // Cost of a failed review
+}
+
+// trueCard is a card as the ground-truth model sees it, with the scheduler's state alongside.
+type trueCard struct {
+	difficulty float64 // True difficulty in [0, 1], also known to the scheduler
+	halflife   float64 // True memory half-life in hours; 0 until the card is introduced
+	lastReview float64 // Hours since the start of the simulation
+	due        float64
+	sched      srs.SchedulerCard
+}
+
+// recall returns the true probability of recalling the card at time t (hours).
+func (c *trueCard) recall(t float64) float64 {
+	if c.halflife == 0 {
+		return 0
+	}
+	return math.Exp2(-(t - c.lastReview) / c.halflife)
+}
+
+// learner is one synthetic learner with a deck of cards.
+type learner struct {
+	ability float64
+	cards   []*trueCard
+	next    int // Next card to introduce
+	queue   dueQueue
+}
+
+// dayStats aggregates one simulated day over all learners.
+type dayStats struct {
+	Day         int     `json:"day"`
+	NewCards    int     `json:"newCards"`
+	Reviews     int     `json:"reviews"` // Reviews of cards studied before, excluding new cards
+	Successes   int     `json:"successes"`
+	Knowledge   float64 `json:"knowledge"`   // Expected number of cards recalled at the end of the day, per learner
+	CostMinutes float64 `json:"costMinutes"` // Study time, per learner
+}
+
+// newPopulation draws the learners and their decks. The same seed yields the same population, so
+// schedulers are compared on identical learners.
+func newPopulation(p population) []*learner {
+	r := rand.New(rand.NewSource(p.Seed))
+	learners := make([]*learner, p.Learners)
+	for i := range learners {
+		l := &learner{ability: r.NormFloat64() * p.AbilitySD}
+		for j := 0; j < p.Cards; j++ {
+			l.cards = append(l.cards, &trueCard{difficulty: r.Float64()})
+		}
+		learners[i] = l
+	}
+	return learners
+}
+
+// simulate runs a scheduler over the population for the given number of days.
+func simulate(p population, scheduler srs.Scheduler, days int) []dayStats {
+	learners := newPopulation(p)
+	r := rand.New(rand.NewSource(p.Seed + 1))
+	stats := make([]dayStats, days)
+
+	for day := 0; day < days; day++ {
+		s := &stats[day]
+		s.Day = day + 1
+		dayStart, dayEnd := float64(day*24), float64(day*24+24)
+
+		for _, l := range learners {
+			// 1. 新词在当天开始时学习
+			for n := 0; n < p.NewPerDay && l.next < len(l.cards); n++ {
+				c := l.cards[l.next]
+				l.next++
+				known := r.Float64() < p.PriorKnown
+				c.halflife = p.InitialHours * math.Exp(l.ability-2*(c.difficulty-0.5))
+				c.sched = srs.SchedulerCard{HalfLife: srs.NewCardHalfLife, Difficulty: c.difficulty}
+				l.review(c, dayStart, known, p, scheduler, r)
+				s.NewCards++
+				s.CostMinutes += p.NewSeconds / 60
+			}
+
+			// 2. 到期的复习，按到期时间先后；当天再次到期的卡片（包括刚学的新词）当天复习
+			done := 0
+			for l.queue.Len() > 0 && l.queue[0].due < dayEnd && (p.MaxPerDay == 0 || done < p.MaxPerDay) {
+				c := heap.Pop(&l.queue).(*trueCard)
+				t := math.Max(c.due, dayStart)
+				success := r.Float64() < c.recall(t)
+				l.review(c, t, success, p, scheduler, r)
+				done++
+
+				s.Reviews++
+				if success {
+					s.Successes++
+					s.CostMinutes += p.SuccessSeconds / 60
+				} else {
+					s.CostMinutes += p.FailSeconds / 60
+				}
+			}
+		}
+
+		for _, l := range learners {
+			for _, c := range l.cards[:l.next] {
+				s.Knowledge += c.recall(dayEnd)
+			}
+		}
+		s.Knowledge /= float64(len(learners))
+		s.CostMinutes /= float64(len(learners))
+	}
+	return stats
+}
+
+// review applies one answer at time t to the true memory and the scheduler, and requeues the card.
+func (l *learner) review(c *trueCard, t float64, success bool, p population, scheduler srs.Scheduler, r *rand.Rand) {
+	choice := "不认识"
+	if success {
+		choice = "认识"
+	}
+
+	var elapsed time.Duration
+	if c.sched.ReviewCount > 0 {
+		elapsed = time.Duration((t - c.lastReview) * float64(time.Hour))
+	}
+
+	// 真实记忆：成功回忆使半衰期增长，回忆越难增长越多；遗忘则大幅缩短
+	if c.sched.ReviewCount > 0 {
+		if success {
+			pRecall := c.recall(t)
+			c.halflife *= 1 + math.Exp(l.ability)*(1.5-c.difficulty)*(math.Exp(2.5*(1-pRecall))-1)
+		} else {
+			c.halflife = math.Max(1, c.halflife*0.3)
+		}
+	}
+	c.lastReview = t
+
+	interval := scheduler.Review(&c.sched, choice, elapsed)
+	if p.Fuzz {
+		interval = srs.FuzzInterval(interval, r)
+	}
+	c.due = t + interval.Hours()
+	heap.Push(&l.queue, c)
+}
+
+// dueQueue is a min-heap of cards by due time.
+type dueQueue []*trueCard
+
+func (q dueQueue) Len() int           { return len(q) }
+func (q dueQueue) Less(i, j int) bool { return q[i].due < q[j].due }
+func (q dueQueue) Swap(i, j int)      { q[i], q[j] = q[j], q[i] }
+func (q *dueQueue) Push(x any)        { *q = append(*q, x.(*trueCard)) }
+
+func (q *dueQueue) Pop() any {
+	old := *q
+	c := old[len(old)-1]
+	*q = old[:len(old)-1]
+	return c
+}
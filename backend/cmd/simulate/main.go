@@ -0,0 +1,195 @@
+// Command simulate compares the schedulers of package srs offline.
+//
+// By default it drives each scheduler against a population of synthetic learners whose memory
+// follows a ground-truth forgetting model, and reports reviews per day, retention, knowledge
+// acquired and study cost. With -replay it instead feeds the real answer sequences from review_log
+// to each scheduler and compares the intervals it would have chosen with the actual outcomes.
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"sentencease/backend/internal/config"
+	"sentencease/backend/internal/database"
+	"sentencease/backend/internal/models"
+	"sentencease/backend/internal/srs"
+)
+
+// simulationResult is the outcome of one scheduler on the synthetic population.
+type simulationResult struct {
+	Algorithm        string     `json:"algorithm"`
+	Reviews          float64    `json:"reviewsPerLearner"`
+	ReviewsPerDay    float64    `json:"reviewsPerDay"`
+	Retention        float64    `json:"retention"`
+	FinalKnowledge   float64    `json:"finalKnowledge"` // Expected cards recalled on the last day, per learner
+	CostHours        float64    `json:"costHours"`      // Total study time, per learner
+	KnowledgePerHour float64    `json:"knowledgePerHour"`
+	Daily            []dayStats `json:"daily,omitempty"`
+}
+
+func main() {
+	algorithms := flag.String("algorithms", strings.Join(srs.SchedulerNames, ","), "comma-separated schedulers to compare")
+	days := flag.Int("days", 365, "number of simulated days")
+	daily := flag.Bool("daily", false, "print per-day statistics")
+	asJSON := flag.Bool("json", false, "print the results as JSON")
+	replaySince := flag.Int("replay", 0, "replay the real review logs of the last N days instead of simulating (needs DATABASE_URL)")
+
+	var p population
+	flag.IntVar(&p.Learners, "learners", 100, "number of synthetic learners")
+	flag.IntVar(&p.Cards, "cards", 2000, "cards in each learner's deck")
+	flag.IntVar(&p.NewPerDay, "new-per-day", 20, "new cards per day")
+	flag.IntVar(&p.MaxPerDay, "max-reviews", 0, "review limit per learner and day (0 = none)")
+	flag.Float64Var(&p.AbilitySD, "ability-sd", 0.3, "standard deviation of learner ability")
+	flag.Float64Var(&p.PriorKnown, "prior-known", 0.1, "probability that a new card is already known")
+	flag.Float64Var(&p.InitialHours, "initial-halflife", 12, "true half-life in hours after first studying an average card")
+	flag.Int64Var(&p.Seed, "seed", 1, "random seed")
+	flag.BoolVar(&p.Fuzz, "fuzz", true, "apply interval fuzz as in production")
+	flag.Float64Var(&p.NewSeconds, "new-seconds", 20, "seconds spent studying a new card")
+	flag.Float64Var(&p.SuccessSeconds, "success-seconds", 8, "seconds spent on a successful review")
+	flag.Float64Var(&p.FailSeconds, "fail-seconds", 15, "seconds spent on a failed review")
+	flag.Parse()
+
+	schedulers := make(map[string]srs.Scheduler)
+	var names []string
+	for _, name := range strings.Split(*algorithms, ",") {
+		name = strings.TrimSpace(name)
+		s, err := srs.NewScheduler(name, models.SchedulerParams{})
+		if err != nil {
+			log.Fatalf("%v (available: %s)", err, strings.Join(srs.SchedulerNames, ", "))
+		}
+		schedulers[name] = s
+		names = append(names, name)
+	}
+
+	if *replaySince > 0 {
+		runReplay(names, schedulers, *replaySince, *asJSON)
+		return
+	}
+
+	if *days < 1 || p.Learners < 1 || p.Cards < 1 {
+		log.Fatal("days, learners and cards must be positive")
+	}
+
+	var results []simulationResult
+	for _, name := range names {
+		stats := simulate(p, schedulers[name], *days)
+		results = append(results, summarise(name, stats, *days, p.Learners, *daily))
+	}
+
+	if *asJSON {
+		printJSON(results)
+		return
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', tabwriter.AlignRight)
+	fmt.Fprintf(w, "%d learners, %d cards each, %d new per day, %d days\n\n", p.Learners, p.Cards, p.NewPerDay, *days)
+	fmt.Fprintln(w, "algorithm\treviews/day\tretention\tknowledge\tcost (h)\tknowledge/h\t")
+	for _, r := range results {
+		fmt.Fprintf(w, "%s\t%.1f\t%.3f\t%.1f\t%.1f\t%.1f\t\n",
+			r.Algorithm, r.ReviewsPerDay, r.Retention, r.FinalKnowledge, r.CostHours, r.KnowledgePerHour)
+	}
+	if *daily {
+		for _, r := range results {
+			fmt.Fprintf(w, "\n%s\nday\tnew\treviews\tretention\tknowledge\tminutes\t\n", r.Algorithm)
+			for _, d := range r.Daily {
+				retention := 0.0
+				if d.Reviews > 0 {
+					retention = float64(d.Successes) / float64(d.Reviews)
+				}
+				fmt.Fprintf(w, "%d\t%.1f\t%.1f\t%.3f\t%.1f\t%.1f\t\n", d.Day,
+					float64(d.NewCards)/float64(p.Learners), float64(d.Reviews)/float64(p.Learners),
+					retention, d.Knowledge, d.CostMinutes)
+			}
+		}
+	}
+	w.Flush()
+}
+
+// summarise aggregates the daily statistics of one scheduler.
+func summarise(name string, stats []dayStats, days, learners int, keepDaily bool) simulationResult {
+	result := simulationResult{Algorithm: name}
+	var reviews, successes int
+	var minutes float64
+	for _, d := range stats {
+		reviews += d.Reviews
+		successes += d.Successes
+		minutes += d.CostMinutes
+	}
+	// Review counts are totals over the population; knowledge and cost are already per learner.
+	result.Reviews = float64(reviews) / float64(learners)
+	result.ReviewsPerDay = result.Reviews / float64(days)
+	if reviews > 0 {
+		result.Retention = float64(successes) / float64(reviews)
+	}
+	result.FinalKnowledge = stats[len(stats)-1].Knowledge
+	result.CostHours = minutes / 60
+	if result.CostHours > 0 {
+		result.KnowledgePerHour = result.FinalKnowledge / result.CostHours
+	}
+	if keepDaily {
+		result.Daily = stats
+	}
+	return result
+}
+
+// runReplay replays the review logs of the last sinceDays days through each scheduler.
+func runReplay(names []string, schedulers map[string]srs.Scheduler, sinceDays int, asJSON bool) {
+	cfg, err := config.Load()
+	if err != nil {
+		log.Fatalf("could not load config: %v", err)
+	}
+
+	db, err := database.Connect(cfg.DatabaseURL)
+	if err != nil {
+		log.Fatalf("could not connect to database: %v", err)
+	}
+	defer db.Close()
+
+	reviews, err := loadReplay(context.Background(), db, time.Now().AddDate(0, 0, -sinceDays))
+	if err != nil {
+		log.Fatalf("could not load review log: %v", err)
+	}
+
+	var results []replayResult
+	for _, name := range names {
+		results = append(results, replay(name, schedulers[name], reviews))
+	}
+
+	if asJSON {
+		printJSON(results)
+		return
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', tabwriter.AlignRight)
+	fmt.Fprintf(w, "Replayed %d reviews from the last %d days\n\n", len(reviews), sinceDays)
+	fmt.Fprintln(w, "algorithm\tcards\treviews\tearly\tsuccess\ton time\tsuccess\tlate\tsuccess\tinterval (d)\t")
+	for _, r := range results {
+		fmt.Fprintf(w, "%s\t%d\t%d\t%d\t%s\t%d\t%s\t%d\t%s\t%.1f\t\n", r.Algorithm, r.Cards, r.Reviews,
+			r.Early.Reviews, rate(r.Early.SuccessRate), r.OnTime.Reviews, rate(r.OnTime.SuccessRate),
+			r.Late.Reviews, rate(r.Late.SuccessRate), r.MeanIntervalDays)
+	}
+	w.Flush()
+}
+
+func rate(v *float64) string {
+	if v == nil {
+		return "-"
+	}
+	return fmt.Sprintf("%.3f", *v)
+}
+
+func printJSON(v any) {
+	enc := json.NewEncoder(os.Stdout)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(v); err != nil {
+		log.Fatalf("could not encode results: %v", err)
+	}
+}
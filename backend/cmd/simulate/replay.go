@@ -0,0 +1,114 @@
+package main
+
+import (
+	"context"
+	"time"
+
+	"sentencease/backend/internal/srs"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// replayedReview is one scheduled review from review_log.
+type replayedReview struct {
+	userID     uuid.UUID
+	meaningID  int
+	difficulty float64
+	success    bool
+	userChoice string
+	reviewedAt time.Time
+}
+
+// timingStats counts reviews that happened at a given timing relative to the scheduler's due date.
+type timingStats struct {
+	Reviews     int      `json:"reviews"`
+	SuccessRate *float64 `json:"successRate"`
+	successes   int
+}
+
+func (t *timingStats) add(success bool) {
+	t.Reviews++
+	if success {
+		t.successes++
+	}
+	rate := float64(t.successes) / float64(t.Reviews)
+	t.SuccessRate = &rate
+}
+
+// replayResult summarises how a scheduler's intervals relate to the outcomes of real reviews.
+type replayResult struct {
+	Algorithm string `json:"algorithm"`
+	Cards     int    `json:"cards"`
+	Reviews   int    `json:"reviews"` // Reviews after the first one of a card
+	// Reviews grouped by when they happened relative to the scheduler's due date: before 90% of
+	// the interval, within ±10%, or after 110%. A well tuned scheduler has an on-time success rate
+	// close to its target retention.
+	Early  timingStats `json:"early"`
+	OnTime timingStats `json:"onTime"`
+	Late   timingStats `json:"late"`
+	// MeanIntervalDays is the average interval the scheduler assigns after a successful review.
+	MeanIntervalDays float64 `json:"meanIntervalDays"`
+}
+
+// loadReplay reads the scheduled (non-cram) reviews since the given time, grouped by card in order.
+func loadReplay(ctx context.Context, db *pgxpool.Pool, since time.Time) ([]replayedReview, error) {
+	rows, err := db.Query(ctx, `
+		SELECT rl.user_id, rl.meaning_id, COALESCE(m.difficulty, 0), rl.success, rl.user_choice, rl.reviewed_at
+		FROM review_log rl
+		JOIN meanings m ON m.id = rl.meaning_id
+		WHERE NOT rl.is_cram AND rl.reviewed_at >= $1
+		ORDER BY rl.user_id, rl.meaning_id, rl.reviewed_at, rl.id`, since)
+	if err != nil {
+		return nil, err
+	}
+	return pgx.CollectRows(rows, func(row pgx.CollectableRow) (replayedReview, error) {
+		var r replayedReview
+		err := row.Scan(&r.userID, &r.meaningID, &r.difficulty, &r.success, &r.userChoice, &r.reviewedAt)
+		return r, err
+	})
+}
+
+// replay feeds the real answer sequence of every card to a scheduler and compares the intervals it
+// would have chosen with when the reviews actually happened and how they turned out. The first
+// review in the window starts a card as new, so cards studied before the window start fresh.
+func replay(algorithm string, scheduler srs.Scheduler, reviews []replayedReview) replayResult {
+	result := replayResult{Algorithm: algorithm}
+	var intervalSum float64
+	var intervalCount int
+
+	var card srs.SchedulerCard
+	var last time.Time
+	var scheduled time.Duration
+	for i, r := range reviews {
+		var elapsed time.Duration
+		if i == 0 || r.userID != reviews[i-1].userID || r.meaningID != reviews[i-1].meaningID {
+			card = srs.SchedulerCard{HalfLife: srs.NewCardHalfLife, Difficulty: r.difficulty}
+			result.Cards++
+		} else {
+			elapsed = r.reviewedAt.Sub(last)
+			result.Reviews++
+			switch ratio := elapsed.Hours() / scheduled.Hours(); {
+			case ratio < 0.9:
+				result.Early.add(r.success)
+			case ratio <= 1.1:
+				result.OnTime.add(r.success)
+			default:
+				result.Late.add(r.success)
+			}
+		}
+
+		scheduled = scheduler.Review(&card, r.userChoice, elapsed)
+		last = r.reviewedAt
+		if r.success {
+			intervalSum += scheduled.Hours() / 24
+			intervalCount++
+		}
+	}
+
+	if intervalCount > 0 {
+		result.MeanIntervalDays = intervalSum / float64(intervalCount)
+	}
+	return result
+}
@@ -0,0 +1,116 @@
+// Command worker runs background jobs: it sends the daily study reminders to users who opted in,
+// and delivers queued webhook events.
+//
+// Email reminders need SMTP_HOST and SMTP_FROM, Web Push reminders need VAPID_PUBLIC_KEY and
+// VAPID_PRIVATE_KEY (create a pair with -generate-vapid-keys). Webhooks are always available.
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"os/signal"
+	"sync"
+	"syscall"
+	"time"
+	_ "time/tzdata" // Embed the IANA time zone database; the runtime image has none
+
+	"sentencease/backend/internal/config"
+	"sentencease/backend/internal/database"
+	"sentencease/backend/internal/models"
+	"sentencease/backend/internal/notify"
+	"sentencease/backend/internal/webhook"
+)
+
+func main() {
+	interval := flag.Duration("interval", 5*time.Minute, "how often to check for due reminders")
+	webhookInterval := flag.Duration("webhook-interval", 10*time.Second, "how often to check for queued webhook deliveries")
+	once := flag.Bool("once", false, "run each job once and exit")
+	generateKeys := flag.Bool("generate-vapid-keys", false, "print a new VAPID key pair and exit")
+	flag.Parse()
+
+	if *generateKeys {
+		publicKey, privateKey, err := notify.GenerateVAPIDKeys()
+		if err != nil {
+			log.Fatalf("could not generate VAPID keys: %v", err)
+		}
+		fmt.Printf("VAPID_PUBLIC_KEY=%s\nVAPID_PRIVATE_KEY=%s\n", publicKey, privateKey)
+		return
+	}
+
+	cfg, err := config.Load()
+	if err != nil {
+		log.Fatalf("could not load config: %v", err)
+	}
+
+	db, err := database.Connect(cfg.DatabaseURL)
+	if err != nil {
+		log.Fatalf("could not connect to database: %v", err)
+	}
+	defer db.Close()
+
+	reminders := &notify.Reminders{
+		DB:        db,
+		Notifiers: map[string]notify.Notifier{models.ChannelWebhook: notify.NewWebhookNotifier()},
+		AppURL:    cfg.AppURL,
+	}
+	if cfg.SMTPHost != "" {
+		reminders.Notifiers[models.ChannelEmail] = &notify.SMTPNotifier{
+			Host:     cfg.SMTPHost,
+			Port:     cfg.SMTPPort,
+			Username: cfg.SMTPUsername,
+			Password: cfg.SMTPPassword,
+			From:     cfg.SMTPFrom,
+		}
+	}
+	if cfg.VAPIDPublicKey != "" {
+		webPush, err := notify.NewWebPushNotifier(cfg.VAPIDPublicKey, cfg.VAPIDPrivateKey, cfg.VAPIDSubject)
+		if err != nil {
+			log.Fatalf("could not configure Web Push: %v", err)
+		}
+		reminders.Notifiers[models.ChannelWebPush] = webPush
+	}
+
+	dispatcher := webhook.NewDispatcher(db)
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	var wg sync.WaitGroup
+	wg.Add(2)
+	go func() {
+		defer wg.Done()
+		every(ctx, *interval, *once, func() {
+			if err := reminders.RunOnce(ctx, time.Now()); err != nil && ctx.Err() == nil {
+				log.Printf("could not send reminders: %v", err)
+			}
+		})
+	}()
+	go func() {
+		defer wg.Done()
+		every(ctx, *webhookInterval, *once, func() {
+			if _, err := dispatcher.RunOnce(ctx); err != nil && ctx.Err() == nil {
+				log.Printf("could not deliver webhooks: %v", err)
+			}
+		})
+	}()
+	wg.Wait()
+}
+
+// every runs job now and then at each interval until ctx is cancelled, or only once.
+func every(ctx context.Context, interval time.Duration, once bool, job func()) {
+	for {
+		job()
+		if once {
+			return
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(interval):
+		}
+	}
+}
@@ -25,4 +25,4 @@ CREATE TABLE IF NOT EXISTS app_settings (
 INSERT INTO app_settings (key, value, description)
 VALUES 
 ('srs_algorithm', 'sspmmc', 'The spaced repetition algorithm used by the system')
-ON CONFLICT (key) DO UPDATE SET value = 'sspmmc'; 
\ No newline at end of file
+ON CONFLICT (key) DO NOTHING; 
\ No newline at end of file
@@ -0,0 +1,17 @@
+-- Users who sign in through an external identity provider have no local password.
+ALTER TABLE users ALTER COLUMN password_hash DROP NOT NULL;
+
+-- Link external (OIDC) identities to local users.
+-- A user may have several identities, but each provider subject maps to exactly one user.
+CREATE TABLE IF NOT EXISTS user_identities (
+    id SERIAL PRIMARY KEY,
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    provider VARCHAR(100) NOT NULL, -- 身份提供方名称, e.g. "google"
+    subject VARCHAR(255) NOT NULL,  -- ID token 中的 sub
+    email VARCHAR(255),
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    last_login_at TIMESTAMPTZ,
+    UNIQUE (provider, subject)
+);
+
+CREATE INDEX IF NOT EXISTS idx_user_identities_user_id ON user_identities(user_id);
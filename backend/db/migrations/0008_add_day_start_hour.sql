@@ -0,0 +1,3 @@
+-- 每日学习的起始小时（用户本地时间），类似 Anki 的凌晨4点换日
+ALTER TABLE users ADD COLUMN IF NOT EXISTS day_start_hour INT NOT NULL DEFAULT 4
+    CHECK (day_start_hour >= 0 AND day_start_hour <= 23);
@@ -0,0 +1,18 @@
+-- 学习计划模板："每天从词书X按单元顺序学习N个新词，直到学完"
+CREATE TABLE IF NOT EXISTS study_plans (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    source VARCHAR(50) NOT NULL,           -- 词书, matches words.source
+    words_per_day INT NOT NULL CHECK (words_per_day > 0),
+    status VARCHAR(20) NOT NULL DEFAULT 'active', -- active, paused, completed
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    completed_at TIMESTAMPTZ
+);
+
+CREATE INDEX IF NOT EXISTS idx_study_plans_user_id ON study_plans(user_id);
+
+-- Daily plans generated from a template remember which template and study day they belong to,
+-- so each day is generated at most once.
+ALTER TABLE daily_plans ADD COLUMN IF NOT EXISTS study_plan_id UUID REFERENCES study_plans(id) ON DELETE SET NULL;
+ALTER TABLE daily_plans ADD COLUMN IF NOT EXISTS plan_date DATE;
+CREATE UNIQUE INDEX IF NOT EXISTS idx_daily_plans_study_plan_date ON daily_plans(study_plan_id, plan_date);
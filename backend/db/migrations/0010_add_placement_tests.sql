@@ -0,0 +1,27 @@
+-- 词汇量测试：通过自适应的"认识/不认识"问题估计已掌握的词汇
+CREATE TABLE IF NOT EXISTS placement_tests (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    source VARCHAR(50) NOT NULL,
+    status VARCHAR(20) NOT NULL DEFAULT 'in_progress', -- in_progress, completed, applied
+    num_bands INT NOT NULL,
+    max_questions INT NOT NULL,
+    current_band INT NOT NULL,
+    pending_word_id INT REFERENCES words(id) ON DELETE SET NULL, -- 当前待回答的问题
+    estimated_vocabulary INT,
+    band_results JSONB,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    completed_at TIMESTAMPTZ,
+    applied_at TIMESTAMPTZ
+);
+
+CREATE INDEX IF NOT EXISTS idx_placement_tests_user_id ON placement_tests(user_id);
+
+CREATE TABLE IF NOT EXISTS placement_test_answers (
+    test_id UUID NOT NULL REFERENCES placement_tests(id) ON DELETE CASCADE,
+    word_id INT NOT NULL REFERENCES words(id) ON DELETE CASCADE,
+    band INT NOT NULL,
+    known BOOLEAN NOT NULL,
+    answered_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    PRIMARY KEY (test_id, word_id)
+);
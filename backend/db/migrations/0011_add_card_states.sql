@@ -0,0 +1,9 @@
+-- 卡片状态：active 正常调度；suspended 暂停（直到恢复）；buried 今天跳过（到 buried_until 为止）；
+-- mastered 已掌握，不再出现
+ALTER TABLE user_progress ADD COLUMN IF NOT EXISTS card_state VARCHAR(20) NOT NULL DEFAULT 'active'
+    CHECK (card_state IN ('active', 'suspended', 'buried', 'mastered'));
+ALTER TABLE user_progress ADD COLUMN IF NOT EXISTS buried_until TIMESTAMPTZ;
+ALTER TABLE user_progress ADD COLUMN IF NOT EXISTS state_changed_at TIMESTAMPTZ;
+
+CREATE INDEX IF NOT EXISTS idx_user_progress_card_state ON user_progress(user_id, card_state)
+    WHERE card_state <> 'active';
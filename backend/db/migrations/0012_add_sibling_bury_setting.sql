@@ -0,0 +1,4 @@
+-- 复习某个词义后，同一单词的其他词义推迟出现的分钟数（0 表示不推迟）
+INSERT INTO app_settings (key, value, description)
+VALUES ('sibling_bury_minutes', '30', 'Minutes to defer other meanings of a word after one of its meanings is reviewed')
+ON CONFLICT (key) DO NOTHING;
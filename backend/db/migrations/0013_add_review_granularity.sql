@@ -0,0 +1,13 @@
+-- 复习粒度：meaning 按词义记录进度（默认）；word 按单词记录，进度保存在该单词的第一个词义上
+ALTER TABLE users ADD COLUMN IF NOT EXISTS review_granularity VARCHAR(10) NOT NULL DEFAULT 'meaning'
+    CHECK (review_granularity IN ('meaning', 'word'));
+
+-- 返回记录某词义学习进度的词义 ID：按词义模式为其本身，按单词模式为该单词的第一个词义
+CREATE OR REPLACE FUNCTION tracked_meaning_id(p_user_id UUID, p_meaning_id INT) RETURNS INT AS $$
+    SELECT CASE WHEN u.review_granularity = 'word'
+                THEN (SELECT MIN(pm.id) FROM meanings pm WHERE pm.word_id = m.word_id)
+                ELSE m.id
+           END
+    FROM meanings m, users u
+    WHERE m.id = p_meaning_id AND u.id = p_user_id
+$$ LANGUAGE SQL STABLE;
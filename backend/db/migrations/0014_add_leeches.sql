@@ -0,0 +1,22 @@
+-- 记忆失败（遗忘）计数与“顽固词”（leech）标记
+ALTER TABLE user_progress ADD COLUMN IF NOT EXISTS lapse_count INT NOT NULL DEFAULT 0;
+ALTER TABLE user_progress ADD COLUMN IF NOT EXISTS is_leech BOOLEAN NOT NULL DEFAULT FALSE;
+ALTER TABLE user_progress ADD COLUMN IF NOT EXISTS leeched_at TIMESTAMPTZ;
+ALTER TABLE user_progress ADD COLUMN IF NOT EXISTS remediation_streak INT NOT NULL DEFAULT 0;
+ALTER TABLE user_progress ADD COLUMN IF NOT EXISTS last_remediated_at TIMESTAMPTZ;
+
+CREATE INDEX IF NOT EXISTS idx_user_progress_leeches ON user_progress(user_id) WHERE is_leech;
+
+-- 词义的备用例句，用于顽固词的强化练习
+CREATE TABLE IF NOT EXISTS meaning_examples (
+    id SERIAL PRIMARY KEY,
+    meaning_id INT NOT NULL REFERENCES meanings(id) ON DELETE CASCADE,
+    sentence TEXT NOT NULL,
+    translation TEXT,
+    UNIQUE (meaning_id, sentence)
+);
+
+INSERT INTO app_settings (key, value, description) VALUES
+('leech_threshold', '8', 'Number of lapses after which a card becomes a leech'),
+('leech_action', 'tag', 'What happens to a new leech: tag (keep scheduling) or suspend')
+ON CONFLICT (key) DO NOTHING;
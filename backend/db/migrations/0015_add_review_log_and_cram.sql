@@ -0,0 +1,43 @@
+-- 复习日志：每次复习一条记录，包括不影响调度的突击复习（cram）
+CREATE TABLE IF NOT EXISTS review_log (
+    id BIGSERIAL PRIMARY KEY,
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    meaning_id INT NOT NULL REFERENCES meanings(id) ON DELETE CASCADE,
+    user_choice VARCHAR(10) NOT NULL,        -- 认识 / 模糊 / 不认识
+    success BOOLEAN NOT NULL,                -- user_choice = 认识
+    is_cram BOOLEAN NOT NULL DEFAULT FALSE,
+    cram_session_id UUID,
+    elapsed_hours FLOAT,                     -- 距上次复习的学习小时数（不含假期），新词为 NULL
+    memory_halflife FLOAT,                   -- 复习前的记忆半衰期（小时），新词为 NULL
+    reviewed_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE INDEX IF NOT EXISTS idx_review_log_user_time ON review_log(user_id, reviewed_at);
+
+-- 突击复习会话：按筛选条件生成的一组单词，复习结果不影响 user_progress
+CREATE TABLE IF NOT EXISTS cram_sessions (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    source VARCHAR(50),
+    unit VARCHAR(255),
+    tag VARCHAR(20),
+    failed_within_days INT,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    finished_at TIMESTAMPTZ
+);
+
+CREATE INDEX IF NOT EXISTS idx_cram_sessions_user ON cram_sessions(user_id, created_at DESC);
+
+CREATE TABLE IF NOT EXISTS cram_session_words (
+    session_id UUID NOT NULL REFERENCES cram_sessions(id) ON DELETE CASCADE,
+    meaning_id INT NOT NULL REFERENCES meanings(id) ON DELETE CASCADE,
+    position INT NOT NULL,
+    attempts INT NOT NULL DEFAULT 0,
+    done BOOLEAN NOT NULL DEFAULT FALSE,     -- 答“认识”后完成，否则稍后再次出现
+    last_answered_at TIMESTAMPTZ,
+    PRIMARY KEY (session_id, meaning_id)
+);
+
+ALTER TABLE review_log DROP CONSTRAINT IF EXISTS review_log_cram_session_fk;
+ALTER TABLE review_log ADD CONSTRAINT review_log_cram_session_fk
+    FOREIGN KEY (cram_session_id) REFERENCES cram_sessions(id) ON DELETE SET NULL;
@@ -0,0 +1,3 @@
+-- 复习间隔随机扰动与负载均衡（按用户设置）
+ALTER TABLE users ADD COLUMN IF NOT EXISTS interval_fuzz BOOLEAN NOT NULL DEFAULT TRUE;
+ALTER TABLE users ADD COLUMN IF NOT EXISTS load_balancing BOOLEAN NOT NULL DEFAULT FALSE;
@@ -0,0 +1,13 @@
+-- 假期模式：假期内暂停复习时钟，开始时将之后到期的复习整体顺延
+CREATE TABLE IF NOT EXISTS vacations (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    start_date DATE NOT NULL,            -- 用户时区内的学习日
+    end_date DATE NOT NULL,
+    starts_at TIMESTAMPTZ NOT NULL,      -- start_date 学习日的开始
+    ends_at TIMESTAMPTZ NOT NULL,        -- end_date 学习日的结束
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    CHECK (end_date >= start_date)
+);
+
+CREATE INDEX IF NOT EXISTS idx_vacations_user ON vacations(user_id, starts_at);
@@ -0,0 +1,40 @@
+-- 学习统计：记录答题耗时和复习后的半衰期，并按学习日预先汇总
+ALTER TABLE review_log ADD COLUMN IF NOT EXISTS response_ms INT;      -- 前端上报的答题耗时，可为空
+ALTER TABLE review_log ADD COLUMN IF NOT EXISTS new_halflife FLOAT;   -- 复习后的记忆半衰期（SSP-MMC），可为空
+
+-- 每个用户每个学习日（用户时区，按 day_start_hour 切分）一行
+CREATE TABLE IF NOT EXISTS review_daily_stats (
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    study_date DATE NOT NULL,
+    reviews INT NOT NULL DEFAULT 0,               -- 全部复习，含突击复习
+    successes INT NOT NULL DEFAULT 0,
+    cram_reviews INT NOT NULL DEFAULT 0,
+    new_cards INT NOT NULL DEFAULT 0,             -- 首次学习的词
+    young_reviews INT NOT NULL DEFAULT 0,         -- 距上次复习不足 21 天
+    young_successes INT NOT NULL DEFAULT 0,
+    mature_reviews INT NOT NULL DEFAULT 0,        -- 距上次复习 21 天及以上
+    mature_successes INT NOT NULL DEFAULT 0,
+    timed_reviews INT NOT NULL DEFAULT 0,         -- 上报了答题耗时的复习
+    time_spent_ms BIGINT NOT NULL DEFAULT 0,
+    halflife_growth_sum FLOAT NOT NULL DEFAULT 0, -- 成功复习的 新半衰期/旧半衰期 之和
+    halflife_growth_count INT NOT NULL DEFAULT 0,
+    PRIMARY KEY (user_id, study_date)
+);
+
+-- 由已有的复习日志回填
+INSERT INTO review_daily_stats (user_id, study_date, reviews, successes, cram_reviews, new_cards,
+                                young_reviews, young_successes, mature_reviews, mature_successes)
+SELECT rl.user_id,
+       (rl.reviewed_at AT TIME ZONE u.timezone - make_interval(hours => u.day_start_hour))::date,
+       COUNT(*),
+       COUNT(*) FILTER (WHERE rl.success),
+       COUNT(*) FILTER (WHERE rl.is_cram),
+       COUNT(*) FILTER (WHERE NOT rl.is_cram AND rl.elapsed_hours IS NULL),
+       COUNT(*) FILTER (WHERE NOT rl.is_cram AND rl.elapsed_hours < 504),
+       COUNT(*) FILTER (WHERE NOT rl.is_cram AND rl.elapsed_hours < 504 AND rl.success),
+       COUNT(*) FILTER (WHERE NOT rl.is_cram AND rl.elapsed_hours >= 504),
+       COUNT(*) FILTER (WHERE NOT rl.is_cram AND rl.elapsed_hours >= 504 AND rl.success)
+FROM review_log rl
+JOIN users u ON u.id = rl.user_id
+GROUP BY 1, 2
+ON CONFLICT (user_id, study_date) DO NOTHING;
@@ -0,0 +1,6 @@
+-- 管理员标记：目前只能直接在数据库中授予，例如
+--   UPDATE users SET is_admin = TRUE WHERE email = 'admin@example.com';
+ALTER TABLE users ADD COLUMN IF NOT EXISTS is_admin BOOLEAN NOT NULL DEFAULT FALSE;
+
+-- 记录每次复习时使用的调度算法，用于校准报告；此前的记录为 NULL
+ALTER TABLE review_log ADD COLUMN IF NOT EXISTS algorithm VARCHAR(20);
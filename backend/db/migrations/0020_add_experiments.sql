@@ -0,0 +1,28 @@
+-- 调度算法 A/B 实验：用户按 user_id 哈希确定性地分到某个实验组（arm）
+CREATE TABLE IF NOT EXISTS experiments (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    name VARCHAR(100) NOT NULL UNIQUE,
+    description TEXT,
+    status VARCHAR(20) NOT NULL DEFAULT 'running' CHECK (status IN ('running', 'stopped')),
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    stopped_at TIMESTAMPTZ
+);
+
+-- 同一时间最多只有一个进行中的实验
+CREATE UNIQUE INDEX IF NOT EXISTS idx_experiments_one_running ON experiments ((status)) WHERE status = 'running';
+
+CREATE TABLE IF NOT EXISTS experiment_arms (
+    id SERIAL PRIMARY KEY,
+    experiment_id UUID NOT NULL REFERENCES experiments(id) ON DELETE CASCADE,
+    name VARCHAR(50) NOT NULL,
+    weight INT NOT NULL DEFAULT 1 CHECK (weight > 0),  -- 分配比例
+    algorithm VARCHAR(20) NOT NULL,                   -- legacy / sspmmc
+    params JSONB NOT NULL DEFAULT '{}',               -- 调度参数覆盖，见 srs.SchedulerParams
+    UNIQUE (experiment_id, name)
+);
+
+-- 复习日志记录复习时所在的实验组
+ALTER TABLE review_log ADD COLUMN IF NOT EXISTS experiment_id UUID REFERENCES experiments(id) ON DELETE SET NULL;
+ALTER TABLE review_log ADD COLUMN IF NOT EXISTS arm_id INT REFERENCES experiment_arms(id) ON DELETE SET NULL;
+
+CREATE INDEX IF NOT EXISTS idx_review_log_experiment ON review_log(experiment_id, arm_id) WHERE experiment_id IS NOT NULL;
@@ -0,0 +1,27 @@
+-- 学习小组：通过邀请码加入，创建者（老师）可查看成员的整体学习进度
+CREATE TABLE IF NOT EXISTS study_groups (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    name VARCHAR(100) NOT NULL,
+    description TEXT,
+    owner_id UUID NOT NULL REFERENCES users(id) ON DELETE RESTRICT,  -- 删除账号前先移交小组（见 DeleteAccount）
+    invite_code VARCHAR(12) NOT NULL UNIQUE,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE TABLE IF NOT EXISTS study_group_members (
+    group_id UUID NOT NULL REFERENCES study_groups(id) ON DELETE CASCADE,
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    role VARCHAR(10) NOT NULL DEFAULT 'member' CHECK (role IN ('owner', 'member')),
+    joined_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    PRIMARY KEY (group_id, user_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_study_group_members_user ON study_group_members(user_id);
+
+-- 隐私设置：是否出现在小组排行榜上
+ALTER TABLE users ADD COLUMN IF NOT EXISTS show_on_leaderboards BOOLEAN NOT NULL DEFAULT TRUE;
+
+-- 已建表的库同样改为 RESTRICT：删除小组创建者的账号不再连带删除整个小组
+ALTER TABLE study_groups DROP CONSTRAINT IF EXISTS study_groups_owner_id_fkey;
+ALTER TABLE study_groups ADD CONSTRAINT study_groups_owner_id_fkey
+    FOREIGN KEY (owner_id) REFERENCES users(id) ON DELETE RESTRICT;
@@ -0,0 +1,29 @@
+-- 班级作业：老师把一组词义（例如考研第5单元）布置给小组内的所有学生，并设定截止日期
+-- 小组即班级：创建者和协助老师（teacher）可以布置作业，普通成员（member）是学生
+ALTER TABLE study_group_members DROP CONSTRAINT IF EXISTS study_group_members_role_check;
+ALTER TABLE study_group_members ADD CONSTRAINT study_group_members_role_check
+    CHECK (role IN ('owner', 'teacher', 'member'));
+
+CREATE TABLE IF NOT EXISTS assignments (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    group_id UUID NOT NULL REFERENCES study_groups(id) ON DELETE CASCADE,
+    created_by UUID REFERENCES users(id) ON DELETE SET NULL,
+    title VARCHAR(200) NOT NULL,
+    start_date DATE NOT NULL,  -- 按每个学生自己的学习日计算
+    due_date DATE NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    CHECK (due_date >= start_date)
+);
+
+CREATE INDEX IF NOT EXISTS idx_assignments_group ON assignments(group_id);
+
+CREATE TABLE IF NOT EXISTS assignment_words (
+    assignment_id UUID NOT NULL REFERENCES assignments(id) ON DELETE CASCADE,
+    meaning_id INT NOT NULL REFERENCES meanings(id) ON DELETE CASCADE,
+    position INT NOT NULL,
+    PRIMARY KEY (assignment_id, meaning_id)
+);
+
+-- 由作业生成的每日计划，每个学生每天最多一个
+ALTER TABLE daily_plans ADD COLUMN IF NOT EXISTS assignment_id UUID REFERENCES assignments(id) ON DELETE SET NULL;
+CREATE UNIQUE INDEX IF NOT EXISTS idx_daily_plans_assignment_date ON daily_plans(assignment_id, user_id, plan_date);
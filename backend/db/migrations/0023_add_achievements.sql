@@ -0,0 +1,20 @@
+-- 成就与经验值：规则在代码中声明（srs.AchievementRules），新增成就无需修改表结构
+ALTER TABLE users ADD COLUMN IF NOT EXISTS xp BIGINT NOT NULL DEFAULT 0;
+
+CREATE TABLE IF NOT EXISTS user_achievements (
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    achievement_id VARCHAR(64) NOT NULL,  -- srs.AchievementRule.ID
+    xp INT NOT NULL,
+    earned_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    PRIMARY KEY (user_id, achievement_id)
+);
+
+-- 一次性事件的经验值记录（例如某天完成计划、读完某本词书），保证同一事件只奖励一次
+CREATE TABLE IF NOT EXISTS xp_awards (
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    event VARCHAR(32) NOT NULL,
+    event_key VARCHAR(100) NOT NULL,
+    xp INT NOT NULL,
+    awarded_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    PRIMARY KEY (user_id, event, event_key)
+);
@@ -0,0 +1,37 @@
+-- 学习提醒：用户自愿开启，在本地时间的指定时刻通过邮件、Webhook 或 Web Push 提醒到期的复习
+CREATE TABLE IF NOT EXISTS notification_settings (
+    user_id UUID PRIMARY KEY REFERENCES users(id) ON DELETE CASCADE,
+    reminders_enabled BOOLEAN NOT NULL DEFAULT FALSE,
+    reminder_time TIME NOT NULL DEFAULT '19:00',  -- 用户本地时间
+    quiet_hours_start TIME,                        -- 免打扰时段，可跨午夜，例如 22:00-08:00
+    quiet_hours_end TIME,
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    CHECK ((quiet_hours_start IS NULL) = (quiet_hours_end IS NULL))
+);
+
+CREATE TABLE IF NOT EXISTS notification_channels (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    type VARCHAR(10) NOT NULL CHECK (type IN ('email', 'webhook', 'webpush')),
+    target TEXT NOT NULL,        -- 邮箱地址、Webhook URL 或 Push 订阅的 endpoint
+    secret TEXT,                 -- Webhook 签名密钥
+    p256dh TEXT,                 -- Push 订阅的公钥
+    auth TEXT,                   -- Push 订阅的认证密钥
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    UNIQUE (user_id, type, target)
+);
+
+CREATE TABLE IF NOT EXISTS notification_log (
+    id BIGSERIAL PRIMARY KEY,
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    channel_id UUID REFERENCES notification_channels(id) ON DELETE SET NULL,
+    channel_type VARCHAR(10) NOT NULL,
+    study_date DATE NOT NULL,
+    due_reviews INT NOT NULL,
+    new_words INT NOT NULL,
+    status VARCHAR(10) NOT NULL CHECK (status IN ('sent', 'failed')),
+    error TEXT,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE INDEX IF NOT EXISTS idx_notification_log_user ON notification_log(user_id, study_date);
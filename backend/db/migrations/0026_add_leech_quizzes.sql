@@ -0,0 +1,8 @@
+-- 顽固词强化练习的当前题目：正确选项只保存在服务器端，每个用户同时只有一道未作答的题
+CREATE TABLE IF NOT EXISTS leech_quizzes (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    user_id UUID NOT NULL UNIQUE REFERENCES users(id) ON DELETE CASCADE,
+    meaning_id INT NOT NULL REFERENCES meanings(id) ON DELETE CASCADE,
+    correct_choice SMALLINT NOT NULL,  -- 正确选项在 choices 中的下标
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
@@ -0,0 +1,20 @@
+-- 按词书设置复习粒度，覆盖用户的默认粒度（users.review_granularity）
+CREATE TABLE IF NOT EXISTS source_granularity (
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    source VARCHAR(50) NOT NULL,
+    granularity VARCHAR(10) NOT NULL CHECK (granularity IN ('meaning', 'word')),
+    PRIMARY KEY (user_id, source)
+);
+
+-- 词书有单独设置时按该设置，否则按用户的默认粒度
+CREATE OR REPLACE FUNCTION tracked_meaning_id(p_user_id UUID, p_meaning_id INT) RETURNS INT AS $$
+    SELECT CASE WHEN COALESCE(sg.granularity, u.review_granularity) = 'word'
+                THEN (SELECT MIN(pm.id) FROM meanings pm WHERE pm.word_id = m.word_id)
+                ELSE m.id
+           END
+    FROM meanings m
+    JOIN words w ON w.id = m.word_id
+    JOIN users u ON u.id = p_user_id
+    LEFT JOIN source_granularity sg ON sg.user_id = u.id AND sg.source = w.source
+    WHERE m.id = p_meaning_id
+$$ LANGUAGE SQL STABLE;
@@ -0,0 +1,672 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"time"
+
+	"sentencease/backend/internal/auth"
+	"sentencease/backend/internal/models"
+	"sentencease/backend/internal/srs"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+)
+
+// GetProfile returns the authenticated user's profile.
+func (a *API) GetProfile(c *gin.Context) {
+	userID, ok := getUserID(c)
+	if !ok {
+		return
+	}
+
+	profile, err := a.loadProfile(c.Request.Context(), userID)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "User not found"})
+			return
+		}
+		log.Printf("GetProfile: Error loading profile for user %s: %v", userID, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get user profile"})
+		return
+	}
+
+	c.JSON(http.StatusOK, profile)
+}
+
+// UpdateProfile changes the display name, native language, timezone, day-start hour, review
+// granularity, scheduling options and/or leaderboard visibility. Sending an empty string clears the display name or native language.
+// Switching to word granularity keeps the progress of each word's first meaning as the word's progress.
+func (a *API) UpdateProfile(c *gin.Context) {
+	userID, ok := getUserID(c)
+	if !ok {
+		return
+	}
+
+	var req models.UpdateProfileRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body: " + err.Error()})
+		return
+	}
+
+	if req.Timezone != nil {
+		if err := validateTimezone(*req.Timezone); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+	}
+
+	query := `
+		UPDATE users SET
+			display_name = CASE WHEN $2::text IS NULL THEN display_name ELSE NULLIF($2, '') END,
+			native_language = CASE WHEN $3::text IS NULL THEN native_language ELSE NULLIF($3, '') END,
+			timezone = COALESCE($4, timezone),
+			day_start_hour = COALESCE($5, day_start_hour),
+			review_granularity = COALESCE($6, review_granularity),
+			interval_fuzz = COALESCE($7, interval_fuzz),
+			load_balancing = COALESCE($8, load_balancing),
+			show_on_leaderboards = COALESCE($9, show_on_leaderboards),
+			updated_at = now()
+		WHERE id = $1
+	`
+	tag, err := a.DB.Exec(c.Request.Context(), query,
+		userID, req.DisplayName, req.NativeLanguage, req.Timezone, req.DayStartHour, req.ReviewGranularity,
+		req.IntervalFuzz, req.LoadBalancing, req.ShowOnLeaderboards)
+	if err != nil {
+		log.Printf("UpdateProfile: Error updating profile for user %s: %v", userID, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update user profile"})
+		return
+	}
+	if tag.RowsAffected() == 0 {
+		c.JSON(http.StatusNotFound, gin.H{"error": "User not found"})
+		return
+	}
+
+	profile, err := a.loadProfile(c.Request.Context(), userID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get user profile"})
+		return
+	}
+
+	c.JSON(http.StatusOK, profile)
+}
+
+// ChangePassword sets a new password after verifying the current one.
+// Accounts created through an external identity provider may set a first password without one.
+func (a *API) ChangePassword(c *gin.Context) {
+	userID, ok := getUserID(c)
+	if !ok {
+		return
+	}
+
+	var req models.ChangePasswordRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body: " + err.Error()})
+		return
+	}
+
+	var currentHash *string
+	err := a.DB.QueryRow(c.Request.Context(), `SELECT password_hash FROM users WHERE id = $1`, userID).Scan(&currentHash)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get user information"})
+		return
+	}
+
+	if currentHash != nil && !auth.CheckPasswordHash(req.CurrentPassword, *currentHash) {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Current password is incorrect"})
+		return
+	}
+
+	hashedPassword, err := auth.HashPassword(req.NewPassword)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to hash password"})
+		return
+	}
+
+	_, err = a.DB.Exec(c.Request.Context(),
+		`UPDATE users SET password_hash = $2, updated_at = now() WHERE id = $1`, userID, hashedPassword)
+	if err != nil {
+		log.Printf("ChangePassword: Error updating password for user %s: %v", userID, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to change password"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Password changed successfully"})
+}
+
+// DeleteAccount permanently removes the user. All learning data (user_progress, daily_plans,
+// linked identities, ...) is removed by the ON DELETE CASCADE foreign keys.
+// Study groups the user owns are handed over to another member (see transferOwnedGroups) so the
+// class and its assignments survive; groups with no other member are deleted.
+// Clients should offer GET /user/export before calling this.
+func (a *API) DeleteAccount(c *gin.Context) {
+	userID, ok := getUserID(c)
+	if !ok {
+		return
+	}
+
+	var req models.DeleteAccountRequest
+	// The body is optional for accounts without a password.
+	if c.Request.ContentLength != 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body: " + err.Error()})
+			return
+		}
+	}
+
+	ctx := c.Request.Context()
+	tx, err := a.DB.Begin(ctx)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to start transaction"})
+		return
+	}
+	defer tx.Rollback(ctx)
+
+	var passwordHash *string
+	err = tx.QueryRow(ctx, `SELECT password_hash FROM users WHERE id = $1 FOR UPDATE`, userID).Scan(&passwordHash)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "User not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get user information"})
+		return
+	}
+
+	if passwordHash != nil && !auth.CheckPasswordHash(req.Password, *passwordHash) {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Password is incorrect"})
+		return
+	}
+
+	if err := transferOwnedGroups(ctx, tx, userID); err != nil {
+		log.Printf("DeleteAccount: Error transferring groups of user %s: %v", userID, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete account"})
+		return
+	}
+
+	if _, err := tx.Exec(ctx, `DELETE FROM users WHERE id = $1`, userID); err != nil {
+		log.Printf("DeleteAccount: Error deleting user %s: %v", userID, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete account"})
+		return
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to commit transaction"})
+		return
+	}
+
+	log.Printf("DeleteAccount: User %s deleted their account", userID)
+	c.JSON(http.StatusOK, gin.H{"message": "Account deleted successfully"})
+}
+
+// ExportUserData returns everything stored about the user as a downloadable JSON document.
+func (a *API) ExportUserData(c *gin.Context) {
+	userID, ok := getUserID(c)
+	if !ok {
+		return
+	}
+
+	export, err := a.buildUserExport(c.Request.Context(), userID)
+	if err != nil {
+		log.Printf("ExportUserData: Error exporting data for user %s: %v", userID, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to export user data"})
+		return
+	}
+
+	filename := fmt.Sprintf("sentencease-export-%s.json", time.Now().UTC().Format("20060102"))
+	c.Header("Content-Disposition", `attachment; filename="`+filename+`"`)
+	c.JSON(http.StatusOK, export)
+}
+
+// loadProfile reads the profile columns of a user.
+func (a *API) loadProfile(ctx context.Context, userID uuid.UUID) (*models.UserProfile, error) {
+	var profile models.UserProfile
+	query := `
+		SELECT id, email, display_name, native_language, timezone, day_start_hour, review_granularity,
+		       interval_fuzz, load_balancing, show_on_leaderboards, password_hash IS NOT NULL, COALESCE(created_at, now())
+		FROM users WHERE id = $1
+	`
+	err := a.DB.QueryRow(ctx, query, userID).Scan(
+		&profile.ID,
+		&profile.Email,
+		&profile.DisplayName,
+		&profile.NativeLanguage,
+		&profile.Timezone,
+		&profile.DayStartHour,
+		&profile.ReviewGranularity,
+		&profile.IntervalFuzz,
+		&profile.LoadBalancing,
+		&profile.ShowOnLeaderboards,
+		&profile.HasPassword,
+		&profile.CreatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := a.DB.Query(ctx, `SELECT source, granularity FROM source_granularity WHERE user_id = $1`, userID)
+	if err != nil {
+		return nil, err
+	}
+	profile.SourceGranularity = make(map[string]string)
+	var source, granularity string
+	_, err = pgx.ForEachRow(rows, []any{&source, &granularity}, func() error {
+		profile.SourceGranularity[source] = granularity
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &profile, nil
+}
+
+// SetSourceGranularity sets the review granularity of one vocabulary source, overriding the
+// profile's reviewGranularity for its words. As with the profile setting, switching a source to
+// word granularity keeps the progress of each word's first meaning as the word's progress.
+func (a *API) SetSourceGranularity(c *gin.Context) {
+	userID, ok := getUserID(c)
+	if !ok {
+		return
+	}
+	source := c.Param("source")
+
+	var req models.SetSourceGranularityRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body: " + err.Error()})
+		return
+	}
+
+	tag, err := a.DB.Exec(c.Request.Context(), `
+		INSERT INTO source_granularity (user_id, source, granularity)
+		SELECT $1, $2, $3 WHERE EXISTS (SELECT 1 FROM words WHERE source = $2)
+		ON CONFLICT (user_id, source) DO UPDATE SET granularity = EXCLUDED.granularity`,
+		userID, source, req.Granularity)
+	if err != nil {
+		log.Printf("SetSourceGranularity: Error setting granularity of %q for user %s: %v", source, userID, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to set review granularity"})
+		return
+	}
+	if tag.RowsAffected() == 0 {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Vocabulary source not found"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"source": source, "granularity": req.Granularity})
+}
+
+// ClearSourceGranularity removes the review granularity of a vocabulary source, so that the
+// profile's reviewGranularity applies to it again.
+func (a *API) ClearSourceGranularity(c *gin.Context) {
+	userID, ok := getUserID(c)
+	if !ok {
+		return
+	}
+	source := c.Param("source")
+
+	_, err := a.DB.Exec(c.Request.Context(),
+		`DELETE FROM source_granularity WHERE user_id = $1 AND source = $2`, userID, source)
+	if err != nil {
+		log.Printf("ClearSourceGranularity: Error clearing granularity of %q for user %s: %v", source, userID, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to clear review granularity"})
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// validateTimezone checks that tz is a loadable IANA time zone name.
+func validateTimezone(tz string) error {
+	if tz == "" || tz == "Local" {
+		return errors.New("timezone must be an IANA time zone name such as Asia/Shanghai")
+	}
+	if _, err := time.LoadLocation(tz); err != nil {
+		return fmt.Errorf("unknown timezone %q", tz)
+	}
+	return nil
+}
+
+// exportedIdentity is a linked external login in the data export.
+type exportedIdentity struct {
+	Provider  string    `json:"provider"`
+	Subject   string    `json:"subject"`
+	Email     *string   `json:"email"`
+	CreatedAt time.Time `json:"createdAt"`
+}
+
+// exportedProgress is one user_progress row in the data export.
+type exportedProgress struct {
+	MeaningID         int        `json:"meaningId"`
+	Lemma             string     `json:"lemma"`
+	Definition        string     `json:"definition"`
+	SRSStage          int        `json:"srsStage"`
+	LastReviewedAt    *time.Time `json:"lastReviewedAt"`
+	NextReviewAt      time.Time  `json:"nextReviewAt"`
+	ReviewCount       int        `json:"reviewCount"`
+	MemoryHalfLife    float64    `json:"memoryHalfLife"`
+	OptimalInterval   float64    `json:"optimalInterval"`
+	LastRecallSuccess bool       `json:"lastRecallSuccess"`
+	CardState         string     `json:"cardState"`
+	BuriedUntil       *time.Time `json:"buriedUntil,omitempty"`
+	LapseCount        int        `json:"lapseCount"`
+	IsLeech           bool       `json:"isLeech"`
+}
+
+// exportedDailyPlan is one daily plan with its meanings in the data export.
+type exportedDailyPlan struct {
+	ID         uuid.UUID `json:"id"`
+	CreatedAt  time.Time `json:"createdAt"`
+	MeaningIDs []int     `json:"meaningIds"`
+}
+
+// exportedReview is one review_log row in the data export.
+type exportedReview struct {
+	MeaningID     int        `json:"meaningId"`
+	UserChoice    string     `json:"userChoice"`
+	IsCram        bool       `json:"isCram"`
+	CramSessionID *uuid.UUID `json:"cramSessionId,omitempty"`
+	ResponseMs    *int       `json:"responseMs,omitempty"`
+	ReviewedAt    time.Time  `json:"reviewedAt"`
+}
+
+// exportedPlacementTest is one vocabulary placement test with its answers in the data export.
+type exportedPlacementTest struct {
+	ID                  uuid.UUID                 `json:"id"`
+	Source              string                    `json:"source"`
+	Status              string                    `json:"status"`
+	EstimatedVocabulary *int                      `json:"estimatedVocabulary"`
+	BandResults         json.RawMessage           `json:"bandResults"`
+	CreatedAt           time.Time                 `json:"createdAt"`
+	CompletedAt         *time.Time                `json:"completedAt"`
+	AppliedAt           *time.Time                `json:"appliedAt"`
+	Answers             []exportedPlacementAnswer `json:"answers"`
+}
+
+// exportedPlacementAnswer is one answer of a placement test.
+type exportedPlacementAnswer struct {
+	WordID     int       `json:"wordId"`
+	Band       int       `json:"band"`
+	Known      bool      `json:"known"`
+	AnsweredAt time.Time `json:"answeredAt"`
+}
+
+// exportedCramSession is one cram session with its words in the data export.
+type exportedCramSession struct {
+	ID               uuid.UUID          `json:"id"`
+	Source           *string            `json:"source"`
+	Unit             *string            `json:"unit"`
+	Tag              *string            `json:"tag"`
+	FailedWithinDays *int               `json:"failedWithinDays"`
+	CreatedAt        time.Time          `json:"createdAt"`
+	FinishedAt       *time.Time         `json:"finishedAt"`
+	Words            []exportedCramWord `json:"words"`
+}
+
+// exportedCramWord is one word of a cram session.
+type exportedCramWord struct {
+	MeaningID      int        `json:"meaningId"`
+	Position       int        `json:"position"`
+	Attempts       int        `json:"attempts"`
+	Done           bool       `json:"done"`
+	LastAnsweredAt *time.Time `json:"lastAnsweredAt"`
+}
+
+// exportedXPAward is one one-off XP award in the data export.
+type exportedXPAward struct {
+	Event     string    `json:"event"`
+	EventKey  string    `json:"eventKey"`
+	XP        int       `json:"xp"`
+	AwardedAt time.Time `json:"awardedAt"`
+}
+
+// exportedLeechQuiz is the unanswered leech remediation quiz in the data export. The correct
+// choice is left out so the export cannot be used to answer it.
+type exportedLeechQuiz struct {
+	ID        uuid.UUID `json:"id"`
+	MeaningID int       `json:"meaningId"`
+	CreatedAt time.Time `json:"createdAt"`
+}
+
+// userExport is the document returned by GET /user/export.
+type userExport struct {
+	ExportedAt           time.Time                     `json:"exportedAt"`
+	Profile              *models.UserProfile           `json:"profile"`
+	Identities           []exportedIdentity            `json:"identities"`
+	Progress             []exportedProgress            `json:"progress"`
+	DailyPlans           []exportedDailyPlan           `json:"dailyPlans"`
+	StudyPlans           []models.StudyPlan            `json:"studyPlans"`
+	Reviews              []exportedReview              `json:"reviews"`
+	Vacations            []models.Vacation             `json:"vacations"`
+	Groups               []models.StudyGroup           `json:"groups"`
+	Assignments          []models.Assignment           `json:"assignments"`
+	PlacementTests       []exportedPlacementTest       `json:"placementTests"`
+	CramSessions         []exportedCramSession         `json:"cramSessions"`
+	Achievements         *models.AchievementList       `json:"achievements"`
+	XPAwards             []exportedXPAward             `json:"xpAwards"`
+	LeechQuizzes         []exportedLeechQuiz           `json:"leechQuizzes"`
+	NotificationSettings *models.NotificationSettings  `json:"notificationSettings"`
+	NotificationChannels []models.NotificationChannel  `json:"notificationChannels"`
+	NotificationLog      []models.NotificationLogEntry `json:"notificationLog"`
+	Webhooks             []models.Webhook              `json:"webhooks"`
+}
+
+// buildUserExport collects all data belonging to a user. Data derived from what is exported
+// (review_daily_stats, the XP total) and webhook delivery attempts, which only mirror the events
+// already listed here, are left out.
+func (a *API) buildUserExport(ctx context.Context, userID uuid.UUID) (*userExport, error) {
+	profile, err := a.loadProfile(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	export := &userExport{
+		ExportedAt: time.Now(),
+		Profile:    profile,
+		Identities: []exportedIdentity{},
+		Progress:   []exportedProgress{},
+		DailyPlans: []exportedDailyPlan{},
+		StudyPlans: []models.StudyPlan{},
+		Reviews:    []exportedReview{},
+		Vacations:  []models.Vacation{},
+		Groups:     []models.StudyGroup{},
+	}
+
+	rows, err := a.DB.Query(ctx,
+		`SELECT provider, subject, email, created_at FROM user_identities WHERE user_id = $1 ORDER BY id`, userID)
+	if err != nil {
+		return nil, err
+	}
+	for rows.Next() {
+		var identity exportedIdentity
+		if err := rows.Scan(&identity.Provider, &identity.Subject, &identity.Email, &identity.CreatedAt); err != nil {
+			rows.Close()
+			return nil, err
+		}
+		export.Identities = append(export.Identities, identity)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	progressQuery := `
+		SELECT up.meaning_id, w.lemma, m.definition, up.srs_stage, up.last_reviewed_at, up.next_review_at,
+		       COALESCE(up.review_count, 0), COALESCE(up.memory_halflife, 4.0),
+		       COALESCE(up.optimal_interval, 4.0), COALESCE(up.last_recall_success, false),
+		       up.card_state, up.buried_until, up.lapse_count, up.is_leech
+		FROM user_progress up
+		JOIN meanings m ON m.id = up.meaning_id
+		JOIN words w ON w.id = m.word_id
+		WHERE up.user_id = $1
+		ORDER BY up.meaning_id
+	`
+	rows, err = a.DB.Query(ctx, progressQuery, userID)
+	if err != nil {
+		return nil, err
+	}
+	for rows.Next() {
+		var p exportedProgress
+		if err := rows.Scan(&p.MeaningID, &p.Lemma, &p.Definition, &p.SRSStage, &p.LastReviewedAt, &p.NextReviewAt,
+			&p.ReviewCount, &p.MemoryHalfLife, &p.OptimalInterval, &p.LastRecallSuccess,
+			&p.CardState, &p.BuriedUntil, &p.LapseCount, &p.IsLeech); err != nil {
+			rows.Close()
+			return nil, err
+		}
+		export.Progress = append(export.Progress, p)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	planQuery := `
+		SELECT dp.id, dp.created_at, COALESCE(array_agg(dpw.meaning_id ORDER BY dpw.meaning_id)
+		       FILTER (WHERE dpw.meaning_id IS NOT NULL), '{}')
+		FROM daily_plans dp
+		LEFT JOIN daily_plan_words dpw ON dpw.plan_id = dp.id
+		WHERE dp.user_id = $1
+		GROUP BY dp.id, dp.created_at
+		ORDER BY dp.created_at
+	`
+	rows, err = a.DB.Query(ctx, planQuery, userID)
+	if err != nil {
+		return nil, err
+	}
+	for rows.Next() {
+		var plan exportedDailyPlan
+		if err := rows.Scan(&plan.ID, &plan.CreatedAt, &plan.MeaningIDs); err != nil {
+			rows.Close()
+			return nil, err
+		}
+		export.DailyPlans = append(export.DailyPlans, plan)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	rows, err = a.DB.Query(ctx, `
+		SELECT id, source, words_per_day, status, created_at, completed_at
+		FROM study_plans WHERE user_id = $1
+		ORDER BY created_at`, userID)
+	if err != nil {
+		return nil, err
+	}
+	export.StudyPlans, err = pgx.CollectRows(rows, scanStudyPlan)
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err = a.DB.Query(ctx, `
+		SELECT meaning_id, user_choice, is_cram, cram_session_id, response_ms, reviewed_at
+		FROM review_log WHERE user_id = $1
+		ORDER BY reviewed_at, id`, userID)
+	if err != nil {
+		return nil, err
+	}
+	export.Reviews, err = pgx.CollectRows(rows, pgx.RowToStructByPos[exportedReview])
+	if err != nil {
+		return nil, err
+	}
+
+	export.Vacations, err = srs.ListVacations(ctx, a.DB, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	export.Groups, err = a.listStudyGroups(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	// Assignments of every group, with the user's own progress where they are a student
+	export.Assignments = []models.Assignment{}
+	for _, group := range export.Groups {
+		assignments, err := srs.ListAssignments(ctx, a.DB, group.ID, userID, !isTeacher(group.Role))
+		if err != nil {
+			return nil, err
+		}
+		export.Assignments = append(export.Assignments, assignments...)
+	}
+
+	rows, err = a.DB.Query(ctx, `
+		SELECT t.id, t.source, t.status, t.estimated_vocabulary, COALESCE(t.band_results, 'null'::jsonb),
+		       t.created_at, t.completed_at, t.applied_at,
+		       COALESCE((SELECT json_agg(json_build_object('wordId', pa.word_id, 'band', pa.band, 'known', pa.known,
+		                                                   'answeredAt', pa.answered_at) ORDER BY pa.answered_at)
+		                 FROM placement_test_answers pa WHERE pa.test_id = t.id), '[]')
+		FROM placement_tests t WHERE t.user_id = $1
+		ORDER BY t.created_at`, userID)
+	if err != nil {
+		return nil, err
+	}
+	export.PlacementTests, err = pgx.CollectRows(rows, pgx.RowToStructByPos[exportedPlacementTest])
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err = a.DB.Query(ctx, `
+		SELECT s.id, s.source, s.unit, s.tag, s.failed_within_days, s.created_at, s.finished_at,
+		       COALESCE((SELECT json_agg(json_build_object('meaningId', w.meaning_id, 'position', w.position,
+		                                                   'attempts', w.attempts, 'done', w.done,
+		                                                   'lastAnsweredAt', w.last_answered_at) ORDER BY w.position)
+		                 FROM cram_session_words w WHERE w.session_id = s.id), '[]')
+		FROM cram_sessions s WHERE s.user_id = $1
+		ORDER BY s.created_at`, userID)
+	if err != nil {
+		return nil, err
+	}
+	export.CramSessions, err = pgx.CollectRows(rows, pgx.RowToStructByPos[exportedCramSession])
+	if err != nil {
+		return nil, err
+	}
+
+	export.Achievements, err = srs.ListAchievements(ctx, a.DB, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err = a.DB.Query(ctx,
+		`SELECT event, event_key, xp, awarded_at FROM xp_awards WHERE user_id = $1 ORDER BY awarded_at`, userID)
+	if err != nil {
+		return nil, err
+	}
+	export.XPAwards, err = pgx.CollectRows(rows, pgx.RowToStructByPos[exportedXPAward])
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err = a.DB.Query(ctx,
+		`SELECT id, meaning_id, created_at FROM leech_quizzes WHERE user_id = $1`, userID)
+	if err != nil {
+		return nil, err
+	}
+	export.LeechQuizzes, err = pgx.CollectRows(rows, pgx.RowToStructByPos[exportedLeechQuiz])
+	if err != nil {
+		return nil, err
+	}
+
+	export.NotificationSettings, err = a.notificationSettings(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	export.NotificationChannels, err = a.listNotificationChannels(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	export.NotificationLog, err = a.notificationLog(ctx, userID, 0)
+	if err != nil {
+		return nil, err
+	}
+
+	export.Webhooks, err = a.listWebhooks(ctx, &userID)
+	if err != nil {
+		return nil, err
+	}
+
+	return export, nil
+}
@@ -0,0 +1,27 @@
+package api
+
+import (
+	"log"
+	"net/http"
+
+	"sentencease/backend/internal/srs"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ListAchievements returns the user's XP and all achievements, earned and locked, with progress.
+func (a *API) ListAchievements(c *gin.Context) {
+	userID, ok := getUserID(c)
+	if !ok {
+		return
+	}
+
+	achievements, err := srs.ListAchievements(c.Request.Context(), a.DB, userID)
+	if err != nil {
+		log.Printf("ListAchievements: Error listing achievements for user %s: %v", userID, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to list achievements"})
+		return
+	}
+
+	c.JSON(http.StatusOK, achievements)
+}
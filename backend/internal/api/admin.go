@@ -0,0 +1,47 @@
+package api
+
+import (
+	"log"
+	"net/http"
+	"strconv"
+	"time"
+
+	"sentencease/backend/internal/srs"
+
+	"github.com/gin-gonic/gin"
+)
+
+// GetCalibrationReport compares the memory model's predicted recall probabilities with actual review
+// outcomes across all users. Query parameters: days limits the report to recent reviews (default
+// all), bins sets the number of calibration curve buckets (default 10).
+func (a *API) GetCalibrationReport(c *gin.Context) {
+	var since *time.Time
+	if daysStr := c.Query("days"); daysStr != "" {
+		days, err := strconv.Atoi(daysStr)
+		if err != nil || days < 1 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "days must be a positive integer"})
+			return
+		}
+		t := time.Now().AddDate(0, 0, -days)
+		since = &t
+	}
+
+	bins := srs.DefaultCalibrationBins
+	if binsStr := c.Query("bins"); binsStr != "" {
+		n, err := strconv.Atoi(binsStr)
+		if err != nil || n < 2 || n > 100 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "bins must be between 2 and 100"})
+			return
+		}
+		bins = n
+	}
+
+	report, err := srs.Calibration(c.Request.Context(), a.DB, since, bins)
+	if err != nil {
+		log.Printf("GetCalibrationReport: Error computing calibration report: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to compute calibration report"})
+		return
+	}
+
+	c.JSON(http.StatusOK, report)
+}
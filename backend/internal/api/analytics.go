@@ -0,0 +1,122 @@
+package api
+
+import (
+	"log"
+	"net/http"
+	"strconv"
+
+	"sentencease/backend/internal/srs"
+
+	"github.com/gin-gonic/gin"
+)
+
+// analyticsDays reads the days query parameter, writing a 400 response if it is invalid.
+func analyticsDays(c *gin.Context, defaultDays int) (int, bool) {
+	daysStr := c.Query("days")
+	if daysStr == "" {
+		return defaultDays, true
+	}
+	n, err := strconv.Atoi(daysStr)
+	if err != nil || n < 1 || n > srs.MaxAnalyticsDays {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "days must be between 1 and " + strconv.Itoa(srs.MaxAnalyticsDays)})
+		return 0, false
+	}
+	return n, true
+}
+
+// GetReviewHeatmap returns daily review counts for a calendar heatmap (query parameter days, default 365).
+func (a *API) GetReviewHeatmap(c *gin.Context) {
+	userID, ok := getUserID(c)
+	if !ok {
+		return
+	}
+	days, ok := analyticsDays(c, 365)
+	if !ok {
+		return
+	}
+
+	heatmap, err := srs.ReviewHeatmap(c.Request.Context(), a.DB, userID, days)
+	if err != nil {
+		log.Printf("GetReviewHeatmap: Error loading heatmap for user %s: %v", userID, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to load review heatmap"})
+		return
+	}
+
+	c.JSON(http.StatusOK, heatmap)
+}
+
+// GetStudyStreaks returns the user's current and longest study streaks.
+func (a *API) GetStudyStreaks(c *gin.Context) {
+	userID, ok := getUserID(c)
+	if !ok {
+		return
+	}
+
+	streak, err := srs.StudyStreaks(c.Request.Context(), a.DB, userID)
+	if err != nil {
+		log.Printf("GetStudyStreaks: Error computing streaks for user %s: %v", userID, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to compute study streaks"})
+		return
+	}
+
+	c.JSON(http.StatusOK, streak)
+}
+
+// GetRetention returns young and mature retention rates and half-life growth (query parameter days, default 30).
+func (a *API) GetRetention(c *gin.Context) {
+	userID, ok := getUserID(c)
+	if !ok {
+		return
+	}
+	days, ok := analyticsDays(c, 30)
+	if !ok {
+		return
+	}
+
+	stats, err := srs.Retention(c.Request.Context(), a.DB, userID, days)
+	if err != nil {
+		log.Printf("GetRetention: Error computing retention for user %s: %v", userID, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to compute retention"})
+		return
+	}
+
+	c.JSON(http.StatusOK, stats)
+}
+
+// GetTimeSpent returns the study time per day (query parameter days, default 30).
+func (a *API) GetTimeSpent(c *gin.Context) {
+	userID, ok := getUserID(c)
+	if !ok {
+		return
+	}
+	days, ok := analyticsDays(c, 30)
+	if !ok {
+		return
+	}
+
+	spent, err := srs.TimeSpent(c.Request.Context(), a.DB, userID, days)
+	if err != nil {
+		log.Printf("GetTimeSpent: Error loading time spent for user %s: %v", userID, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to load time spent"})
+		return
+	}
+
+	c.JSON(http.StatusOK, spent)
+}
+
+// GetSourceMastery returns the per-book breakdown of the user's progress.
+func (a *API) GetSourceMastery(c *gin.Context) {
+	userID, ok := getUserID(c)
+	if !ok {
+		return
+	}
+
+	mastery, err := srs.SourceMasteryBreakdown(c.Request.Context(), a.DB, userID)
+	if err != nil {
+		log.Printf("GetSourceMastery: Error computing mastery for user %s: %v", userID, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to compute mastery"})
+		return
+	}
+
+	c.JSON(http.StatusOK, mastery)
+}
@@ -0,0 +1,166 @@
+package api
+
+import (
+	"errors"
+	"log"
+	"net/http"
+
+	"sentencease/backend/internal/database"
+	"sentencease/backend/internal/models"
+	"sentencease/backend/internal/srs"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// ListAssignments returns the assignments of a group. Students also see their own progress.
+func (a *API) ListAssignments(c *gin.Context) {
+	userID, ok := getUserID(c)
+	if !ok {
+		return
+	}
+
+	group, ok := a.groupFromParam(c, userID)
+	if !ok {
+		return
+	}
+
+	assignments, err := srs.ListAssignments(c.Request.Context(), a.DB, group.ID, userID, !isTeacher(group.Role))
+	if err != nil {
+		log.Printf("ListAssignments: Error listing assignments of group %s: %v", group.ID, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to list assignments"})
+		return
+	}
+
+	c.JSON(http.StatusOK, assignments)
+}
+
+// CreateAssignment assigns meanings to every student of a group, either by meaning ID or by
+// vocabulary source and unit. Only teachers can create assignments.
+func (a *API) CreateAssignment(c *gin.Context) {
+	userID, ok := getUserID(c)
+	if !ok {
+		return
+	}
+
+	var req models.CreateAssignmentRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body: " + err.Error()})
+		return
+	}
+	if (len(req.MeaningIDs) == 0) == (req.Source == nil) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Exactly one of meaningIds and source is required"})
+		return
+	}
+	if req.Unit != nil && req.Source == nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "unit requires source"})
+		return
+	}
+
+	group, ok := a.groupFromParam(c, userID)
+	if !ok {
+		return
+	}
+	if !isTeacher(group.Role) {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Only teachers can create assignments"})
+		return
+	}
+
+	if len(req.MeaningIDs) > 0 {
+		req.MeaningIDs, ok = validateMeaningIDs(c, a.DB, req.MeaningIDs, srs.MaxAssignmentSize)
+		if !ok {
+			return
+		}
+	}
+
+	assignment, err := srs.CreateAssignment(c.Request.Context(), a.DB, group.ID, userID, req)
+	if err != nil {
+		switch {
+		case errors.Is(err, srs.ErrAssignmentInvalidDates):
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		case errors.Is(err, srs.ErrAssignmentTooLarge):
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		case errors.Is(err, srs.ErrAssignmentEmpty):
+			c.JSON(http.StatusUnprocessableEntity, gin.H{"error": err.Error()})
+		default:
+			log.Printf("CreateAssignment: Error creating assignment in group %s: %v", group.ID, err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create assignment"})
+		}
+		return
+	}
+
+	c.JSON(http.StatusCreated, assignment)
+}
+
+// DeleteAssignment deletes an assignment. Daily plans already generated from it are kept, and no
+// new ones are generated. Only teachers can delete assignments.
+func (a *API) DeleteAssignment(c *gin.Context) {
+	userID, ok := getUserID(c)
+	if !ok {
+		return
+	}
+
+	group, ok := a.groupFromParam(c, userID)
+	if !ok {
+		return
+	}
+	if !isTeacher(group.Role) {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Only teachers can delete assignments"})
+		return
+	}
+	assignmentID, err := uuid.Parse(c.Param("assignmentId"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid assignment ID"})
+		return
+	}
+
+	tag, err := a.DB.Exec(c.Request.Context(),
+		`DELETE FROM assignments WHERE id = $1 AND group_id = $2`, assignmentID, group.ID)
+	if err != nil {
+		log.Printf("DeleteAssignment: Error deleting assignment %s: %v", assignmentID, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete assignment"})
+		return
+	}
+	if tag.RowsAffected() == 0 {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Assignment not found"})
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// GetAssignmentDashboard returns each student's completion and retention on an assignment's
+// meanings. Only teachers can see it.
+func (a *API) GetAssignmentDashboard(c *gin.Context) {
+	userID, ok := getUserID(c)
+	if !ok {
+		return
+	}
+
+	group, ok := a.groupFromParam(c, userID)
+	if !ok {
+		return
+	}
+	if !isTeacher(group.Role) {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Only teachers can see the assignment dashboard"})
+		return
+	}
+	assignmentID, err := uuid.Parse(c.Param("assignmentId"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid assignment ID"})
+		return
+	}
+
+	dashboard, err := srs.AssignmentDashboard(c.Request.Context(), a.DB, group.ID, assignmentID, userID)
+	if err != nil {
+		if errors.Is(err, database.ErrNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Assignment not found"})
+			return
+		}
+		log.Printf("GetAssignmentDashboard: Error computing dashboard of assignment %s: %v", assignmentID, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get assignment dashboard"})
+		return
+	}
+
+	c.JSON(http.StatusOK, dashboard)
+}
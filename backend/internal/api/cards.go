@@ -0,0 +1,72 @@
+package api
+
+import (
+	"log"
+	"net/http"
+	"strconv"
+
+	"sentencease/backend/internal/models"
+	"sentencease/backend/internal/srs"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ListCards lists the user's cards in a non-active state. The state query parameter is one of
+// suspended (default), buried or mastered. Cards are restored with PUT /cards/:meaningId/state.
+func (a *API) ListCards(c *gin.Context) {
+	userID, ok := getUserID(c)
+	if !ok {
+		return
+	}
+
+	state := c.DefaultQuery("state", models.CardSuspended)
+	switch state {
+	case models.CardSuspended, models.CardBuried, models.CardMastered:
+	default:
+		c.JSON(http.StatusBadRequest, gin.H{"error": "state must be one of suspended, buried, mastered"})
+		return
+	}
+
+	cards, err := srs.ListCards(c.Request.Context(), a.DB, userID, state)
+	if err != nil {
+		log.Printf("ListCards: Error listing %s cards for user %s: %v", state, userID, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to list cards"})
+		return
+	}
+
+	c.JSON(http.StatusOK, cards)
+}
+
+// SetCardState marks a meaning as known (mastered), suspends it, buries it until the next study day,
+// or restores it to active.
+func (a *API) SetCardState(c *gin.Context) {
+	userID, ok := getUserID(c)
+	if !ok {
+		return
+	}
+
+	meaningID, err := strconv.Atoi(c.Param("meaningId"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid meaning ID"})
+		return
+	}
+
+	var req models.SetCardStateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body: " + err.Error()})
+		return
+	}
+
+	if _, ok := validateMeaningIDs(c, a.DB, []int{meaningID}, 1); !ok {
+		return
+	}
+
+	card, err := srs.SetCardState(c.Request.Context(), a.DB, userID, meaningID, req.State)
+	if err != nil {
+		log.Printf("SetCardState: Error setting meaning %d to %s for user %s: %v", meaningID, req.State, userID, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update card state"})
+		return
+	}
+
+	c.JSON(http.StatusOK, card)
+}
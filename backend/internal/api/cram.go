@@ -0,0 +1,143 @@
+package api
+
+import (
+	"errors"
+	"log"
+	"net/http"
+
+	"sentencease/backend/internal/database"
+	"sentencease/backend/internal/models"
+	"sentencease/backend/internal/srs"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// CreateCramSession starts a custom study session filtered by source, unit, tag and/or recent
+// failures. Reviews in the session do not change the user's SRS schedule.
+func (a *API) CreateCramSession(c *gin.Context) {
+	userID, ok := getUserID(c)
+	if !ok {
+		return
+	}
+
+	var req models.CreateCramSessionRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body: " + err.Error()})
+		return
+	}
+	if req.Source == nil && req.Unit == nil && req.Tag == nil && req.FailedWithinDays == nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "At least one of source, unit, tag or failedWithinDays is required"})
+		return
+	}
+
+	session, err := srs.StartCramSession(c.Request.Context(), a.DB, userID, req)
+	if err != nil {
+		if errors.Is(err, database.ErrNotFound) {
+			c.JSON(http.StatusUnprocessableEntity, gin.H{"error": "No words match the given filters"})
+			return
+		}
+		log.Printf("CreateCramSession: Error creating cram session for user %s: %v", userID, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create cram session"})
+		return
+	}
+
+	c.JSON(http.StatusCreated, session)
+}
+
+// GetCramSession returns a cram session with its progress.
+func (a *API) GetCramSession(c *gin.Context) {
+	userID, ok := getUserID(c)
+	if !ok {
+		return
+	}
+
+	sessionID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid cram session ID"})
+		return
+	}
+
+	session, err := srs.GetCramSession(c.Request.Context(), a.DB, userID, sessionID)
+	if err != nil {
+		if errors.Is(err, database.ErrNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Cram session not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get cram session"})
+		return
+	}
+
+	c.JSON(http.StatusOK, session)
+}
+
+// GetNextCramCard returns the next card of a cram session in the same shape as /learn/next-word.
+func (a *API) GetNextCramCard(c *gin.Context) {
+	userID, ok := getUserID(c)
+	if !ok {
+		return
+	}
+
+	sessionID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid cram session ID"})
+		return
+	}
+
+	wordCard, err := srs.NextCramCard(c.Request.Context(), a.DB, userID, sessionID)
+	if err != nil {
+		switch {
+		case errors.Is(err, database.ErrNotFound):
+			c.JSON(http.StatusNotFound, gin.H{"error": "Cram session not found"})
+		case errors.Is(err, srs.ErrCramSessionComplete):
+			c.JSON(http.StatusOK, gin.H{"message": "Cram session complete"})
+		default:
+			log.Printf("GetNextCramCard: Error getting next card of cram session %s: %v", sessionID, err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get next word"})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, wordCard)
+}
+
+// ReviewCramCard records an answer in a cram session without touching the SRS schedule.
+// Cards answered 模糊 or 不认识 come back later in the session.
+func (a *API) ReviewCramCard(c *gin.Context) {
+	userID, ok := getUserID(c)
+	if !ok {
+		return
+	}
+
+	sessionID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid cram session ID"})
+		return
+	}
+
+	var req models.ReviewRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body: " + err.Error()})
+		return
+	}
+
+	if _, ok := validateMeaningIDs(c, a.DB, []int{req.MeaningID}, 1); !ok {
+		return
+	}
+
+	session, err := srs.RecordCramReview(c.Request.Context(), a.DB, userID, sessionID, req.MeaningID, req.UserChoice, req.ResponseMs)
+	if err != nil {
+		switch {
+		case errors.Is(err, database.ErrNotFound):
+			c.JSON(http.StatusNotFound, gin.H{"error": "Cram session not found"})
+		case errors.Is(err, srs.ErrCramWordNotInSession):
+			c.JSON(http.StatusUnprocessableEntity, gin.H{"error": err.Error()})
+		default:
+			log.Printf("ReviewCramCard: Error recording review in cram session %s: %v", sessionID, err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to record review"})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, session)
+}
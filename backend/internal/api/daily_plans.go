@@ -0,0 +1,340 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"log"
+	"net/http"
+	"strconv"
+	"time"
+
+	"sentencease/backend/internal/models"
+	"sentencease/backend/internal/srs"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+)
+
+var errDailyPlanNotFound = errors.New("daily plan not found")
+
+// ListDailyPlans returns the user's most recent daily plans with word counts.
+// Plans that share a study day are listed separately but are merged when learning (see srs.GetDailyPlanProgress).
+func (a *API) ListDailyPlans(c *gin.Context) {
+	userID, ok := getUserID(c)
+	if !ok {
+		return
+	}
+
+	limit := 30
+	if limitStr := c.Query("limit"); limitStr != "" {
+		n, err := strconv.Atoi(limitStr)
+		if err != nil || n < 1 || n > 100 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "limit must be between 1 and 100"})
+			return
+		}
+		limit = n
+	}
+
+	ctx := c.Request.Context()
+	loc, dayStartHour, err := srs.GetUserDayConfig(ctx, a.DB, userID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get study day"})
+		return
+	}
+
+	rows, err := a.DB.Query(ctx, `
+		SELECT id, created_at, study_plan_id, assignment_id
+		FROM daily_plans WHERE user_id = $1
+		ORDER BY created_at DESC
+		LIMIT $2`, userID, limit)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to query daily plans"})
+		return
+	}
+	plans, err := pgx.CollectRows(rows, func(row pgx.CollectableRow) (models.DailyPlanSummary, error) {
+		var p models.DailyPlanSummary
+		err := row.Scan(&p.ID, &p.CreatedAt, &p.StudyPlanID, &p.AssignmentID)
+		return p, err
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to scan daily plan"})
+		return
+	}
+
+	planIndex := make(map[uuid.UUID]int, len(plans))
+	planIDs := make([]uuid.UUID, len(plans))
+	days := make([]srs.StudyDay, len(plans))
+	dayStarts := make([]time.Time, len(plans))
+	dayEnds := make([]time.Time, len(plans))
+	for i := range plans {
+		days[i] = srs.StudyDayAt(plans[i].CreatedAt, loc, dayStartHour)
+		plans[i].Date = days[i].Date()
+		planIndex[plans[i].ID] = i
+		planIDs[i] = plans[i].ID
+		dayStarts[i], dayEnds[i] = days[i].Start, days[i].End
+	}
+
+	wordRows, err := a.DB.Query(ctx, `
+		SELECT dpw.plan_id, up.last_reviewed_at,
+		       EXISTS (
+				SELECT 1 FROM review_log rl
+				WHERE rl.user_id = $1 AND rl.meaning_id = tracked_meaning_id($1, dpw.meaning_id) AND NOT rl.is_cram
+				  AND rl.reviewed_at >= p.day_start AND rl.reviewed_at < p.day_end
+		       )
+		FROM unnest($2::uuid[], $3::timestamptz[], $4::timestamptz[]) AS p(plan_id, day_start, day_end)
+		JOIN daily_plan_words dpw ON dpw.plan_id = p.plan_id
+		LEFT JOIN user_progress up ON up.meaning_id = tracked_meaning_id($1, dpw.meaning_id) AND up.user_id = $1`,
+		userID, planIDs, dayStarts, dayEnds)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to query daily plan words"})
+		return
+	}
+	defer wordRows.Close()
+
+	for wordRows.Next() {
+		var planID uuid.UUID
+		var lastReviewedAt *time.Time
+		var reviewedOnDay bool
+		if err := wordRows.Scan(&planID, &lastReviewedAt, &reviewedOnDay); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to scan daily plan word"})
+			return
+		}
+		i := planIndex[planID]
+		plans[i].TotalWords++
+		if planWordStatus(lastReviewedAt, reviewedOnDay, days[i]) == models.PlanWordCompleted {
+			plans[i].CompletedWords++
+		}
+	}
+	if err := wordRows.Err(); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error iterating daily plan words"})
+		return
+	}
+
+	c.JSON(http.StatusOK, plans)
+}
+
+// GetDailyPlan returns one daily plan with word details and the status of each word.
+func (a *API) GetDailyPlan(c *gin.Context) {
+	userID, ok := getUserID(c)
+	if !ok {
+		return
+	}
+
+	planID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid daily plan ID"})
+		return
+	}
+
+	plan, err := a.loadDailyPlan(c.Request.Context(), userID, planID)
+	if err != nil {
+		if errors.Is(err, errDailyPlanNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Daily plan not found"})
+			return
+		}
+		log.Printf("GetDailyPlan: Error loading daily plan %s: %v", planID, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get daily plan"})
+		return
+	}
+
+	c.JSON(http.StatusOK, plan)
+}
+
+// UpdateDailyPlan adds meanings to and/or removes meanings from a daily plan.
+func (a *API) UpdateDailyPlan(c *gin.Context) {
+	userID, ok := getUserID(c)
+	if !ok {
+		return
+	}
+
+	planID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid daily plan ID"})
+		return
+	}
+
+	var req models.UpdateDailyPlanRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body: " + err.Error()})
+		return
+	}
+	if len(req.AddMeaningIDs) == 0 && len(req.RemoveMeaningIDs) == 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "addMeaningIds or removeMeaningIds is required"})
+		return
+	}
+
+	ctx := c.Request.Context()
+	tx, err := a.DB.Begin(ctx)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to start transaction"})
+		return
+	}
+	defer tx.Rollback(ctx)
+
+	var owned bool
+	err = tx.QueryRow(ctx,
+		`SELECT EXISTS(SELECT 1 FROM daily_plans WHERE id = $1 AND user_id = $2)`, planID, userID).Scan(&owned)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get daily plan"})
+		return
+	}
+	if !owned {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Daily plan not found"})
+		return
+	}
+
+	if len(req.AddMeaningIDs) > 0 {
+		addIDs, ok := validateMeaningIDs(c, tx, req.AddMeaningIDs, maxDailyPlanSize)
+		if !ok {
+			return
+		}
+
+		_, err = tx.Exec(ctx, `
+			INSERT INTO daily_plan_words (plan_id, meaning_id)
+			SELECT $1, unnest($2::int[])
+			ON CONFLICT (plan_id, meaning_id) DO NOTHING`, planID, addIDs)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to add words to daily plan"})
+			return
+		}
+	}
+
+	if len(req.RemoveMeaningIDs) > 0 {
+		_, err = tx.Exec(ctx,
+			`DELETE FROM daily_plan_words WHERE plan_id = $1 AND meaning_id = ANY($2)`, planID, req.RemoveMeaningIDs)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to remove words from daily plan"})
+			return
+		}
+	}
+
+	var planSize int
+	err = tx.QueryRow(ctx, `SELECT COUNT(*) FROM daily_plan_words WHERE plan_id = $1`, planID).Scan(&planSize)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to count daily plan words"})
+		return
+	}
+	if planSize == 0 {
+		c.JSON(http.StatusUnprocessableEntity, meaningIDsError{Error: "A daily plan cannot be empty; delete it instead"})
+		return
+	}
+	if planSize > maxDailyPlanSize {
+		c.JSON(http.StatusUnprocessableEntity, meaningIDsError{Error: "Too many meaning IDs", MaxSize: maxDailyPlanSize})
+		return
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to commit transaction"})
+		return
+	}
+
+	plan, err := a.loadDailyPlan(ctx, userID, planID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get daily plan"})
+		return
+	}
+
+	c.JSON(http.StatusOK, plan)
+}
+
+// DeleteDailyPlan deletes a daily plan. Learning progress on its words is kept.
+func (a *API) DeleteDailyPlan(c *gin.Context) {
+	userID, ok := getUserID(c)
+	if !ok {
+		return
+	}
+
+	planID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid daily plan ID"})
+		return
+	}
+
+	tag, err := a.DB.Exec(c.Request.Context(), `DELETE FROM daily_plans WHERE id = $1 AND user_id = $2`, planID, userID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete daily plan"})
+		return
+	}
+	if tag.RowsAffected() == 0 {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Daily plan not found"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Daily plan deleted successfully"})
+}
+
+// loadDailyPlan reads a daily plan owned by the user with all of its words.
+func (a *API) loadDailyPlan(ctx context.Context, userID, planID uuid.UUID) (*models.DailyPlanDetail, error) {
+	var plan models.DailyPlanDetail
+	err := a.DB.QueryRow(ctx,
+		`SELECT id, created_at, study_plan_id, assignment_id FROM daily_plans WHERE id = $1 AND user_id = $2`,
+		planID, userID).Scan(&plan.ID, &plan.CreatedAt, &plan.StudyPlanID, &plan.AssignmentID)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, errDailyPlanNotFound
+		}
+		return nil, err
+	}
+
+	loc, dayStartHour, err := srs.GetUserDayConfig(ctx, a.DB, userID)
+	if err != nil {
+		return nil, err
+	}
+	day := srs.StudyDayAt(plan.CreatedAt, loc, dayStartHour)
+	plan.Date = day.Date()
+
+	rows, err := a.DB.Query(ctx, `
+		SELECT m.id, m.word_id, w.lemma, COALESCE(m.part_of_speech, ''), m.definition, m.unit,
+		       up.last_reviewed_at, up.next_review_at, up.card_state, up.buried_until,
+		       EXISTS (
+				SELECT 1 FROM review_log rl
+				WHERE rl.user_id = $2 AND rl.meaning_id = tracked_meaning_id($2, m.id) AND NOT rl.is_cram
+				  AND rl.reviewed_at >= $3 AND rl.reviewed_at < $4
+		       )
+		FROM daily_plan_words dpw
+		JOIN meanings m ON m.id = dpw.meaning_id
+		JOIN words w ON w.id = m.word_id
+		LEFT JOIN user_progress up ON up.meaning_id = tracked_meaning_id($2, m.id) AND up.user_id = $2
+		WHERE dpw.plan_id = $1
+		ORDER BY m.id`, planID, userID, day.Start, day.End)
+	if err != nil {
+		return nil, err
+	}
+	plan.Words, err = pgx.CollectRows(rows, func(row pgx.CollectableRow) (models.DailyPlanWord, error) {
+		var w models.DailyPlanWord
+		var cardState *string
+		var buriedUntil *time.Time
+		var reviewedOnDay bool
+		err := row.Scan(&w.MeaningID, &w.WordID, &w.Lemma, &w.PartOfSpeech, &w.Definition, &w.Unit,
+			&w.LastReviewedAt, &w.NextReviewAt, &cardState, &buriedUntil, &reviewedOnDay)
+		w.Status = planWordStatus(w.LastReviewedAt, reviewedOnDay, day)
+		w.CardState = srs.EffectiveCardState(cardState, buriedUntil)
+		return w, err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	plan.TotalWords = len(plan.Words)
+	for _, w := range plan.Words {
+		if w.Status == models.PlanWordCompleted {
+			plan.CompletedWords++
+		}
+	}
+	return &plan, nil
+}
+
+// planWordStatus classifies a plan word by whether it was reviewed during the plan's day. Reviews
+// on later days do not complete an earlier plan. reviewedOnDay reports a review logged during the
+// day, which covers words reviewed again since.
+func planWordStatus(lastReviewedAt *time.Time, reviewedOnDay bool, day srs.StudyDay) string {
+	switch {
+	case lastReviewedAt == nil:
+		return models.PlanWordNew
+	case reviewedOnDay, day.Contains(*lastReviewedAt):
+		return models.PlanWordCompleted
+	default:
+		return models.PlanWordPending
+	}
+}
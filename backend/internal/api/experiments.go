@@ -0,0 +1,102 @@
+package api
+
+import (
+	"errors"
+	"log"
+	"net/http"
+
+	"sentencease/backend/internal/database"
+	"sentencease/backend/internal/models"
+	"sentencease/backend/internal/srs"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// ListExperiments returns all scheduling experiments, newest first.
+func (a *API) ListExperiments(c *gin.Context) {
+	experiments, err := srs.ListExperiments(c.Request.Context(), a.DB)
+	if err != nil {
+		log.Printf("ListExperiments: Error listing experiments: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to list experiments"})
+		return
+	}
+
+	c.JSON(http.StatusOK, experiments)
+}
+
+// CreateExperiment starts a scheduling experiment. Users are split between its arms immediately.
+func (a *API) CreateExperiment(c *gin.Context) {
+	var req models.CreateExperimentRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body: " + err.Error()})
+		return
+	}
+
+	names := make(map[string]bool, len(req.Arms))
+	for _, arm := range req.Arms {
+		if names[arm.Name] {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Arm names must be unique: " + arm.Name})
+			return
+		}
+		names[arm.Name] = true
+	}
+
+	experiment, err := srs.CreateExperiment(c.Request.Context(), a.DB, req)
+	if err != nil {
+		switch {
+		case errors.Is(err, srs.ErrExperimentRunning), errors.Is(err, srs.ErrExperimentNameTaken):
+			c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+		default:
+			log.Printf("CreateExperiment: Error creating experiment %q: %v", req.Name, err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create experiment"})
+		}
+		return
+	}
+
+	c.JSON(http.StatusCreated, experiment)
+}
+
+// StopExperiment stops an experiment; all users return to the global algorithm.
+func (a *API) StopExperiment(c *gin.Context) {
+	experimentID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid experiment ID"})
+		return
+	}
+
+	experiment, err := srs.StopExperiment(c.Request.Context(), a.DB, experimentID)
+	if err != nil {
+		if errors.Is(err, database.ErrNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Experiment not found"})
+			return
+		}
+		log.Printf("StopExperiment: Error stopping experiment %s: %v", experimentID, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to stop experiment"})
+		return
+	}
+
+	c.JSON(http.StatusOK, experiment)
+}
+
+// GetExperimentReport compares retention and workload between the arms of an experiment.
+func (a *API) GetExperimentReport(c *gin.Context) {
+	experimentID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid experiment ID"})
+		return
+	}
+
+	report, err := srs.ExperimentReport(c.Request.Context(), a.DB, experimentID)
+	if err != nil {
+		if errors.Is(err, database.ErrNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Experiment not found"})
+			return
+		}
+		log.Printf("GetExperimentReport: Error computing report for experiment %s: %v", experimentID, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to compute experiment report"})
+		return
+	}
+
+	c.JSON(http.StatusOK, report)
+}
@@ -0,0 +1,62 @@
+package api
+
+import (
+	"log"
+	"net/http"
+	"strconv"
+
+	"sentencease/backend/internal/models"
+	"sentencease/backend/internal/srs"
+
+	"github.com/gin-gonic/gin"
+)
+
+// GetForecast returns the review workload of the next N study days (query parameter days, default 7).
+func (a *API) GetForecast(c *gin.Context) {
+	userID, ok := getUserID(c)
+	if !ok {
+		return
+	}
+
+	days := 7
+	if daysStr := c.Query("days"); daysStr != "" {
+		n, err := strconv.Atoi(daysStr)
+		if err != nil || n < 1 || n > srs.MaxForecastDays {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "days must be between 1 and " + strconv.Itoa(srs.MaxForecastDays)})
+			return
+		}
+		days = n
+	}
+
+	forecast, err := srs.Forecast(c.Request.Context(), a.DB, userID, days)
+	if err != nil {
+		log.Printf("GetForecast: Error computing forecast for user %s: %v", userID, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to compute forecast"})
+		return
+	}
+
+	c.JSON(http.StatusOK, forecast)
+}
+
+// RescheduleBacklog spreads the user's overdue cards evenly over the next N study days.
+func (a *API) RescheduleBacklog(c *gin.Context) {
+	userID, ok := getUserID(c)
+	if !ok {
+		return
+	}
+
+	var req models.RescheduleBacklogRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body: " + err.Error()})
+		return
+	}
+
+	result, err := srs.RescheduleBacklog(c.Request.Context(), a.DB, userID, req.Days)
+	if err != nil {
+		log.Printf("RescheduleBacklog: Error rescheduling backlog for user %s: %v", userID, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to reschedule backlog"})
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
@@ -0,0 +1,512 @@
+package api
+
+import (
+	"context"
+	"crypto/rand"
+	"errors"
+	"log"
+	"net/http"
+	"strings"
+
+	"sentencease/backend/internal/database"
+	"sentencease/backend/internal/models"
+	"sentencease/backend/internal/srs"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+// inviteCodeAlphabet leaves out characters that are easily confused, such as 0/O and 1/I.
+const inviteCodeAlphabet = "ABCDEFGHJKLMNPQRSTUVWXYZ23456789"
+
+const inviteCodeLength = 8
+
+// newInviteCode returns a random group invite code.
+func newInviteCode() (string, error) {
+	b := make([]byte, inviteCodeLength)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	for i := range b {
+		b[i] = inviteCodeAlphabet[int(b[i])%len(inviteCodeAlphabet)]
+	}
+	return string(b), nil
+}
+
+// withInviteCode runs fn with fresh invite codes until it does not collide with an existing one.
+func withInviteCode(fn func(code string) error) error {
+	for attempt := 0; ; attempt++ {
+		code, err := newInviteCode()
+		if err != nil {
+			return err
+		}
+		err = fn(code)
+		var pgErr *pgconn.PgError
+		if attempt < 3 && errors.As(err, &pgErr) && pgErr.Code == "23505" && pgErr.ConstraintName == "study_groups_invite_code_key" {
+			continue
+		}
+		return err
+	}
+}
+
+// studyGroupQuery selects the groups a user belongs to, with the user's role. The invite code is
+// only returned to the owner.
+const studyGroupQuery = `
+	SELECT g.id, g.name, g.description, g.owner_id,
+	       CASE WHEN m.role = 'owner' THEN g.invite_code END,
+	       m.role,
+	       (SELECT COUNT(*) FROM study_group_members c WHERE c.group_id = g.id),
+	       g.created_at
+	FROM study_groups g
+	JOIN study_group_members m ON m.group_id = g.id AND m.user_id = $1
+`
+
+func scanStudyGroup(row pgx.CollectableRow) (models.StudyGroup, error) {
+	var g models.StudyGroup
+	err := row.Scan(&g.ID, &g.Name, &g.Description, &g.OwnerID, &g.InviteCode, &g.Role, &g.MemberCount, &g.CreatedAt)
+	return g, err
+}
+
+// listStudyGroups returns the groups the user belongs to, oldest membership first.
+func (a *API) listStudyGroups(ctx context.Context, userID uuid.UUID) ([]models.StudyGroup, error) {
+	rows, err := a.DB.Query(ctx, studyGroupQuery+` ORDER BY m.joined_at`, userID)
+	if err != nil {
+		return nil, err
+	}
+	return pgx.CollectRows(rows, scanStudyGroup)
+}
+
+// loadStudyGroup returns a group the user belongs to, or database.ErrNotFound.
+func (a *API) loadStudyGroup(ctx context.Context, userID, groupID uuid.UUID) (*models.StudyGroup, error) {
+	rows, err := a.DB.Query(ctx, studyGroupQuery+` WHERE g.id = $2`, userID, groupID)
+	if err != nil {
+		return nil, err
+	}
+	group, err := pgx.CollectOneRow(rows, scanStudyGroup)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, database.ErrNotFound
+		}
+		return nil, err
+	}
+	return &group, nil
+}
+
+// isTeacher reports whether a group role may set assignments and see member progress.
+func isTeacher(role string) bool {
+	return role == models.GroupOwner || role == models.GroupTeacher
+}
+
+// groupFromParam loads the group in the :id parameter for the current user and writes an error
+// response if it cannot. Groups the user does not belong to are reported as not found.
+func (a *API) groupFromParam(c *gin.Context, userID uuid.UUID) (*models.StudyGroup, bool) {
+	groupID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid group ID"})
+		return nil, false
+	}
+	group, err := a.loadStudyGroup(c.Request.Context(), userID, groupID)
+	if err != nil {
+		if errors.Is(err, database.ErrNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Study group not found"})
+			return nil, false
+		}
+		log.Printf("groupFromParam: Error loading group %s for user %s: %v", groupID, userID, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get study group"})
+		return nil, false
+	}
+	return group, true
+}
+
+// ListStudyGroups returns the study groups the user belongs to.
+func (a *API) ListStudyGroups(c *gin.Context) {
+	userID, ok := getUserID(c)
+	if !ok {
+		return
+	}
+
+	groups, err := a.listStudyGroups(c.Request.Context(), userID)
+	if err != nil {
+		log.Printf("ListStudyGroups: Error listing groups for user %s: %v", userID, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to list study groups"})
+		return
+	}
+
+	c.JSON(http.StatusOK, groups)
+}
+
+// CreateStudyGroup creates a study group owned by the user, with a new invite code.
+func (a *API) CreateStudyGroup(c *gin.Context) {
+	userID, ok := getUserID(c)
+	if !ok {
+		return
+	}
+
+	var req models.CreateStudyGroupRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body: " + err.Error()})
+		return
+	}
+	req.Name = strings.TrimSpace(req.Name)
+	if req.Name == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Group name must not be empty"})
+		return
+	}
+
+	ctx := c.Request.Context()
+	var groupID uuid.UUID
+	err := withInviteCode(func(code string) error {
+		tx, err := a.DB.Begin(ctx)
+		if err != nil {
+			return err
+		}
+		defer tx.Rollback(ctx)
+
+		err = tx.QueryRow(ctx, `
+			INSERT INTO study_groups (name, description, owner_id, invite_code)
+			VALUES ($1, $2, $3, $4) RETURNING id`, req.Name, req.Description, userID, code).Scan(&groupID)
+		if err != nil {
+			return err
+		}
+		_, err = tx.Exec(ctx,
+			`INSERT INTO study_group_members (group_id, user_id, role) VALUES ($1, $2, $3)`,
+			groupID, userID, models.GroupOwner)
+		if err != nil {
+			return err
+		}
+		return tx.Commit(ctx)
+	})
+	if err != nil {
+		log.Printf("CreateStudyGroup: Error creating group for user %s: %v", userID, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create study group"})
+		return
+	}
+
+	group, err := a.loadStudyGroup(ctx, userID, groupID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get study group"})
+		return
+	}
+
+	c.JSON(http.StatusCreated, group)
+}
+
+// GetStudyGroup returns a group the user belongs to.
+func (a *API) GetStudyGroup(c *gin.Context) {
+	userID, ok := getUserID(c)
+	if !ok {
+		return
+	}
+
+	group, ok := a.groupFromParam(c, userID)
+	if !ok {
+		return
+	}
+
+	c.JSON(http.StatusOK, group)
+}
+
+// JoinStudyGroup adds the user to the group with the given invite code. Joining a group the user
+// already belongs to returns it unchanged.
+func (a *API) JoinStudyGroup(c *gin.Context) {
+	userID, ok := getUserID(c)
+	if !ok {
+		return
+	}
+
+	var req models.JoinStudyGroupRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body: " + err.Error()})
+		return
+	}
+
+	ctx := c.Request.Context()
+	var groupID uuid.UUID
+	err := a.DB.QueryRow(ctx, `SELECT id FROM study_groups WHERE invite_code = $1`,
+		strings.ToUpper(strings.TrimSpace(req.InviteCode))).Scan(&groupID)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Invalid invite code"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to look up invite code"})
+		return
+	}
+
+	_, err = a.DB.Exec(ctx, `
+		INSERT INTO study_group_members (group_id, user_id, role) VALUES ($1, $2, $3)
+		ON CONFLICT (group_id, user_id) DO NOTHING`, groupID, userID, models.GroupMember)
+	if err != nil {
+		log.Printf("JoinStudyGroup: Error adding user %s to group %s: %v", userID, groupID, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to join study group"})
+		return
+	}
+
+	group, err := a.loadStudyGroup(ctx, userID, groupID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get study group"})
+		return
+	}
+
+	c.JSON(http.StatusOK, group)
+}
+
+// LeaveStudyGroup removes the user from a group. The owner cannot leave; they delete the group instead.
+func (a *API) LeaveStudyGroup(c *gin.Context) {
+	userID, ok := getUserID(c)
+	if !ok {
+		return
+	}
+
+	group, ok := a.groupFromParam(c, userID)
+	if !ok {
+		return
+	}
+	if group.Role == models.GroupOwner {
+		c.JSON(http.StatusConflict, gin.H{"error": "The owner cannot leave the group; delete it instead"})
+		return
+	}
+
+	_, err := a.DB.Exec(c.Request.Context(),
+		`DELETE FROM study_group_members WHERE group_id = $1 AND user_id = $2`, group.ID, userID)
+	if err != nil {
+		log.Printf("LeaveStudyGroup: Error removing user %s from group %s: %v", userID, group.ID, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to leave study group"})
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// RemoveStudyGroupMember removes another member from a group. Only the owner can remove members.
+func (a *API) RemoveStudyGroupMember(c *gin.Context) {
+	userID, ok := getUserID(c)
+	if !ok {
+		return
+	}
+
+	group, ok := a.groupFromParam(c, userID)
+	if !ok {
+		return
+	}
+	if group.Role != models.GroupOwner {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Only the group owner can remove members"})
+		return
+	}
+	memberID, err := uuid.Parse(c.Param("userId"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid user ID"})
+		return
+	}
+	if memberID == userID {
+		c.JSON(http.StatusConflict, gin.H{"error": "The owner cannot leave the group; delete it instead"})
+		return
+	}
+
+	tag, err := a.DB.Exec(c.Request.Context(),
+		`DELETE FROM study_group_members WHERE group_id = $1 AND user_id = $2`, group.ID, memberID)
+	if err != nil {
+		log.Printf("RemoveStudyGroupMember: Error removing user %s from group %s: %v", memberID, group.ID, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to remove member"})
+		return
+	}
+	if tag.RowsAffected() == 0 {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Member not found"})
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// UpdateStudyGroupMember appoints a member as a teacher of the group or makes a teacher a student
+// again. Only the owner can change roles.
+func (a *API) UpdateStudyGroupMember(c *gin.Context) {
+	userID, ok := getUserID(c)
+	if !ok {
+		return
+	}
+
+	group, ok := a.groupFromParam(c, userID)
+	if !ok {
+		return
+	}
+	if group.Role != models.GroupOwner {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Only the group owner can change member roles"})
+		return
+	}
+	memberID, err := uuid.Parse(c.Param("userId"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid user ID"})
+		return
+	}
+
+	var req models.UpdateGroupMemberRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body: " + err.Error()})
+		return
+	}
+
+	tag, err := a.DB.Exec(c.Request.Context(),
+		`UPDATE study_group_members SET role = $3 WHERE group_id = $1 AND user_id = $2 AND role <> $4`,
+		group.ID, memberID, req.Role, models.GroupOwner)
+	if err != nil {
+		log.Printf("UpdateStudyGroupMember: Error updating role of user %s in group %s: %v", memberID, group.ID, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update member"})
+		return
+	}
+	if tag.RowsAffected() == 0 {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Member not found"})
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// DeleteStudyGroup deletes a group and all its memberships. Only the owner can delete a group.
+func (a *API) DeleteStudyGroup(c *gin.Context) {
+	userID, ok := getUserID(c)
+	if !ok {
+		return
+	}
+
+	group, ok := a.groupFromParam(c, userID)
+	if !ok {
+		return
+	}
+	if group.Role != models.GroupOwner {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Only the group owner can delete the group"})
+		return
+	}
+
+	if _, err := a.DB.Exec(c.Request.Context(), `DELETE FROM study_groups WHERE id = $1`, group.ID); err != nil {
+		log.Printf("DeleteStudyGroup: Error deleting group %s: %v", group.ID, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete study group"})
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// RegenerateInviteCode replaces a group's invite code, so the old one can no longer be used to join.
+func (a *API) RegenerateInviteCode(c *gin.Context) {
+	userID, ok := getUserID(c)
+	if !ok {
+		return
+	}
+
+	group, ok := a.groupFromParam(c, userID)
+	if !ok {
+		return
+	}
+	if group.Role != models.GroupOwner {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Only the group owner can change the invite code"})
+		return
+	}
+
+	ctx := c.Request.Context()
+	err := withInviteCode(func(code string) error {
+		_, err := a.DB.Exec(ctx, `UPDATE study_groups SET invite_code = $2 WHERE id = $1`, group.ID, code)
+		return err
+	})
+	if err != nil {
+		log.Printf("RegenerateInviteCode: Error updating invite code of group %s: %v", group.ID, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to regenerate invite code"})
+		return
+	}
+
+	group, err = a.loadStudyGroup(ctx, userID, group.ID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get study group"})
+		return
+	}
+
+	c.JSON(http.StatusOK, group)
+}
+
+// GetGroupLeaderboard ranks the group's members for the current week.
+// Query parameter metric is reviews (default), newWords or streak.
+func (a *API) GetGroupLeaderboard(c *gin.Context) {
+	userID, ok := getUserID(c)
+	if !ok {
+		return
+	}
+
+	metric := c.DefaultQuery("metric", models.LeaderboardReviews)
+	switch metric {
+	case models.LeaderboardReviews, models.LeaderboardNewWords, models.LeaderboardStreak:
+	default:
+		c.JSON(http.StatusBadRequest, gin.H{"error": "metric must be reviews, newWords or streak"})
+		return
+	}
+
+	group, ok := a.groupFromParam(c, userID)
+	if !ok {
+		return
+	}
+
+	leaderboard, err := srs.GroupLeaderboard(c.Request.Context(), a.DB, group.ID, userID, metric)
+	if err != nil {
+		log.Printf("GetGroupLeaderboard: Error computing leaderboard of group %s: %v", group.ID, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get leaderboard"})
+		return
+	}
+
+	c.JSON(http.StatusOK, leaderboard)
+}
+
+// GetGroupProgress returns the aggregate and per-member progress of a group. Only teachers can see it,
+// and it lists every member, including those who opted out of leaderboards.
+func (a *API) GetGroupProgress(c *gin.Context) {
+	userID, ok := getUserID(c)
+	if !ok {
+		return
+	}
+
+	group, ok := a.groupFromParam(c, userID)
+	if !ok {
+		return
+	}
+	if !isTeacher(group.Role) {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Only teachers can see member progress"})
+		return
+	}
+
+	progress, err := srs.GroupProgress(c.Request.Context(), a.DB, group.ID, userID)
+	if err != nil {
+		log.Printf("GetGroupProgress: Error computing progress of group %s: %v", group.ID, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get group progress"})
+		return
+	}
+
+	c.JSON(http.StatusOK, progress)
+}
+
+// transferOwnedGroups hands every group the user owns to a successor before the account is
+// deleted: the longest-standing teacher, otherwise the longest-standing member. Groups without
+// other members are deleted. study_groups.owner_id is ON DELETE RESTRICT, so deleting an owner
+// without this fails instead of silently removing the group for everyone.
+func transferOwnedGroups(ctx context.Context, tx pgx.Tx, userID uuid.UUID) error {
+	_, err := tx.Exec(ctx, `
+		WITH successor AS (
+			SELECT DISTINCT ON (m.group_id) m.group_id, m.user_id
+			FROM study_group_members m
+			JOIN study_groups g ON g.id = m.group_id
+			WHERE g.owner_id = $1 AND m.user_id <> $1
+			ORDER BY m.group_id, (m.role = $3) DESC, m.joined_at, m.user_id
+		), promoted AS (
+			UPDATE study_group_members m SET role = $2
+			FROM successor s
+			WHERE m.group_id = s.group_id AND m.user_id = s.user_id
+		)
+		UPDATE study_groups g SET owner_id = s.user_id
+		FROM successor s
+		WHERE g.id = s.group_id`, userID, models.GroupOwner, models.GroupTeacher)
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.Exec(ctx, `DELETE FROM study_groups WHERE owner_id = $1`, userID)
+	return err
+}
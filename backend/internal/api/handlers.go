@@ -7,12 +7,14 @@ import (
 	"net/http"
 
 	"errors"
+	"fmt"
 	"sentencease/backend/internal/auth"
 	"sentencease/backend/internal/database"
 	"sentencease/backend/internal/models"
 	"sentencease/backend/internal/srs"
 
 	"strconv"
+	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
@@ -24,6 +26,13 @@ import (
 type API struct {
 	DB           *pgxpool.Pool
 	JWTSecretKey string
+
+	// OIDC is nil when external login is not configured.
+	OIDC                  *auth.OIDCProvider
+	OIDCPostLoginRedirect string
+
+	// VAPIDPublicKey is served to browsers subscribing to Web Push reminders; empty when disabled.
+	VAPIDPublicKey string
 }
 
 // New creates a new API instance with the given database connection and JWT secret.
@@ -49,14 +58,33 @@ func (a *API) Register(c *gin.Context) {
 	}
 	user.PasswordHash = hashedPassword
 
+	ctx := c.Request.Context()
+	tx, err := a.DB.Begin(ctx)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to register user"})
+		return
+	}
+	defer tx.Rollback(ctx)
+
 	query := `INSERT INTO users (email, password_hash) VALUES ($1, $2) RETURNING id`
-	err = a.DB.QueryRow(context.Background(), query, user.Email, user.PasswordHash).Scan(&user.ID)
+	err = tx.QueryRow(ctx, query, user.Email, user.PasswordHash).Scan(&user.ID)
 	if err != nil {
 		log.Printf("Failed to register user: %v", err)
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to register user. The email might already be taken."})
 		return
 	}
 
+	if err := enqueueUserRegistered(ctx, tx, user.ID, user.Email, "password"); err != nil {
+		log.Printf("Failed to queue user.registered webhooks: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to register user"})
+		return
+	}
+	if err := tx.Commit(ctx); err != nil {
+		log.Printf("Failed to register user: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to register user"})
+		return
+	}
+
 	c.JSON(http.StatusCreated, gin.H{"message": "User registered successfully", "userID": user.ID})
 }
 
@@ -176,21 +204,12 @@ func (a *API) ReviewWord(c *gin.Context) {
 		userID, req.MeaningID, req.UserChoice)
 
 	// 验证meaningID是否存在
-	var meaningExists bool
-	checkQuery := `SELECT EXISTS(SELECT 1 FROM meanings WHERE id = $1)`
-	err := a.DB.QueryRow(c.Request.Context(), checkQuery, req.MeaningID).Scan(&meaningExists)
-	if err != nil {
-		log.Printf("ReviewWord: Error checking meaning existence: %v", err)
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to validate meaning ID"})
-		return
-	}
-	if !meaningExists {
+	if _, ok := validateMeaningIDs(c, a.DB, []int{req.MeaningID}, 1); !ok {
 		log.Printf("ReviewWord: Meaning ID %d does not exist", req.MeaningID)
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid meaning ID: not found"})
 		return
 	}
 
-	err = srs.UpdateProgress(c.Request.Context(), a.DB, userID, req.MeaningID, req.UserChoice)
+	err := srs.UpdateProgress(c.Request.Context(), a.DB, userID, req.MeaningID, req.UserChoice, req.ResponseMs)
 	if err != nil {
 		log.Printf("ReviewWord: Error updating progress for user %s on meaning %d: %v",
 			userID, req.MeaningID, err)
@@ -200,7 +219,21 @@ func (a *API) ReviewWord(c *gin.Context) {
 
 	log.Printf("ReviewWord: Successfully updated progress for user %s on meaning %d",
 		userID, req.MeaningID)
-	c.JSON(http.StatusOK, gin.H{"message": "Progress updated successfully"})
+
+	// 奖励经验值和成就失败不影响复习结果
+	rewards := &models.Rewards{Achievements: []models.Achievement{}}
+	events, err := srs.ReviewEvents(c.Request.Context(), a.DB, userID, req.MeaningID)
+	if err == nil {
+		var emitted *models.Rewards
+		if emitted, err = srs.EmitEvents(c.Request.Context(), a.DB, userID, events); err == nil {
+			rewards = emitted
+		}
+	}
+	if err != nil {
+		log.Printf("ReviewWord: Error awarding achievements to user %s: %v", userID, err)
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Progress updated successfully", "rewards": rewards})
 }
 
 func (a *API) GetVocabSources(c *gin.Context) {
@@ -279,37 +312,110 @@ func (a *API) GetWordsBySource(c *gin.Context) {
 	c.JSON(http.StatusOK, wordsByUnit)
 }
 
+// Word selection modes for GetWordsForSelection.
+const (
+	selectionModeAll     = "all"     // Every meaning in the source except mastered and suspended ones
+	selectionModeUnseen  = "unseen"  // Meanings the user has never studied (the default)
+	selectionModeDue     = "due"     // Studied meanings whose review is due, most overdue first
+	selectionModeWeakest = "weakest" // Studied meanings with the lowest memory half-life first
+	selectionModeUnit    = "unit"    // Meanings of a single unit except mastered and suspended ones (requires the unit parameter)
+)
+
+// GetWordsForSelection lists candidate meanings for building a daily plan.
+// Query parameters: source (required), mode (see selectionMode*, default "unseen"), order ("random" or
+// sequential), count (page size), offset, seed (makes random order stable across pages) and unit.
+// The total number of matching meanings is returned in the X-Total-Count header.
+// For users in word mode only the first meaning of each word, which tracks the word, is listed.
 func (a *API) GetWordsForSelection(c *gin.Context) {
+	userID, ok := getUserID(c)
+	if !ok {
+		return
+	}
+
 	source := c.Query("source")
 	order := c.Query("order")
+	mode := c.DefaultQuery("mode", selectionModeUnseen)
 	countStr := c.Query("count")
 	count, err := strconv.Atoi(countStr)
-	if err != nil {
+	if err != nil || count < 1 || count > 500 {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid count parameter"})
 		return
 	}
 
+	offset := 0
+	if offsetStr := c.Query("offset"); offsetStr != "" {
+		offset, err = strconv.Atoi(offsetStr)
+		if err != nil || offset < 0 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid offset parameter"})
+			return
+		}
+	}
+
 	if source == "" || order == "" {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "source and order parameters are required"})
 		return
 	}
 
-	query := `
-        SELECT m.id, w.lemma, m.definition
+	fromClause := `
         FROM meanings m
         JOIN words w ON m.word_id = w.id
-        WHERE w.source = $1
+        LEFT JOIN user_progress up ON up.meaning_id = m.id AND up.user_id = $1
+        WHERE w.source = $2 AND m.id = tracked_meaning_id($1, m.id)
     `
+	args := []interface{}{userID, source}
+
+	var orderBy string
+	// 已掌握或暂停的词义不应再被选入计划
+	const notRetired = " AND (up.card_state IS NULL OR up.card_state NOT IN ('mastered', 'suspended'))"
+	switch mode {
+	case selectionModeAll:
+		fromClause += notRetired
+	case selectionModeUnseen:
+		fromClause += " AND up.meaning_id IS NULL"
+	case selectionModeDue:
+		fromClause += " AND up.next_review_at <= now() AND " + srs.SchedulableCard
+		orderBy = " ORDER BY up.next_review_at, m.id"
+	case selectionModeWeakest:
+		fromClause += " AND up.meaning_id IS NOT NULL AND " + srs.SchedulableCard
+		orderBy = " ORDER BY COALESCE(up.memory_halflife, 4.0), up.last_recall_success NULLS FIRST, m.id"
+	case selectionModeUnit:
+		unit := c.Query("unit")
+		if unit == "" {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "unit parameter is required for mode=unit"})
+			return
+		}
+		args = append(args, unit)
+		fromClause += " AND m.unit = $3" + notRetired
+	default:
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid mode parameter"})
+		return
+	}
+
+	if orderBy == "" {
+		if order == "random" {
+			if seed := c.Query("seed"); seed != "" {
+				// A seeded hash gives a random but repeatable order, so pages don't overlap.
+				args = append(args, seed)
+				orderBy = fmt.Sprintf(" ORDER BY md5(m.id::text || $%d), m.id", len(args))
+			} else {
+				orderBy = " ORDER BY random()"
+			}
+		} else {
+			orderBy = " ORDER BY m.id" // or w.lemma, depending on desired sequential order
+		}
+	}
 
-	if order == "random" {
-		query += " ORDER BY random()"
-	} else {
-		query += " ORDER BY m.id" // or w.lemma, depending on desired sequential order
+	var total int
+	if err := a.DB.QueryRow(c.Request.Context(), "SELECT COUNT(*)"+fromClause, args...).Scan(&total); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to count words for selection"})
+		return
 	}
 
-	query += " LIMIT $2"
+	query := `SELECT m.id, w.lemma, m.definition, m.unit, up.next_review_at, up.memory_halflife, up.card_state, up.buried_until` + fromClause + orderBy +
+		fmt.Sprintf(" LIMIT $%d OFFSET $%d", len(args)+1, len(args)+2)
+	args = append(args, count, offset)
 
-	rows, err := a.DB.Query(c.Request.Context(), query, source, count)
+	rows, err := a.DB.Query(c.Request.Context(), query, args...)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to query words for selection"})
 		return
@@ -317,18 +423,26 @@ func (a *API) GetWordsForSelection(c *gin.Context) {
 	defer rows.Close()
 
 	type WordForSelection struct {
-		ID         int    `json:"id"`
-		Lemma      string `json:"lemma"`
-		Definition string `json:"definition"`
+		ID             int        `json:"id"`
+		Lemma          string     `json:"lemma"`
+		Definition     string     `json:"definition"`
+		Unit           *string    `json:"unit,omitempty"`
+		NextReviewAt   *time.Time `json:"nextReviewAt,omitempty"`
+		MemoryHalfLife *float64   `json:"memoryHalfLife,omitempty"`
+		CardState      string     `json:"cardState"`
 	}
 
-	var words []WordForSelection
+	words := []WordForSelection{}
 	for rows.Next() {
 		var word WordForSelection
-		if err := rows.Scan(&word.ID, &word.Lemma, &word.Definition); err != nil {
+		var cardState *string
+		var buriedUntil *time.Time
+		if err := rows.Scan(&word.ID, &word.Lemma, &word.Definition, &word.Unit, &word.NextReviewAt, &word.MemoryHalfLife,
+			&cardState, &buriedUntil); err != nil {
 			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to scan word for selection"})
 			return
 		}
+		word.CardState = srs.EffectiveCardState(cardState, buriedUntil)
 		words = append(words, word)
 	}
 
@@ -337,6 +451,7 @@ func (a *API) GetWordsForSelection(c *gin.Context) {
 		return
 	}
 
+	c.Header("X-Total-Count", strconv.Itoa(total))
 	c.JSON(http.StatusOK, words)
 }
 
@@ -362,6 +477,12 @@ func (a *API) CreateDailyPlan(c *gin.Context) {
 		return
 	}
 
+	// Reject empty, oversized or unknown meaning lists up front; an empty plan would count as completed.
+	meaningIDs, ok := validateMeaningIDs(c, a.DB, req.MeaningIDs, maxDailyPlanSize)
+	if !ok {
+		return
+	}
+
 	tx, err := a.DB.Begin(c.Request.Context())
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to start transaction"})
@@ -380,14 +501,12 @@ func (a *API) CreateDailyPlan(c *gin.Context) {
 	}
 
 	// Insert into the join table
-	for _, meaningID := range req.MeaningIDs {
-		_, err := tx.Exec(c.Request.Context(),
-			`INSERT INTO daily_plan_words (plan_id, meaning_id) VALUES ($1, $2)`,
-			planID, meaningID)
-		if err != nil {
-			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to add words to daily plan"})
-			return
-		}
+	_, err = tx.Exec(c.Request.Context(),
+		`INSERT INTO daily_plan_words (plan_id, meaning_id) SELECT $1, unnest($2::int[])`,
+		planID, meaningIDs)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to add words to daily plan"})
+		return
 	}
 
 	if err := tx.Commit(c.Request.Context()); err != nil {
@@ -449,49 +568,20 @@ func (a *API) GetLearningProgress(c *gin.Context) {
 		return
 	}
 
-	// 获取用户今日学习计划中的单词总数
-	query := `
-		WITH latest_plan AS (
-			SELECT id FROM daily_plans
-			WHERE user_id = $1 AND created_at >= current_date
-			ORDER BY created_at DESC
-			LIMIT 1
-		)
-		SELECT COUNT(*) AS total_words
-		FROM daily_plan_words
-		WHERE plan_id = (SELECT id FROM latest_plan)
-	`
-
-	var totalWords int
-	err := a.DB.QueryRow(c.Request.Context(), query, userID).Scan(&totalWords)
+	day, err := srs.GetStudyDay(c.Request.Context(), a.DB, userID)
 	if err != nil {
-		if errors.Is(err, pgx.ErrNoRows) {
-			c.JSON(http.StatusOK, gin.H{"total": 0, "completed": 0})
-			return
-		}
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get learning plan"})
 		return
 	}
 
-	// 获取今日已完成的单词数量
-	completedQuery := `
-		WITH latest_plan AS (
-			SELECT id FROM daily_plans
-			WHERE user_id = $1 AND created_at >= current_date
-			ORDER BY created_at DESC
-			LIMIT 1
-		)
-		SELECT COUNT(*) AS completed_words
-		FROM daily_plan_words dpw
-		JOIN user_progress up ON dpw.meaning_id = up.meaning_id AND up.user_id = $1
-		WHERE dpw.plan_id = (SELECT id FROM latest_plan)
-		  AND up.last_reviewed_at >= current_date
-	`
+	if err := srs.EnsureDailyPlans(c.Request.Context(), a.DB, userID, day); err != nil {
+		log.Printf("Error generating daily plans for user %s: %v", userID, err)
+	}
 
-	var completedWords int
-	err = a.DB.QueryRow(c.Request.Context(), completedQuery, userID).Scan(&completedWords)
+	// 获取用户今日学习计划中的单词总数和已完成数量
+	completedWords, totalWords, err := srs.GetDailyPlanProgress(c.Request.Context(), a.DB, userID, day)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get completed words count"})
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get learning plan"})
 		return
 	}
 
@@ -520,10 +610,10 @@ func (a *API) GetUserStats(c *gin.Context) {
 		return
 	}
 
-	// 获取用户已学习过的单词数量（至少点击过一次按钮）
+	// 获取用户已学习过的单词数量（至少点击过一次按钮）；按单词模式下按单词计数
 	var learnedWordsCount int
 	countQuery := `
-		SELECT COUNT(DISTINCT meaning_id) 
+		SELECT COUNT(DISTINCT tracked_meaning_id($1, meaning_id))
 		FROM user_progress 
 		WHERE user_id = $1
 	`
@@ -539,12 +629,17 @@ func (a *API) GetUserStats(c *gin.Context) {
 	})
 }
 
-// GetSRSAlgorithmInfo 返回当前使用的SRS算法信息
+// GetSRSAlgorithmInfo 返回当前用户使用的SRS算法信息
 func (a *API) GetSRSAlgorithmInfo(c *gin.Context) {
 	ctx := c.Request.Context()
+	userID, ok := getUserID(c)
+	if !ok {
+		return
+	}
 
-	// 获取当前使用的SRS算法
-	algorithm, err := srs.GetSRSAlgorithm(ctx, a.DB)
+	// 获取当前使用的SRS算法（进行中的实验可能为用户指定算法）
+	scheduler, err := srs.GetUserScheduler(ctx, a.DB, userID)
+	algorithm := scheduler.Algorithm
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get SRS algorithm info"})
 		return
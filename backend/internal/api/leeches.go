@@ -0,0 +1,78 @@
+package api
+
+import (
+	"errors"
+	"log"
+	"net/http"
+
+	"sentencease/backend/internal/database"
+	"sentencease/backend/internal/models"
+	"sentencease/backend/internal/srs"
+
+	"github.com/gin-gonic/gin"
+)
+
+// GetLeeches lists the meanings the user keeps forgetting.
+func (a *API) GetLeeches(c *gin.Context) {
+	userID, ok := getUserID(c)
+	if !ok {
+		return
+	}
+
+	leeches, err := srs.ListLeeches(c.Request.Context(), a.DB, userID)
+	if err != nil {
+		log.Printf("GetLeeches: Error listing leeches for user %s: %v", userID, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to list leeches"})
+		return
+	}
+
+	c.JSON(http.StatusOK, leeches)
+}
+
+// GetNextLeechRemediation returns the next leech as a cloze sentence with a definition quiz.
+func (a *API) GetNextLeechRemediation(c *gin.Context) {
+	userID, ok := getUserID(c)
+	if !ok {
+		return
+	}
+
+	card, err := srs.NextLeechRemediation(c.Request.Context(), a.DB, userID)
+	if err != nil {
+		if errors.Is(err, database.ErrNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"message": "No leeches to practise"})
+			return
+		}
+		log.Printf("GetNextLeechRemediation: Error building remediation card for user %s: %v", userID, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get remediation card"})
+		return
+	}
+
+	c.JSON(http.StatusOK, card)
+}
+
+// AnswerLeechRemediation checks the answer to the user's current remediation quiz.
+func (a *API) AnswerLeechRemediation(c *gin.Context) {
+	userID, ok := getUserID(c)
+	if !ok {
+		return
+	}
+
+	var req models.LeechAnswerRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body: " + err.Error()})
+		return
+	}
+
+	result, err := srs.AnswerLeechRemediation(c.Request.Context(), a.DB, userID, req.QuizID, *req.Choice)
+	if err != nil {
+		if errors.Is(err, database.ErrNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Quiz not found or already answered"})
+			return
+		}
+		log.Printf("AnswerLeechRemediation: Error recording answer for user %s on quiz %s: %v", userID, req.QuizID, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to record answer"})
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
@@ -1,11 +1,15 @@
 package api
 
 import (
+	"errors"
 	"net/http"
 	"sentencease/backend/internal/auth"
 	"strings"
 
 	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
 )
 
 func AuthMiddleware(secretKey string) gin.HandlerFunc {
@@ -33,3 +37,46 @@ func AuthMiddleware(secretKey string) gin.HandlerFunc {
 		c.Next()
 	}
 }
+
+// AdminMiddleware only lets through users flagged as administrators. It must run after AuthMiddleware.
+func AdminMiddleware(db *pgxpool.Pool) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		userID, ok := getUserID(c)
+		if !ok {
+			c.Abort()
+			return
+		}
+
+		var isAdmin bool
+		err := db.QueryRow(c.Request.Context(), `SELECT is_admin FROM users WHERE id = $1`, userID).Scan(&isAdmin)
+		if err != nil && !errors.Is(err, pgx.ErrNoRows) {
+			c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": "Failed to check permissions"})
+			return
+		}
+		if !isAdmin {
+			c.AbortWithStatusJSON(http.StatusForbidden, gin.H{"error": "Administrator access required"})
+			return
+		}
+
+		c.Set("isAdmin", true)
+		c.Next()
+	}
+}
+
+// getUserID returns the ID of the authenticated user set by AuthMiddleware.
+// If it is missing or malformed, an error response is written and ok is false.
+func getUserID(c *gin.Context) (uuid.UUID, bool) {
+	userIDClaim, exists := c.Get("userID")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User ID not found in token"})
+		return uuid.Nil, false
+	}
+
+	userID, ok := userIDClaim.(uuid.UUID)
+	if !ok {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Invalid user ID format in token"})
+		return uuid.Nil, false
+	}
+
+	return userID, true
+}
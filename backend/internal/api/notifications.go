@@ -0,0 +1,280 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"net/mail"
+	"strconv"
+	"strings"
+
+	"sentencease/backend/internal/models"
+	"sentencease/backend/internal/notify"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+// maxNotificationChannels is the largest number of reminder channels a user may register.
+const maxNotificationChannels = 10
+
+// GetNotificationSettings returns the user's reminder preferences. Users who never saved any get
+// the defaults: reminders off, at 19:00.
+func (a *API) GetNotificationSettings(c *gin.Context) {
+	userID, ok := getUserID(c)
+	if !ok {
+		return
+	}
+
+	settings, err := a.notificationSettings(c.Request.Context(), userID)
+	if err != nil {
+		log.Printf("GetNotificationSettings: Error loading settings for user %s: %v", userID, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get notification settings"})
+		return
+	}
+
+	c.JSON(http.StatusOK, settings)
+}
+
+func (a *API) notificationSettings(ctx context.Context, userID uuid.UUID) (*models.NotificationSettings, error) {
+	settings := &models.NotificationSettings{ReminderTime: "19:00"}
+	err := a.DB.QueryRow(ctx, `
+		SELECT reminders_enabled, to_char(reminder_time, 'HH24:MI'),
+		       to_char(quiet_hours_start, 'HH24:MI'), to_char(quiet_hours_end, 'HH24:MI')
+		FROM notification_settings WHERE user_id = $1
+	`, userID).Scan(&settings.RemindersEnabled, &settings.ReminderTime, &settings.QuietHoursStart, &settings.QuietHoursEnd)
+	if err != nil && !errors.Is(err, pgx.ErrNoRows) {
+		return nil, err
+	}
+	return settings, nil
+}
+
+// UpdateNotificationSettings replaces the user's reminder preferences.
+func (a *API) UpdateNotificationSettings(c *gin.Context) {
+	userID, ok := getUserID(c)
+	if !ok {
+		return
+	}
+
+	var req models.UpdateNotificationSettingsRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body: " + err.Error()})
+		return
+	}
+	if (req.QuietHoursStart == nil) != (req.QuietHoursEnd == nil) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "quietHoursStart and quietHoursEnd must be set together"})
+		return
+	}
+
+	_, err := a.DB.Exec(c.Request.Context(), `
+		INSERT INTO notification_settings (user_id, reminders_enabled, reminder_time, quiet_hours_start, quiet_hours_end)
+		VALUES ($1, $2, $3::time, $4::time, $5::time)
+		ON CONFLICT (user_id) DO UPDATE SET
+			reminders_enabled = EXCLUDED.reminders_enabled,
+			reminder_time = EXCLUDED.reminder_time,
+			quiet_hours_start = EXCLUDED.quiet_hours_start,
+			quiet_hours_end = EXCLUDED.quiet_hours_end,
+			updated_at = now()
+	`, userID, req.RemindersEnabled, req.ReminderTime, req.QuietHoursStart, req.QuietHoursEnd)
+	if err != nil {
+		log.Printf("UpdateNotificationSettings: Error saving settings for user %s: %v", userID, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update notification settings"})
+		return
+	}
+
+	c.JSON(http.StatusOK, models.NotificationSettings{
+		RemindersEnabled: req.RemindersEnabled,
+		ReminderTime:     req.ReminderTime,
+		QuietHoursStart:  req.QuietHoursStart,
+		QuietHoursEnd:    req.QuietHoursEnd,
+	})
+}
+
+// ListNotificationChannels returns the user's reminder channels, oldest first.
+func (a *API) ListNotificationChannels(c *gin.Context) {
+	userID, ok := getUserID(c)
+	if !ok {
+		return
+	}
+
+	channels, err := a.listNotificationChannels(c.Request.Context(), userID)
+	if err != nil {
+		log.Printf("ListNotificationChannels: Error listing channels for user %s: %v", userID, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to list notification channels"})
+		return
+	}
+
+	c.JSON(http.StatusOK, channels)
+}
+
+func (a *API) listNotificationChannels(ctx context.Context, userID uuid.UUID) ([]models.NotificationChannel, error) {
+	rows, err := a.DB.Query(ctx,
+		`SELECT id, type, target, created_at FROM notification_channels WHERE user_id = $1 ORDER BY created_at`, userID)
+	if err != nil {
+		return nil, err
+	}
+	return pgx.CollectRows(rows, func(row pgx.CollectableRow) (models.NotificationChannel, error) {
+		var ch models.NotificationChannel
+		err := row.Scan(&ch.ID, &ch.Type, &ch.Target, &ch.CreatedAt)
+		return ch, err
+	})
+}
+
+// CreateNotificationChannel registers an email address, webhook URL or Web Push subscription for
+// the user's reminders. Email reminders go only to the account's own address, so the worker can
+// never be used to mail third parties.
+func (a *API) CreateNotificationChannel(c *gin.Context) {
+	userID, ok := getUserID(c)
+	if !ok {
+		return
+	}
+
+	var req models.CreateNotificationChannelRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body: " + err.Error()})
+		return
+	}
+	target := notify.Target{Address: req.Target}
+	if req.Secret != nil {
+		target.Secret = *req.Secret
+	}
+	if req.P256dh != nil {
+		target.P256dh = *req.P256dh
+	}
+	if req.Auth != nil {
+		target.Auth = *req.Auth
+	}
+	if err := notify.ValidateTarget(c.Request.Context(), req.Type, target); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	var email string
+	var channels int
+	err := a.DB.QueryRow(c.Request.Context(), `
+		SELECT email, (SELECT COUNT(*) FROM notification_channels WHERE user_id = $1)
+		FROM users WHERE id = $1`, userID).Scan(&email, &channels)
+	if err != nil {
+		log.Printf("CreateNotificationChannel: Error loading user %s: %v", userID, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create notification channel"})
+		return
+	}
+	if channels >= maxNotificationChannels {
+		c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("At most %d notification channels are allowed", maxNotificationChannels)})
+		return
+	}
+	if req.Type == models.ChannelEmail {
+		// ValidateTarget has already checked that the address parses.
+		addr, _ := mail.ParseAddress(req.Target)
+		if !strings.EqualFold(addr.Address, email) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Reminder emails can only be sent to your account's email address"})
+			return
+		}
+		req.Target = email
+	}
+
+	ch := models.NotificationChannel{Type: req.Type, Target: req.Target}
+	err = a.DB.QueryRow(c.Request.Context(), `
+		INSERT INTO notification_channels (user_id, type, target, secret, p256dh, auth)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		RETURNING id, created_at
+	`, userID, req.Type, req.Target, req.Secret, req.P256dh, req.Auth).Scan(&ch.ID, &ch.CreatedAt)
+	if err != nil {
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgErr.Code == "23505" {
+			c.JSON(http.StatusConflict, gin.H{"error": "This notification channel already exists"})
+			return
+		}
+		log.Printf("CreateNotificationChannel: Error creating channel for user %s: %v", userID, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create notification channel"})
+		return
+	}
+
+	c.JSON(http.StatusCreated, ch)
+}
+
+// DeleteNotificationChannel removes one of the user's reminder channels.
+func (a *API) DeleteNotificationChannel(c *gin.Context) {
+	userID, ok := getUserID(c)
+	if !ok {
+		return
+	}
+
+	channelID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid channel ID"})
+		return
+	}
+
+	tag, err := a.DB.Exec(c.Request.Context(),
+		`DELETE FROM notification_channels WHERE id = $1 AND user_id = $2`, channelID, userID)
+	if err != nil {
+		log.Printf("DeleteNotificationChannel: Error deleting channel %s: %v", channelID, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete notification channel"})
+		return
+	}
+	if tag.RowsAffected() == 0 {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Notification channel not found"})
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// ListNotificationLog returns the user's most recent reminder deliveries.
+func (a *API) ListNotificationLog(c *gin.Context) {
+	userID, ok := getUserID(c)
+	if !ok {
+		return
+	}
+
+	limit := 30
+	if limitStr := c.Query("limit"); limitStr != "" {
+		n, err := strconv.Atoi(limitStr)
+		if err != nil || n < 1 || n > 100 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "limit must be between 1 and 100"})
+			return
+		}
+		limit = n
+	}
+
+	entries, err := a.notificationLog(c.Request.Context(), userID, limit)
+	if err != nil {
+		log.Printf("ListNotificationLog: Error listing log for user %s: %v", userID, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to list notification log"})
+		return
+	}
+
+	c.JSON(http.StatusOK, entries)
+}
+
+// notificationLog returns the user's latest log entries; limit 0 returns all of them.
+func (a *API) notificationLog(ctx context.Context, userID uuid.UUID, limit int) ([]models.NotificationLogEntry, error) {
+	rows, err := a.DB.Query(ctx, `
+		SELECT id, channel_id, channel_type, to_char(study_date, 'YYYY-MM-DD'), due_reviews, new_words, status, error, created_at
+		FROM notification_log WHERE user_id = $1
+		ORDER BY created_at DESC, id DESC
+		LIMIT NULLIF($2, 0)
+	`, userID, limit)
+	if err != nil {
+		return nil, err
+	}
+	return pgx.CollectRows(rows, func(row pgx.CollectableRow) (models.NotificationLogEntry, error) {
+		var e models.NotificationLogEntry
+		err := row.Scan(&e.ID, &e.ChannelID, &e.ChannelType, &e.StudyDate, &e.DueReviews, &e.NewWords, &e.Status, &e.Error, &e.CreatedAt)
+		return e, err
+	})
+}
+
+// GetVAPIDPublicKey returns the application server key browsers need to create a push subscription.
+func (a *API) GetVAPIDPublicKey(c *gin.Context) {
+	if a.VAPIDPublicKey == "" {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Web Push is not configured"})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"publicKey": a.VAPIDPublicKey})
+}
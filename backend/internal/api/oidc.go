@@ -0,0 +1,196 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"log"
+	"net/http"
+	"net/url"
+
+	"sentencease/backend/internal/auth"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+)
+
+const oidcFlowCookie = "oidc_flow"
+
+var (
+	errOIDCEmailMissing    = errors.New("the identity provider did not return an email address")
+	errOIDCEmailUnverified = errors.New("an account with this email already exists and the provider has not verified the email")
+)
+
+// OIDCLogin starts the authorization code + PKCE flow by redirecting to the identity provider.
+func (a *API) OIDCLogin(c *gin.Context) {
+	if a.OIDC == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": auth.ErrOIDCDisabled.Error()})
+		return
+	}
+
+	state, err := auth.RandomToken(24)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to start login"})
+		return
+	}
+	nonce, err := auth.RandomToken(24)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to start login"})
+		return
+	}
+	verifier, challenge, err := auth.GeneratePKCE()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to start login"})
+		return
+	}
+
+	authURL, err := a.OIDC.AuthCodeURL(c.Request.Context(), state, nonce, challenge)
+	if err != nil {
+		log.Printf("OIDCLogin: %v", err)
+		c.JSON(http.StatusBadGateway, gin.H{"error": "Identity provider is unavailable"})
+		return
+	}
+
+	flowToken, err := auth.GenerateOIDCFlowToken(state, nonce, verifier, a.JWTSecretKey)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to start login"})
+		return
+	}
+
+	c.SetSameSite(http.SameSiteLaxMode)
+	c.SetCookie(oidcFlowCookie, flowToken, 600, "/", "", c.Request.TLS != nil, true)
+	c.Redirect(http.StatusFound, authURL)
+}
+
+// OIDCCallback completes the login: it verifies the state, exchanges the code, validates the
+// ID token, links the identity to a local user and issues the same JWT as the password login.
+func (a *API) OIDCCallback(c *gin.Context) {
+	if a.OIDC == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": auth.ErrOIDCDisabled.Error()})
+		return
+	}
+
+	if providerErr := c.Query("error"); providerErr != "" {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Login was rejected by the identity provider: " + providerErr})
+		return
+	}
+
+	flowToken, err := c.Cookie(oidcFlowCookie)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Login session not found or expired"})
+		return
+	}
+	// The flow cookie is single use.
+	c.SetCookie(oidcFlowCookie, "", -1, "/", "", c.Request.TLS != nil, true)
+
+	flow, err := auth.ValidateOIDCFlowToken(flowToken, a.JWTSecretKey)
+	if err != nil || flow.State != c.Query("state") {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid login state"})
+		return
+	}
+
+	code := c.Query("code")
+	if code == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Authorization code is missing"})
+		return
+	}
+
+	ctx := c.Request.Context()
+	tokens, err := a.OIDC.Exchange(ctx, code, flow.CodeVerifier)
+	if err != nil {
+		log.Printf("OIDCCallback: %v", err)
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Failed to exchange authorization code"})
+		return
+	}
+
+	claims, err := a.OIDC.VerifyIDToken(ctx, tokens.IDToken, flow.Nonce)
+	if err != nil {
+		log.Printf("OIDCCallback: %v", err)
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid ID token"})
+		return
+	}
+
+	userID, err := a.linkOIDCIdentity(ctx, a.OIDC.Config.Name, claims)
+	if err != nil {
+		if errors.Is(err, errOIDCEmailMissing) || errors.Is(err, errOIDCEmailUnverified) {
+			c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+			return
+		}
+		log.Printf("OIDCCallback: Failed to link identity %s/%s: %v", a.OIDC.Config.Name, claims.Subject, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to sign in"})
+		return
+	}
+
+	token, err := auth.GenerateJWT(userID, a.JWTSecretKey)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to generate token"})
+		return
+	}
+
+	if a.OIDCPostLoginRedirect != "" {
+		// Pass the token in the fragment so it never reaches server logs.
+		c.Redirect(http.StatusFound, a.OIDCPostLoginRedirect+"#token="+url.QueryEscape(token))
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"token": token})
+}
+
+// linkOIDCIdentity resolves the local user for an external identity, creating the link
+// (and, for first-time visitors, the user) as needed.
+func (a *API) linkOIDCIdentity(ctx context.Context, provider string, claims *auth.IDTokenClaims) (uuid.UUID, error) {
+	tx, err := a.DB.Begin(ctx)
+	if err != nil {
+		return uuid.Nil, err
+	}
+	defer tx.Rollback(ctx)
+
+	var userID uuid.UUID
+	err = tx.QueryRow(ctx,
+		`UPDATE user_identities SET last_login_at = now(), email = COALESCE(NULLIF($3, ''), email)
+		 WHERE provider = $1 AND subject = $2
+		 RETURNING user_id`,
+		provider, claims.Subject, claims.Email).Scan(&userID)
+	if err == nil {
+		return userID, tx.Commit(ctx)
+	}
+	if !errors.Is(err, pgx.ErrNoRows) {
+		return uuid.Nil, err
+	}
+
+	if claims.Email == "" {
+		return uuid.Nil, errOIDCEmailMissing
+	}
+
+	// Attach the identity to an existing account with the same email, but only if the
+	// provider vouches for the address; otherwise anyone could claim someone else's account.
+	err = tx.QueryRow(ctx, `SELECT id FROM users WHERE lower(email) = lower($1)`, claims.Email).Scan(&userID)
+	switch {
+	case err == nil:
+		if !claims.EmailVerified {
+			return uuid.Nil, errOIDCEmailUnverified
+		}
+	case errors.Is(err, pgx.ErrNoRows):
+		err = tx.QueryRow(ctx,
+			`INSERT INTO users (email, password_hash) VALUES ($1, NULL) RETURNING id`,
+			claims.Email).Scan(&userID)
+		if err != nil {
+			return uuid.Nil, err
+		}
+		if err := enqueueUserRegistered(ctx, tx, userID, claims.Email, provider); err != nil {
+			return uuid.Nil, err
+		}
+	default:
+		return uuid.Nil, err
+	}
+
+	_, err = tx.Exec(ctx,
+		`INSERT INTO user_identities (user_id, provider, subject, email, last_login_at)
+		 VALUES ($1, $2, $3, $4, now())`,
+		userID, provider, claims.Subject, claims.Email)
+	if err != nil {
+		return uuid.Nil, err
+	}
+
+	return userID, tx.Commit(ctx)
+}
@@ -0,0 +1,134 @@
+package api
+
+import (
+	"errors"
+	"log"
+	"net/http"
+
+	"sentencease/backend/internal/database"
+	"sentencease/backend/internal/models"
+	"sentencease/backend/internal/srs"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// StartPlacementTest begins an adaptive vocabulary placement test and returns the first question.
+func (a *API) StartPlacementTest(c *gin.Context) {
+	userID, ok := getUserID(c)
+	if !ok {
+		return
+	}
+
+	var req models.StartPlacementTestRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body: " + err.Error()})
+		return
+	}
+
+	test, question, err := srs.StartPlacementTest(c.Request.Context(), a.DB, userID, req.Source, req.MaxQuestions)
+	if err != nil {
+		if errors.Is(err, database.ErrNotFound) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Vocabulary source has no words: " + req.Source})
+			return
+		}
+		log.Printf("StartPlacementTest: Error starting placement test for user %s: %v", userID, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to start placement test"})
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{"test": test, "question": question})
+}
+
+// GetPlacementTest returns the state of a placement test, including the estimate once finished.
+func (a *API) GetPlacementTest(c *gin.Context) {
+	userID, ok := getUserID(c)
+	if !ok {
+		return
+	}
+
+	testID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid placement test ID"})
+		return
+	}
+
+	test, err := srs.GetPlacementTest(c.Request.Context(), a.DB, userID, testID)
+	if err != nil {
+		if errors.Is(err, database.ErrNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Placement test not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get placement test"})
+		return
+	}
+
+	c.JSON(http.StatusOK, test)
+}
+
+// AnswerPlacementQuestion records whether the user knows the current word and returns the next
+// question. When the test finishes, "question" is null and the test carries the estimate.
+func (a *API) AnswerPlacementQuestion(c *gin.Context) {
+	userID, ok := getUserID(c)
+	if !ok {
+		return
+	}
+
+	testID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid placement test ID"})
+		return
+	}
+
+	var req models.PlacementAnswerRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body: " + err.Error()})
+		return
+	}
+
+	test, question, err := srs.AnswerPlacementQuestion(c.Request.Context(), a.DB, userID, testID, req.WordID, *req.Known)
+	if err != nil {
+		switch {
+		case errors.Is(err, database.ErrNotFound):
+			c.JSON(http.StatusNotFound, gin.H{"error": "Placement test not found"})
+		case errors.Is(err, srs.ErrPlacementFinished), errors.Is(err, srs.ErrPlacementWrongWord):
+			c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+		default:
+			log.Printf("AnswerPlacementQuestion: Error answering placement test %s: %v", testID, err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to record answer"})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"test": test, "question": question})
+}
+
+// ApplyPlacementTest marks the words the test found to be known as learned, with long intervals.
+func (a *API) ApplyPlacementTest(c *gin.Context) {
+	userID, ok := getUserID(c)
+	if !ok {
+		return
+	}
+
+	testID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid placement test ID"})
+		return
+	}
+
+	added, err := srs.ApplyPlacementTest(c.Request.Context(), a.DB, userID, testID)
+	if err != nil {
+		switch {
+		case errors.Is(err, database.ErrNotFound):
+			c.JSON(http.StatusNotFound, gin.H{"error": "Placement test not found"})
+		case errors.Is(err, srs.ErrPlacementNotCompleted):
+			c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+		default:
+			log.Printf("ApplyPlacementTest: Error applying placement test %s: %v", testID, err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to apply placement test"})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Placement test applied successfully", "meaningsMarkedKnown": added})
+}
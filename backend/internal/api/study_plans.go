@@ -0,0 +1,210 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"log"
+	"net/http"
+
+	"sentencease/backend/internal/models"
+	"sentencease/backend/internal/srs"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+)
+
+// ListStudyPlans returns the user's recurring study plans with their projected completion dates.
+func (a *API) ListStudyPlans(c *gin.Context) {
+	userID, ok := getUserID(c)
+	if !ok {
+		return
+	}
+
+	ctx := c.Request.Context()
+	rows, err := a.DB.Query(ctx, `
+		SELECT id, source, words_per_day, status, created_at, completed_at
+		FROM study_plans WHERE user_id = $1
+		ORDER BY created_at DESC`, userID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to query study plans"})
+		return
+	}
+	plans, err := pgx.CollectRows(rows, scanStudyPlan)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to scan study plan"})
+		return
+	}
+
+	day, err := srs.GetStudyDay(ctx, a.DB, userID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get study day"})
+		return
+	}
+	for i := range plans {
+		if err := srs.ProjectStudyPlan(ctx, a.DB, userID, &plans[i], day); err != nil {
+			log.Printf("ListStudyPlans: Error projecting study plan %s: %v", plans[i].ID, err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to project study plan"})
+			return
+		}
+	}
+
+	c.JSON(http.StatusOK, plans)
+}
+
+// CreateStudyPlan creates a recurring plan: N new words per day from a source, in unit order.
+// The first daily plan is generated on the next learning request.
+func (a *API) CreateStudyPlan(c *gin.Context) {
+	userID, ok := getUserID(c)
+	if !ok {
+		return
+	}
+
+	var req models.CreateStudyPlanRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body: " + err.Error()})
+		return
+	}
+
+	ctx := c.Request.Context()
+	var sourceExists bool
+	err := a.DB.QueryRow(ctx, `SELECT EXISTS(SELECT 1 FROM words WHERE source = $1)`, req.Source).Scan(&sourceExists)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to validate source"})
+		return
+	}
+	if !sourceExists {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Unknown vocabulary source: " + req.Source})
+		return
+	}
+
+	var planID uuid.UUID
+	err = a.DB.QueryRow(ctx,
+		`INSERT INTO study_plans (user_id, source, words_per_day) VALUES ($1, $2, $3) RETURNING id`,
+		userID, req.Source, req.WordsPerDay).Scan(&planID)
+	if err != nil {
+		log.Printf("CreateStudyPlan: Error creating study plan for user %s: %v", userID, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create study plan"})
+		return
+	}
+
+	plan, err := a.loadStudyPlan(ctx, userID, planID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get study plan"})
+		return
+	}
+
+	c.JSON(http.StatusCreated, plan)
+}
+
+// UpdateStudyPlan changes the daily quota or pauses/resumes a study plan.
+// The new quota applies from the next generated daily plan.
+func (a *API) UpdateStudyPlan(c *gin.Context) {
+	userID, ok := getUserID(c)
+	if !ok {
+		return
+	}
+
+	planID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid study plan ID"})
+		return
+	}
+
+	var req models.UpdateStudyPlanRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body: " + err.Error()})
+		return
+	}
+
+	ctx := c.Request.Context()
+	var status string
+	err = a.DB.QueryRow(ctx, `SELECT status FROM study_plans WHERE id = $1 AND user_id = $2`, planID, userID).Scan(&status)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Study plan not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get study plan"})
+		return
+	}
+	if status == models.StudyPlanCompleted {
+		c.JSON(http.StatusConflict, gin.H{"error": "Study plan is already completed"})
+		return
+	}
+
+	_, err = a.DB.Exec(ctx, `
+		UPDATE study_plans SET
+			words_per_day = COALESCE($3, words_per_day),
+			status = COALESCE($4, status)
+		WHERE id = $1 AND user_id = $2`,
+		planID, userID, req.WordsPerDay, req.Status)
+	if err != nil {
+		log.Printf("UpdateStudyPlan: Error updating study plan %s: %v", planID, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update study plan"})
+		return
+	}
+
+	plan, err := a.loadStudyPlan(ctx, userID, planID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get study plan"})
+		return
+	}
+
+	c.JSON(http.StatusOK, plan)
+}
+
+// DeleteStudyPlan removes a study plan. Daily plans already generated from it are kept.
+func (a *API) DeleteStudyPlan(c *gin.Context) {
+	userID, ok := getUserID(c)
+	if !ok {
+		return
+	}
+
+	planID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid study plan ID"})
+		return
+	}
+
+	tag, err := a.DB.Exec(c.Request.Context(), `DELETE FROM study_plans WHERE id = $1 AND user_id = $2`, planID, userID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete study plan"})
+		return
+	}
+	if tag.RowsAffected() == 0 {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Study plan not found"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Study plan deleted successfully"})
+}
+
+// loadStudyPlan reads one of the user's study plans and computes its projection.
+func (a *API) loadStudyPlan(ctx context.Context, userID, planID uuid.UUID) (*models.StudyPlan, error) {
+	rows, err := a.DB.Query(ctx, `
+		SELECT id, source, words_per_day, status, created_at, completed_at
+		FROM study_plans WHERE id = $1 AND user_id = $2`, planID, userID)
+	if err != nil {
+		return nil, err
+	}
+	plan, err := pgx.CollectOneRow(rows, scanStudyPlan)
+	if err != nil {
+		return nil, err
+	}
+
+	day, err := srs.GetStudyDay(ctx, a.DB, userID)
+	if err != nil {
+		return nil, err
+	}
+	if err := srs.ProjectStudyPlan(ctx, a.DB, userID, &plan, day); err != nil {
+		return nil, err
+	}
+	return &plan, nil
+}
+
+func scanStudyPlan(row pgx.CollectableRow) (models.StudyPlan, error) {
+	var p models.StudyPlan
+	err := row.Scan(&p.ID, &p.Source, &p.WordsPerDay, &p.Status, &p.CreatedAt, &p.CompletedAt)
+	return p, err
+}
@@ -0,0 +1,90 @@
+package api
+
+import (
+	"errors"
+	"log"
+	"net/http"
+
+	"sentencease/backend/internal/database"
+	"sentencease/backend/internal/models"
+	"sentencease/backend/internal/srs"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// ListVacations returns the user's vacations, latest first.
+func (a *API) ListVacations(c *gin.Context) {
+	userID, ok := getUserID(c)
+	if !ok {
+		return
+	}
+
+	vacations, err := srs.ListVacations(c.Request.Context(), a.DB, userID)
+	if err != nil {
+		log.Printf("ListVacations: Error listing vacations for user %s: %v", userID, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to list vacations"})
+		return
+	}
+
+	c.JSON(http.StatusOK, vacations)
+}
+
+// CreateVacation schedules a vacation. Reviews falling in or after it are postponed by its length.
+func (a *API) CreateVacation(c *gin.Context) {
+	userID, ok := getUserID(c)
+	if !ok {
+		return
+	}
+
+	var req models.CreateVacationRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body: " + err.Error()})
+		return
+	}
+
+	vacation, err := srs.CreateVacation(c.Request.Context(), a.DB, userID, req.StartDate, req.EndDate)
+	if err != nil {
+		switch {
+		case errors.Is(err, srs.ErrVacationInPast), errors.Is(err, srs.ErrVacationInvalidRange):
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		case errors.Is(err, srs.ErrVacationOverlap):
+			c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+		default:
+			log.Printf("CreateVacation: Error creating vacation for user %s: %v", userID, err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create vacation"})
+		}
+		return
+	}
+
+	c.JSON(http.StatusCreated, vacation)
+}
+
+// CancelVacation ends a vacation early, or removes it if it has not started yet.
+func (a *API) CancelVacation(c *gin.Context) {
+	userID, ok := getUserID(c)
+	if !ok {
+		return
+	}
+
+	vacationID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid vacation ID"})
+		return
+	}
+
+	if err := srs.CancelVacation(c.Request.Context(), a.DB, userID, vacationID); err != nil {
+		switch {
+		case errors.Is(err, database.ErrNotFound):
+			c.JSON(http.StatusNotFound, gin.H{"error": "Vacation not found"})
+		case errors.Is(err, srs.ErrVacationOver):
+			c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+		default:
+			log.Printf("CancelVacation: Error cancelling vacation %s: %v", vacationID, err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to cancel vacation"})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Vacation cancelled successfully"})
+}
@@ -0,0 +1,81 @@
+package api
+
+import (
+	"context"
+	"log"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5"
+)
+
+// maxDailyPlanSize is the largest number of meanings a single daily plan may hold.
+const maxDailyPlanSize = 200
+
+// meaningIDsError is the 422 response body for requests with unusable meaning IDs.
+type meaningIDsError struct {
+	Error             string `json:"error"`
+	InvalidMeaningIDs []int  `json:"invalidMeaningIds,omitempty"`
+	MaxSize           int    `json:"maxSize,omitempty"`
+}
+
+// queryRower is satisfied by both the pool and a transaction.
+type queryRower interface {
+	QueryRow(ctx context.Context, sql string, args ...interface{}) pgx.Row
+}
+
+// findInvalidMeaningIDs returns the IDs that do not exist in the meanings table, using a single query.
+func findInvalidMeaningIDs(ctx context.Context, db queryRower, ids []int) ([]int, error) {
+	query := `
+		SELECT COALESCE(array_agg(t.id ORDER BY t.ord), '{}')
+		FROM unnest($1::int[]) WITH ORDINALITY AS t(id, ord)
+		WHERE NOT EXISTS (SELECT 1 FROM meanings m WHERE m.id = t.id)
+	`
+	var invalid []int
+	if err := db.QueryRow(ctx, query, ids).Scan(&invalid); err != nil {
+		return nil, err
+	}
+	return invalid, nil
+}
+
+// validateMeaningIDs deduplicates ids and checks that the list is non-empty, no longer than maxSize
+// (if positive) and that every meaning exists. On failure it writes a 422 response and returns false.
+func validateMeaningIDs(c *gin.Context, db queryRower, ids []int, maxSize int) ([]int, bool) {
+	ids = uniqueInts(ids)
+
+	if len(ids) == 0 {
+		c.JSON(http.StatusUnprocessableEntity, meaningIDsError{Error: "At least one meaning ID is required"})
+		return nil, false
+	}
+	if maxSize > 0 && len(ids) > maxSize {
+		c.JSON(http.StatusUnprocessableEntity, meaningIDsError{Error: "Too many meaning IDs", MaxSize: maxSize})
+		return nil, false
+	}
+
+	invalid, err := findInvalidMeaningIDs(c.Request.Context(), db, ids)
+	if err != nil {
+		log.Printf("Error validating meaning IDs: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to validate meaning IDs"})
+		return nil, false
+	}
+	if len(invalid) > 0 {
+		c.JSON(http.StatusUnprocessableEntity, meaningIDsError{Error: "Some meaning IDs do not exist", InvalidMeaningIDs: invalid})
+		return nil, false
+	}
+
+	return ids, true
+}
+
+// uniqueInts returns ids without duplicates, preserving order.
+func uniqueInts(ids []int) []int {
+	seen := make(map[int]struct{}, len(ids))
+	unique := make([]int, 0, len(ids))
+	for _, id := range ids {
+		if _, ok := seen[id]; ok {
+			continue
+		}
+		seen[id] = struct{}{}
+		unique = append(unique, id)
+	}
+	return unique
+}
@@ -0,0 +1,404 @@
+package api
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"log"
+	"net/http"
+	"slices"
+	"strconv"
+	"time"
+
+	"sentencease/backend/internal/database"
+	"sentencease/backend/internal/models"
+	"sentencease/backend/internal/outbound"
+	"sentencease/backend/internal/webhook"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+)
+
+// The same handlers serve /webhooks, where users manage webhooks receiving their own events, and
+// /admin/webhooks, where administrators manage site-wide webhooks (user_id NULL) receiving the
+// events of every user. Webhooks are matched with "user_id IS NOT DISTINCT FROM owner".
+
+// webhookOwner returns whose webhooks the request manages: the user's, or nil for the site-wide
+// webhooks when the request went through AdminMiddleware.
+func webhookOwner(c *gin.Context) (*uuid.UUID, bool) {
+	userID, ok := getUserID(c)
+	if !ok {
+		return nil, false
+	}
+	if c.GetBool("isAdmin") {
+		return nil, true
+	}
+	return &userID, true
+}
+
+// userRegisteredData is the data of a user.registered webhook event.
+type userRegisteredData struct {
+	Email  string `json:"email"`
+	Method string `json:"method"` // "password" or the name of the OIDC provider
+}
+
+// enqueueUserRegistered queues the user.registered event in the transaction creating the user.
+func enqueueUserRegistered(ctx context.Context, tx pgx.Tx, userID uuid.UUID, email, method string) error {
+	return webhook.Enqueue(ctx, tx, userID, models.WebhookUserRegistered, "",
+		userRegisteredData{Email: email, Method: method})
+}
+
+// validateWebhookEvents rejects events a user webhook cannot receive.
+func validateWebhookEvents(owner *uuid.UUID, events []string) error {
+	if owner != nil && slices.Contains(events, models.WebhookUserRegistered) {
+		return errors.New("user.registered is only available to admin webhooks")
+	}
+	return nil
+}
+
+const webhookColumns = `id, url, events, active, created_at`
+
+func scanWebhook(row pgx.Row) (models.Webhook, error) {
+	var w models.Webhook
+	err := row.Scan(&w.ID, &w.URL, &w.Events, &w.Active, &w.CreatedAt)
+	return w, err
+}
+
+func (a *API) listWebhooks(ctx context.Context, owner *uuid.UUID) ([]models.Webhook, error) {
+	rows, err := a.DB.Query(ctx,
+		`SELECT `+webhookColumns+` FROM webhooks WHERE user_id IS NOT DISTINCT FROM $1 ORDER BY created_at`, owner)
+	if err != nil {
+		return nil, err
+	}
+	return pgx.CollectRows(rows, func(row pgx.CollectableRow) (models.Webhook, error) {
+		return scanWebhook(row)
+	})
+}
+
+// webhookFromParam loads the webhook in the :id path parameter. If it is invalid or not managed by
+// owner, an error response is written and ok is false.
+func (a *API) webhookFromParam(c *gin.Context, owner *uuid.UUID) (*models.Webhook, bool) {
+	webhookID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid webhook ID"})
+		return nil, false
+	}
+	w, err := scanWebhook(a.DB.QueryRow(c.Request.Context(),
+		`SELECT `+webhookColumns+` FROM webhooks WHERE id = $1 AND user_id IS NOT DISTINCT FROM $2`, webhookID, owner))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Webhook not found"})
+			return nil, false
+		}
+		log.Printf("webhookFromParam: Error loading webhook %s: %v", webhookID, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get webhook"})
+		return nil, false
+	}
+	return &w, true
+}
+
+// ListWebhooks returns the webhooks the user manages. Secrets are not included.
+func (a *API) ListWebhooks(c *gin.Context) {
+	owner, ok := webhookOwner(c)
+	if !ok {
+		return
+	}
+
+	webhooks, err := a.listWebhooks(c.Request.Context(), owner)
+	if err != nil {
+		log.Printf("ListWebhooks: Error listing webhooks: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to list webhooks"})
+		return
+	}
+
+	c.JSON(http.StatusOK, webhooks)
+}
+
+// CreateWebhook registers a webhook. The response is the only one that includes the signing
+// secret; receivers verify the X-Signature header, which covers X-Webhook-Timestamp and the body,
+// with it.
+func (a *API) CreateWebhook(c *gin.Context) {
+	owner, ok := webhookOwner(c)
+	if !ok {
+		return
+	}
+
+	var req models.CreateWebhookRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body: " + err.Error()})
+		return
+	}
+	if err := outbound.ValidateURL(c.Request.Context(), req.URL, "http", "https"); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if err := validateWebhookEvents(owner, req.Events); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create webhook"})
+		return
+	}
+	secret := hex.EncodeToString(b)
+
+	w, err := scanWebhook(a.DB.QueryRow(c.Request.Context(), `
+		INSERT INTO webhooks (user_id, url, secret, events)
+		VALUES ($1, $2, $3, $4)
+		RETURNING `+webhookColumns, owner, req.URL, secret, slices.Compact(slices.Sorted(slices.Values(req.Events)))))
+	if err != nil {
+		log.Printf("CreateWebhook: Error creating webhook: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create webhook"})
+		return
+	}
+	w.Secret = secret
+
+	c.JSON(http.StatusCreated, w)
+}
+
+// UpdateWebhook changes a webhook's URL or events, or pauses and resumes it. Deliveries queued
+// while a webhook is paused are sent when it is resumed.
+func (a *API) UpdateWebhook(c *gin.Context) {
+	owner, ok := webhookOwner(c)
+	if !ok {
+		return
+	}
+	current, ok := a.webhookFromParam(c, owner)
+	if !ok {
+		return
+	}
+
+	var req models.UpdateWebhookRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body: " + err.Error()})
+		return
+	}
+	if req.URL != nil {
+		if err := outbound.ValidateURL(c.Request.Context(), *req.URL, "http", "https"); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+	}
+	if req.Events != nil {
+		if len(req.Events) == 0 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "events must not be empty"})
+			return
+		}
+		if err := validateWebhookEvents(owner, req.Events); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		req.Events = slices.Compact(slices.Sorted(slices.Values(req.Events)))
+	}
+
+	w, err := scanWebhook(a.DB.QueryRow(c.Request.Context(), `
+		UPDATE webhooks
+		SET url = COALESCE($2, url), events = COALESCE($3, events), active = COALESCE($4, active)
+		WHERE id = $1
+		RETURNING `+webhookColumns, current.ID, req.URL, req.Events, req.Active))
+	if err != nil {
+		log.Printf("UpdateWebhook: Error updating webhook %s: %v", current.ID, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update webhook"})
+		return
+	}
+
+	c.JSON(http.StatusOK, w)
+}
+
+// DeleteWebhook removes a webhook and its deliveries.
+func (a *API) DeleteWebhook(c *gin.Context) {
+	owner, ok := webhookOwner(c)
+	if !ok {
+		return
+	}
+	w, ok := a.webhookFromParam(c, owner)
+	if !ok {
+		return
+	}
+
+	if _, err := a.DB.Exec(c.Request.Context(), `DELETE FROM webhooks WHERE id = $1`, w.ID); err != nil {
+		log.Printf("DeleteWebhook: Error deleting webhook %s: %v", w.ID, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete webhook"})
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+const webhookDeliveryColumns = `
+	d.id, d.event_id, e.type, d.status, d.attempts,
+	CASE WHEN d.status = 'pending' THEN d.next_attempt_at END, d.last_error, d.delivered_at, d.created_at`
+
+func scanWebhookDelivery(row pgx.Row, extra ...any) (models.WebhookDelivery, error) {
+	var d models.WebhookDelivery
+	dest := append([]any{&d.ID, &d.EventID, &d.EventType, &d.Status, &d.Attempts,
+		&d.NextAttemptAt, &d.LastError, &d.DeliveredAt, &d.CreatedAt}, extra...)
+	err := row.Scan(dest...)
+	return d, err
+}
+
+// ListWebhookDeliveries returns a webhook's most recent deliveries. Query parameters: status filters
+// by pending, delivered or failed; limit defaults to 50.
+func (a *API) ListWebhookDeliveries(c *gin.Context) {
+	owner, ok := webhookOwner(c)
+	if !ok {
+		return
+	}
+	w, ok := a.webhookFromParam(c, owner)
+	if !ok {
+		return
+	}
+
+	limit := 50
+	if limitStr := c.Query("limit"); limitStr != "" {
+		n, err := strconv.Atoi(limitStr)
+		if err != nil || n < 1 || n > 200 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "limit must be between 1 and 200"})
+			return
+		}
+		limit = n
+	}
+	var status *string
+	if s := c.Query("status"); s != "" {
+		if s != models.DeliveryPending && s != models.DeliveryDelivered && s != models.DeliveryFailed {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "status must be pending, delivered or failed"})
+			return
+		}
+		status = &s
+	}
+
+	rows, err := a.DB.Query(c.Request.Context(), `
+		SELECT `+webhookDeliveryColumns+`
+		FROM webhook_deliveries d
+		JOIN webhook_events e ON e.id = d.event_id
+		WHERE d.webhook_id = $1 AND ($2::text IS NULL OR d.status = $2)
+		ORDER BY d.created_at DESC, d.id DESC
+		LIMIT $3
+	`, w.ID, status, limit)
+	if err != nil {
+		log.Printf("ListWebhookDeliveries: Error listing deliveries of webhook %s: %v", w.ID, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to list deliveries"})
+		return
+	}
+	deliveries, err := pgx.CollectRows(rows, func(row pgx.CollectableRow) (models.WebhookDelivery, error) {
+		return scanWebhookDelivery(row)
+	})
+	if err != nil {
+		log.Printf("ListWebhookDeliveries: Error listing deliveries of webhook %s: %v", w.ID, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to list deliveries"})
+		return
+	}
+
+	c.JSON(http.StatusOK, deliveries)
+}
+
+// loadWebhookDelivery loads a delivery of the webhook with its payload and attempt log.
+func (a *API) loadWebhookDelivery(ctx context.Context, webhookID uuid.UUID, deliveryID int64) (*models.WebhookDeliveryDetail, error) {
+	var detail models.WebhookDeliveryDetail
+	var payload string
+	delivery, err := scanWebhookDelivery(a.DB.QueryRow(ctx, `
+		SELECT `+webhookDeliveryColumns+`, e.payload::text
+		FROM webhook_deliveries d
+		JOIN webhook_events e ON e.id = d.event_id
+		WHERE d.id = $1 AND d.webhook_id = $2
+	`, deliveryID, webhookID), &payload)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, database.ErrNotFound
+		}
+		return nil, err
+	}
+	detail.WebhookDelivery = delivery
+	detail.Payload = []byte(payload)
+
+	rows, err := a.DB.Query(ctx, `
+		SELECT status_code, error, duration_ms, attempted_at
+		FROM webhook_delivery_attempts WHERE delivery_id = $1
+		ORDER BY attempted_at, id
+	`, deliveryID)
+	if err != nil {
+		return nil, err
+	}
+	detail.AttemptLog, err = pgx.CollectRows(rows, func(row pgx.CollectableRow) (models.WebhookDeliveryAttempt, error) {
+		var at models.WebhookDeliveryAttempt
+		err := row.Scan(&at.StatusCode, &at.Error, &at.DurationMs, &at.AttemptedAt)
+		return at, err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &detail, nil
+}
+
+// webhookDeliveryFromParams loads the delivery in the :deliveryId path parameter of the webhook in
+// :id. If either is invalid or not managed by the user, an error response is written and ok is false.
+func (a *API) webhookDeliveryFromParams(c *gin.Context) (*models.WebhookDeliveryDetail, bool) {
+	owner, ok := webhookOwner(c)
+	if !ok {
+		return nil, false
+	}
+	w, ok := a.webhookFromParam(c, owner)
+	if !ok {
+		return nil, false
+	}
+	deliveryID, err := strconv.ParseInt(c.Param("deliveryId"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid delivery ID"})
+		return nil, false
+	}
+
+	detail, err := a.loadWebhookDelivery(c.Request.Context(), w.ID, deliveryID)
+	if err != nil {
+		if errors.Is(err, database.ErrNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Delivery not found"})
+			return nil, false
+		}
+		log.Printf("webhookDeliveryFromParams: Error loading delivery %d: %v", deliveryID, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get delivery"})
+		return nil, false
+	}
+	return detail, true
+}
+
+// GetWebhookDelivery returns a delivery with the payload sent and every attempt made.
+func (a *API) GetWebhookDelivery(c *gin.Context) {
+	detail, ok := a.webhookDeliveryFromParams(c)
+	if !ok {
+		return
+	}
+	c.JSON(http.StatusOK, detail)
+}
+
+// RedeliverWebhookDelivery queues a delivered or failed delivery to be sent again as soon as
+// possible, with a fresh retry budget. Pending deliveries are already queued and may be in flight,
+// so they cannot be redelivered. The payload and event ID are unchanged, so receivers can
+// deduplicate; the timestamp and signature are new.
+func (a *API) RedeliverWebhookDelivery(c *gin.Context) {
+	detail, ok := a.webhookDeliveryFromParams(c)
+	if !ok {
+		return
+	}
+
+	tag, err := a.DB.Exec(c.Request.Context(), `
+		UPDATE webhook_deliveries
+		SET status = $2, attempts = 0, next_attempt_at = now(), delivered_at = NULL
+		WHERE id = $1 AND status <> $2
+	`, detail.ID, models.DeliveryPending)
+	if err != nil {
+		log.Printf("RedeliverWebhookDelivery: Error requeueing delivery %d: %v", detail.ID, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to redeliver"})
+		return
+	}
+	if tag.RowsAffected() == 0 {
+		c.JSON(http.StatusConflict, gin.H{"error": "Delivery is already queued"})
+		return
+	}
+
+	now := time.Now()
+	detail.Status, detail.Attempts, detail.NextAttemptAt, detail.DeliveredAt = models.DeliveryPending, 0, &now, nil
+	c.JSON(http.StatusAccepted, detail)
+}
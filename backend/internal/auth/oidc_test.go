@@ -0,0 +1,199 @@
+package auth
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+const (
+	testClientID = "sentencease-test"
+	testKeyID    = "key-1"
+	testCode     = "auth-code"
+	testNonce    = "nonce-123"
+)
+
+// mockProvider is an OIDC provider serving discovery, JWKS and a token endpoint. The token
+// endpoint checks the PKCE verifier against challenge and returns idToken.
+type mockProvider struct {
+	server    *httptest.Server
+	key       *rsa.PrivateKey
+	challenge string
+	idToken   string
+}
+
+func newMockProvider(t *testing.T) *mockProvider {
+	t.Helper()
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	m := &mockProvider{key: key}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/.well-known/openid-configuration", func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(OIDCDiscovery{
+			Issuer:                m.server.URL,
+			AuthorizationEndpoint: m.server.URL + "/authorize",
+			TokenEndpoint:         m.server.URL + "/token",
+			JWKSURI:               m.server.URL + "/jwks",
+		})
+	})
+	mux.HandleFunc("/jwks", func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(map[string]any{"keys": []jsonWebKey{{
+			Kty: "RSA",
+			Kid: testKeyID,
+			Use: "sig",
+			N:   base64.RawURLEncoding.EncodeToString(key.N.Bytes()),
+			E:   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(key.E)).Bytes()),
+		}}})
+	})
+	mux.HandleFunc("/token", func(w http.ResponseWriter, r *http.Request) {
+		if err := r.ParseForm(); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		sum := sha256.Sum256([]byte(r.PostForm.Get("code_verifier")))
+		if r.PostForm.Get("grant_type") != "authorization_code" || r.PostForm.Get("code") != testCode ||
+			base64.RawURLEncoding.EncodeToString(sum[:]) != m.challenge {
+			w.WriteHeader(http.StatusBadRequest)
+			w.Write([]byte(`{"error":"invalid_grant"}`))
+			return
+		}
+		json.NewEncoder(w).Encode(OIDCTokenResponse{AccessToken: "access", TokenType: "Bearer", IDToken: m.idToken, ExpiresIn: 3600})
+	})
+	m.server = httptest.NewServer(mux)
+	t.Cleanup(m.server.Close)
+	return m
+}
+
+func (m *mockProvider) provider() *OIDCProvider {
+	return NewOIDCProvider(OIDCConfig{
+		Name:        "mock",
+		IssuerURL:   m.server.URL,
+		ClientID:    testClientID,
+		RedirectURL: "http://localhost/callback",
+	})
+}
+
+// validClaims returns the claims of an ID token the provider would accept.
+func (m *mockProvider) validClaims() IDTokenClaims {
+	now := time.Now()
+	return IDTokenClaims{
+		Email:         "learner@example.com",
+		EmailVerified: true,
+		Nonce:         testNonce,
+		RegisteredClaims: jwt.RegisteredClaims{
+			Issuer:    m.server.URL,
+			Subject:   "subject-1",
+			Audience:  jwt.ClaimStrings{testClientID},
+			IssuedAt:  jwt.NewNumericDate(now),
+			ExpiresAt: jwt.NewNumericDate(now.Add(time.Hour)),
+		},
+	}
+}
+
+func (m *mockProvider) sign(t *testing.T, claims IDTokenClaims, kid string) string {
+	t.Helper()
+	token := jwt.NewWithClaims(jwt.SigningMethodRS256, claims)
+	token.Header["kid"] = kid
+	signed, err := token.SignedString(m.key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return signed
+}
+
+func TestOIDCExchangeAndVerify(t *testing.T) {
+	m := newMockProvider(t)
+	verifier, challenge, err := GeneratePKCE()
+	if err != nil {
+		t.Fatal(err)
+	}
+	m.challenge = challenge
+	m.idToken = m.sign(t, m.validClaims(), testKeyID)
+
+	p := m.provider()
+	ctx := context.Background()
+	token, err := p.Exchange(ctx, testCode, verifier)
+	if err != nil {
+		t.Fatalf("Exchange: %v", err)
+	}
+	claims, err := p.VerifyIDToken(ctx, token.IDToken, testNonce)
+	if err != nil {
+		t.Fatalf("VerifyIDToken: %v", err)
+	}
+	if claims.Subject != "subject-1" || claims.Email != "learner@example.com" || !claims.EmailVerified {
+		t.Errorf("unexpected claims: %+v", claims)
+	}
+}
+
+func TestOIDCExchangeRejectsBadVerifier(t *testing.T) {
+	m := newMockProvider(t)
+	_, challenge, err := GeneratePKCE()
+	if err != nil {
+		t.Fatal(err)
+	}
+	m.challenge = challenge
+	m.idToken = m.sign(t, m.validClaims(), testKeyID)
+
+	otherVerifier, _, err := GeneratePKCE()
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = m.provider().Exchange(context.Background(), testCode, otherVerifier)
+	if err == nil || !strings.Contains(err.Error(), "invalid_grant") {
+		t.Fatalf("Exchange with wrong verifier: got %v, want invalid_grant error", err)
+	}
+}
+
+func TestOIDCVerifyIDTokenRejects(t *testing.T) {
+	m := newMockProvider(t)
+
+	tests := []struct {
+		name   string
+		modify func(c *IDTokenClaims)
+		kid    string
+		nonce  string
+	}{
+		{name: "wrong nonce", nonce: "other-nonce"},
+		{name: "wrong issuer", modify: func(c *IDTokenClaims) { c.Issuer = "https://evil.example.com" }},
+		{name: "wrong audience", modify: func(c *IDTokenClaims) { c.Audience = jwt.ClaimStrings{"another-client"} }},
+		{name: "expired", modify: func(c *IDTokenClaims) {
+			c.IssuedAt = jwt.NewNumericDate(time.Now().Add(-2 * time.Hour))
+			c.ExpiresAt = jwt.NewNumericDate(time.Now().Add(-time.Hour))
+		}},
+		{name: "unknown kid", kid: "key-2"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			claims := m.validClaims()
+			if tt.modify != nil {
+				tt.modify(&claims)
+			}
+			kid := testKeyID
+			if tt.kid != "" {
+				kid = tt.kid
+			}
+			nonce := testNonce
+			if tt.nonce != "" {
+				nonce = tt.nonce
+			}
+
+			if _, err := m.provider().VerifyIDToken(context.Background(), m.sign(t, claims, kid), nonce); err == nil {
+				t.Fatal("VerifyIDToken accepted an invalid token")
+			}
+		})
+	}
+}
@@ -3,6 +3,7 @@ package config
 import (
 	"log"
 	"os"
+	"strconv"
 
 	"github.com/joho/godotenv"
 )
@@ -11,6 +12,25 @@ import (
 type Config struct {
 	DatabaseURL  string
 	JWTSecretKey string
+
+	// OIDC login is enabled only when OIDCIssuerURL is set.
+	OIDCProviderName      string
+	OIDCIssuerURL         string
+	OIDCClientID          string
+	OIDCClientSecret      string
+	OIDCRedirectURL       string
+	OIDCPostLoginRedirect string // Frontend URL that receives the token after login, optional
+
+	// Reminders. Email is enabled only when SMTPHost is set, Web Push only when the VAPID keys are set.
+	AppURL          string // Frontend URL linked from reminders, optional
+	SMTPHost        string
+	SMTPPort        int
+	SMTPUsername    string
+	SMTPPassword    string
+	SMTPFrom        string
+	VAPIDPublicKey  string
+	VAPIDPrivateKey string
+	VAPIDSubject    string // "mailto:..." contact sent to push services
 }
 
 // Load loads configuration from environment variables.
@@ -36,8 +56,44 @@ func Load() (*Config, error) {
 		log.Fatal("JWT_SECRET_KEY environment variable is not set")
 	}
 
-	return &Config{
+	cfg := &Config{
 		DatabaseURL:  dbURL,
 		JWTSecretKey: jwtSecret,
-	}, nil
+
+		OIDCProviderName:      os.Getenv("OIDC_PROVIDER_NAME"),
+		OIDCIssuerURL:         os.Getenv("OIDC_ISSUER_URL"),
+		OIDCClientID:          os.Getenv("OIDC_CLIENT_ID"),
+		OIDCClientSecret:      os.Getenv("OIDC_CLIENT_SECRET"),
+		OIDCRedirectURL:       os.Getenv("OIDC_REDIRECT_URL"),
+		OIDCPostLoginRedirect: os.Getenv("OIDC_POST_LOGIN_REDIRECT"),
+
+		AppURL:          os.Getenv("APP_URL"),
+		SMTPHost:        os.Getenv("SMTP_HOST"),
+		SMTPPort:        587,
+		SMTPUsername:    os.Getenv("SMTP_USERNAME"),
+		SMTPPassword:    os.Getenv("SMTP_PASSWORD"),
+		SMTPFrom:        os.Getenv("SMTP_FROM"),
+		VAPIDPublicKey:  os.Getenv("VAPID_PUBLIC_KEY"),
+		VAPIDPrivateKey: os.Getenv("VAPID_PRIVATE_KEY"),
+		VAPIDSubject:    os.Getenv("VAPID_SUBJECT"),
+	}
+
+	if port := os.Getenv("SMTP_PORT"); port != "" {
+		cfg.SMTPPort, err = strconv.Atoi(port)
+		if err != nil {
+			log.Fatalf("Invalid SMTP_PORT %q: %v", port, err)
+		}
+	}
+	if cfg.SMTPHost != "" && cfg.SMTPFrom == "" {
+		log.Fatal("SMTP_FROM must be set when SMTP_HOST is set")
+	}
+	if (cfg.VAPIDPublicKey == "") != (cfg.VAPIDPrivateKey == "") {
+		log.Fatal("VAPID_PUBLIC_KEY and VAPID_PRIVATE_KEY must be set together")
+	}
+
+	if cfg.OIDCIssuerURL != "" && (cfg.OIDCClientID == "" || cfg.OIDCRedirectURL == "") {
+		log.Fatal("OIDC_CLIENT_ID and OIDC_REDIRECT_URL must be set when OIDC_ISSUER_URL is set")
+	}
+
+	return cfg, nil
 }
@@ -1,6 +1,7 @@
 package models
 
 import (
+	"encoding/json"
 	"time"
 
 	"github.com/google/uuid"
@@ -16,6 +17,61 @@ type User struct {
 	PasswordHash string    `json:"-"` // Do not expose hash in JSON responses
 }
 
+// UserProfile is the editable account information returned by GET /user/profile.
+type UserProfile struct {
+	ID                 uuid.UUID `json:"id"`
+	Email              string    `json:"email"`
+	DisplayName        *string   `json:"displayName"`
+	NativeLanguage     *string   `json:"nativeLanguage"`
+	Timezone           string    `json:"timezone"`
+	DayStartHour       int       `json:"dayStartHour"`       // Local hour at which a new study day begins
+	ReviewGranularity  string    `json:"reviewGranularity"`  // GranularityMeaning or GranularityWord
+	IntervalFuzz       bool      `json:"intervalFuzz"`       // Randomly spread due dates of cards learned together
+	LoadBalancing      bool      `json:"loadBalancing"`      // Move due dates to the least loaded nearby day
+	ShowOnLeaderboards bool      `json:"showOnLeaderboards"` // Appear on group leaderboards; group teachers always see the user's progress
+	HasPassword        bool      `json:"hasPassword"`        // False for accounts created through an external identity provider
+	CreatedAt          time.Time `json:"createdAt"`
+
+	// SourceGranularity maps vocabulary sources to the granularity used for them instead of ReviewGranularity.
+	SourceGranularity map[string]string `json:"sourceGranularity"`
+}
+
+// Review granularities: whether progress is tracked per meaning or per word.
+// In word mode, the progress of a word is stored on its first meaning.
+const (
+	GranularityMeaning = "meaning"
+	GranularityWord    = "word"
+)
+
+// UpdateProfileRequest is the body of PATCH /user/profile. Omitted fields are left unchanged.
+type UpdateProfileRequest struct {
+	DisplayName        *string `json:"displayName" binding:"omitempty,max=100"`
+	NativeLanguage     *string `json:"nativeLanguage" binding:"omitempty,max=35"`
+	Timezone           *string `json:"timezone" binding:"omitempty,max=64"`
+	DayStartHour       *int    `json:"dayStartHour" binding:"omitempty,min=0,max=23"`
+	ReviewGranularity  *string `json:"reviewGranularity" binding:"omitempty,oneof=meaning word"`
+	IntervalFuzz       *bool   `json:"intervalFuzz"`
+	LoadBalancing      *bool   `json:"loadBalancing"`
+	ShowOnLeaderboards *bool   `json:"showOnLeaderboards"`
+}
+
+// SetSourceGranularityRequest is the body of PUT /vocab-sources/:source/granularity.
+type SetSourceGranularityRequest struct {
+	Granularity string `json:"granularity" binding:"required,oneof=meaning word"`
+}
+
+// ChangePasswordRequest is the body of POST /user/change-password.
+// CurrentPassword may be empty only for accounts that have no password yet.
+type ChangePasswordRequest struct {
+	CurrentPassword string `json:"currentPassword"`
+	NewPassword     string `json:"newPassword" binding:"required,min=8"`
+}
+
+// DeleteAccountRequest is the body of DELETE /user.
+type DeleteAccountRequest struct {
+	Password string `json:"password"`
+}
+
 // Word represents a word lemma.
 type Word struct {
 	ID         int     `json:"id"`
@@ -36,6 +92,15 @@ type Meaning struct {
 	Lemma                      string  `json:"lemma"` // Used for temporary association, not a DB field in meanings
 	Unit                       string  `json:"unit,omitempty"`
 	Difficulty                 float64 `json:"difficulty,omitempty"` // 用于SSP-MMC算法的单词难度
+
+	// Examples are alternative example sentences read by the seeder and stored in meaning_examples.
+	Examples []MeaningExample `json:"-"`
+}
+
+// MeaningExample is an alternative example sentence of a meaning.
+type MeaningExample struct {
+	Sentence    string
+	Translation string
 }
 
 // ReviewHistoryEntry 记录复习历史的条目
@@ -71,6 +136,712 @@ type UserProgress struct {
 type ReviewRequest struct {
 	MeaningID  int    `json:"meaningId" binding:"required"`
 	UserChoice string `json:"userChoice" binding:"required,oneof=认识 模糊 不认识"`
+	ResponseMs *int   `json:"responseMs" binding:"omitempty,min=0"` // Time taken to answer, for time-spent analytics
+}
+
+// Per-word statuses within a daily plan.
+const (
+	PlanWordNew       = "new"       // Never studied
+	PlanWordPending   = "pending"   // Studied before, not yet reviewed on the plan's day
+	PlanWordCompleted = "completed" // Reviewed on or after the plan's day
+)
+
+// DailyPlanSummary describes one daily plan without its words.
+type DailyPlanSummary struct {
+	ID             uuid.UUID  `json:"id"`
+	Date           string     `json:"date"` // Study day in the user's timezone, YYYY-MM-DD
+	CreatedAt      time.Time  `json:"createdAt"`
+	StudyPlanID    *uuid.UUID `json:"studyPlanId,omitempty"`  // Set if generated from a recurring study plan
+	AssignmentID   *uuid.UUID `json:"assignmentId,omitempty"` // Set if generated from a group assignment
+	TotalWords     int        `json:"totalWords"`
+	CompletedWords int        `json:"completedWords"`
+}
+
+// DailyPlanWord is a meaning in a daily plan together with the user's status for it.
+type DailyPlanWord struct {
+	MeaningID      int        `json:"meaningId"`
+	WordID         int        `json:"wordId"`
+	Lemma          string     `json:"lemma"`
+	PartOfSpeech   string     `json:"partOfSpeech"`
+	Definition     string     `json:"definition"`
+	Unit           *string    `json:"unit,omitempty"`
+	Status         string     `json:"status"`
+	CardState      string     `json:"cardState"`
+	LastReviewedAt *time.Time `json:"lastReviewedAt,omitempty"`
+	NextReviewAt   *time.Time `json:"nextReviewAt,omitempty"`
+}
+
+// DailyPlanDetail is a daily plan with its words, returned by GET /daily-plans/:id.
+type DailyPlanDetail struct {
+	DailyPlanSummary
+	Words []DailyPlanWord `json:"words"`
+}
+
+// UpdateDailyPlanRequest is the body of PATCH /daily-plans/:id.
+type UpdateDailyPlanRequest struct {
+	AddMeaningIDs    []int `json:"addMeaningIds"`
+	RemoveMeaningIDs []int `json:"removeMeaningIds"`
+}
+
+// Study plan statuses.
+const (
+	StudyPlanActive    = "active"
+	StudyPlanPaused    = "paused"
+	StudyPlanCompleted = "completed"
+)
+
+// StudyPlan is a recurring plan template from which a daily plan is generated every study day.
+type StudyPlan struct {
+	ID                      uuid.UUID  `json:"id"`
+	Source                  string     `json:"source"`
+	WordsPerDay             int        `json:"wordsPerDay"`
+	Status                  string     `json:"status"`
+	CreatedAt               time.Time  `json:"createdAt"`
+	CompletedAt             *time.Time `json:"completedAt,omitempty"`
+	TotalWords              int        `json:"totalWords"`                        // Meanings (words, in word mode) in the source
+	RemainingWords          int        `json:"remainingWords"`                    // Of those, not studied yet
+	ProjectedCompletionDate *string    `json:"projectedCompletionDate,omitempty"` // YYYY-MM-DD in the user's timezone
+}
+
+// CreateStudyPlanRequest is the body of POST /study-plans.
+type CreateStudyPlanRequest struct {
+	Source      string `json:"source" binding:"required"`
+	WordsPerDay int    `json:"wordsPerDay" binding:"required,min=1,max=200"`
+}
+
+// UpdateStudyPlanRequest is the body of PATCH /study-plans/:id. Omitted fields are left unchanged.
+type UpdateStudyPlanRequest struct {
+	WordsPerDay *int    `json:"wordsPerDay" binding:"omitempty,min=1,max=200"`
+	Status      *string `json:"status" binding:"omitempty,oneof=active paused"`
+}
+
+// Placement test statuses.
+const (
+	PlacementInProgress = "in_progress"
+	PlacementCompleted  = "completed"
+	PlacementApplied    = "applied" // Known words have been added to user_progress
+)
+
+// PlacementBand summarises one difficulty band of a placement test.
+// Band 1 holds the easiest words of the source.
+type PlacementBand struct {
+	Band   int     `json:"band"`
+	Words  int     `json:"words"`
+	Asked  int     `json:"asked"`
+	Known  int     `json:"known"`
+	PKnown float64 `json:"pKnown"` // Estimated share of the band's words the user knows
+}
+
+// PlacementTest is the state and result of a vocabulary placement test.
+type PlacementTest struct {
+	ID                  uuid.UUID       `json:"id"`
+	Source              string          `json:"source"`
+	Status              string          `json:"status"`
+	QuestionsAsked      int             `json:"questionsAsked"`
+	MaxQuestions        int             `json:"maxQuestions"`
+	TotalWords          int             `json:"totalWords"`
+	EstimatedVocabulary *int            `json:"estimatedVocabulary,omitempty"`
+	Bands               []PlacementBand `json:"bands,omitempty"`
+	CreatedAt           time.Time       `json:"createdAt"`
+	CompletedAt         *time.Time      `json:"completedAt,omitempty"`
+	AppliedAt           *time.Time      `json:"appliedAt,omitempty"`
+}
+
+// PlacementQuestion asks whether the user knows a word.
+type PlacementQuestion struct {
+	WordID          int    `json:"wordId"`
+	Lemma           string `json:"lemma"`
+	ExampleSentence string `json:"exampleSentence,omitempty"`
+	Band            int    `json:"band"`
+}
+
+// StartPlacementTestRequest is the body of POST /placement-tests.
+type StartPlacementTestRequest struct {
+	Source       string `json:"source" binding:"required"`
+	MaxQuestions int    `json:"maxQuestions" binding:"omitempty,min=10,max=100"`
+}
+
+// PlacementAnswerRequest is the body of POST /placement-tests/:id/answers.
+type PlacementAnswerRequest struct {
+	WordID int   `json:"wordId" binding:"required"`
+	Known  *bool `json:"known" binding:"required"`
+}
+
+// Card states of a meaning for a user.
+const (
+	CardActive    = "active"    // Scheduled normally
+	CardSuspended = "suspended" // Never shown until restored
+	CardBuried    = "buried"    // Skipped until the next study day
+	CardMastered  = "mastered"  // Known; never shown again
+)
+
+// CardState is a meaning's card state, returned by the /cards endpoints.
+type CardState struct {
+	MeaningID      int        `json:"meaningId"`
+	Lemma          string     `json:"lemma"`
+	Definition     string     `json:"definition"`
+	State          string     `json:"state"`
+	BuriedUntil    *time.Time `json:"buriedUntil,omitempty"`
+	StateChangedAt *time.Time `json:"stateChangedAt,omitempty"`
+}
+
+// SetCardStateRequest is the body of PUT /cards/:meaningId/state.
+type SetCardStateRequest struct {
+	State string `json:"state" binding:"required,oneof=active suspended buried mastered"`
+}
+
+// Leech is a meaning the user keeps forgetting, returned by GET /learn/leeches.
+type Leech struct {
+	MeaningID         int        `json:"meaningId"`
+	WordID            int        `json:"wordId"`
+	Lemma             string     `json:"lemma"`
+	Definition        string     `json:"definition"`
+	LapseCount        int        `json:"lapseCount"`
+	CardState         string     `json:"cardState"`
+	LeechedAt         *time.Time `json:"leechedAt,omitempty"`
+	RemediationStreak int        `json:"remediationStreak"` // Consecutive correct remediation answers
+}
+
+// LeechQuizChoice is one definition to choose from in a remediation quiz. Choices are answered by
+// their index; which one is correct is only known to the server.
+type LeechQuizChoice struct {
+	Definition string `json:"definition"`
+}
+
+// LeechRemediationCard presents a leech as a cloze sentence with a multiple-choice definition
+// quiz, using an alternative example sentence when one exists.
+type LeechRemediationCard struct {
+	QuizID              uuid.UUID         `json:"quizId"` // Identifies the quiz when answering; only the latest quiz can be answered
+	MeaningID           int               `json:"meaningId"`
+	Lemma               string            `json:"lemma"`
+	ClozeSentence       string            `json:"clozeSentence"` // The example sentence with the word blanked out
+	Sentence            string            `json:"sentence"`
+	SentenceTranslation *string           `json:"sentenceTranslation,omitempty"`
+	Choices             []LeechQuizChoice `json:"choices"`
+	RemediationStreak   int               `json:"remediationStreak"`
+	StreakToRecover     int               `json:"streakToRecover"` // Correct answers in a row needed to clear the leech
+}
+
+// LeechAnswerRequest is the body of POST /learn/leeches/answer.
+type LeechAnswerRequest struct {
+	QuizID uuid.UUID `json:"quizId" binding:"required"`
+	Choice *int      `json:"choice" binding:"required,min=0"` // Index into the card's choices
+}
+
+// LeechAnswerResult tells whether a remediation answer was right and whether the leech recovered.
+type LeechAnswerResult struct {
+	Correct           bool `json:"correct"`
+	CorrectChoice     int  `json:"correctChoice"` // Index of the right definition, revealed after answering
+	RemediationStreak int  `json:"remediationStreak"`
+	Recovered         bool `json:"recovered"` // The card is no longer a leech and is scheduled normally again
+}
+
+// Cram session tags.
+const (
+	CramTagLeech = "leech" // Meanings flagged as leeches
+)
+
+// CramSession is a custom study session whose reviews do not affect scheduling.
+type CramSession struct {
+	ID               uuid.UUID  `json:"id"`
+	Source           *string    `json:"source,omitempty"`
+	Unit             *string    `json:"unit,omitempty"`
+	Tag              *string    `json:"tag,omitempty"`
+	FailedWithinDays *int       `json:"failedWithinDays,omitempty"`
+	CreatedAt        time.Time  `json:"createdAt"`
+	FinishedAt       *time.Time `json:"finishedAt,omitempty"`
+	TotalCards       int        `json:"totalCards"`
+	DoneCards        int        `json:"doneCards"` // Cards answered 认识 in this session
+	Attempts         int        `json:"attempts"`  // Answers given, including repeats
+}
+
+// CreateCramSessionRequest is the body of POST /cram-sessions. At least one filter is required;
+// filters are combined with AND.
+type CreateCramSessionRequest struct {
+	Source           *string `json:"source" binding:"omitempty,max=50"`
+	Unit             *string `json:"unit" binding:"omitempty,max=255"`
+	Tag              *string `json:"tag" binding:"omitempty,oneof=leech"`
+	FailedWithinDays *int    `json:"failedWithinDays" binding:"omitempty,min=1,max=365"` // Failed a review in the last N days
+	Limit            int     `json:"limit" binding:"omitempty,min=1,max=500"`
+}
+
+// ForecastDay is the review workload of one study day, returned by GET /learn/forecast.
+type ForecastDay struct {
+	Date              string `json:"date"`              // Study day in the user's timezone, YYYY-MM-DD
+	DueReviews        int    `json:"dueReviews"`        // Cards whose next review falls on this day (today includes overdue cards)
+	ProjectedNewCards int    `json:"projectedNewCards"` // New words expected from today's plan and active study plans
+	ProjectedReviews  int    `json:"projectedReviews"`  // Simulated reviews, including those generated by earlier days' work
+	ProjectedTotal    int    `json:"projectedTotal"`    // ProjectedNewCards + ProjectedReviews
+}
+
+// Vacation is a date range during which the user's review clock is paused.
+type Vacation struct {
+	ID        uuid.UUID `json:"id"`
+	StartDate string    `json:"startDate"` // First study day of the vacation, YYYY-MM-DD
+	EndDate   string    `json:"endDate"`   // Last study day of the vacation, YYYY-MM-DD
+	StartsAt  time.Time `json:"startsAt"`
+	EndsAt    time.Time `json:"endsAt"`
+	CreatedAt time.Time `json:"createdAt"`
+}
+
+// CreateVacationRequest is the body of POST /vacations. Both dates are inclusive study days.
+type CreateVacationRequest struct {
+	StartDate string `json:"startDate" binding:"required,datetime=2006-01-02"`
+	EndDate   string `json:"endDate" binding:"required,datetime=2006-01-02"`
+}
+
+// RescheduleBacklogRequest is the body of POST /learn/reschedule-backlog.
+type RescheduleBacklogRequest struct {
+	Days int `json:"days" binding:"required,min=1,max=30"` // Spread overdue cards over this many study days, starting today
+}
+
+// BacklogDay is the number of overdue cards moved to one study day.
+type BacklogDay struct {
+	Date  string `json:"date"`
+	Cards int    `json:"cards"`
+}
+
+// BacklogReschedule is the result of POST /learn/reschedule-backlog.
+type BacklogReschedule struct {
+	Rescheduled int          `json:"rescheduled"`
+	Days        []BacklogDay `json:"days"`
+}
+
+// HeatmapDay is the number of reviews on one study day, for the calendar heatmap.
+type HeatmapDay struct {
+	Date    string `json:"date"` // Study day in the user's timezone, YYYY-MM-DD
+	Reviews int    `json:"reviews"`
+}
+
+// StudyStreak is the user's run of consecutive study days. Vacation days neither break nor extend it.
+type StudyStreak struct {
+	Current      int  `json:"current"`
+	Longest      int  `json:"longest"`
+	StudiedToday bool `json:"studiedToday"` // A current streak not yet extended today is still kept
+}
+
+// RetentionStats is the recall success rate of scheduled reviews over a period.
+type RetentionStats struct {
+	Days                  int      `json:"days"`
+	TargetRetention       float64  `json:"targetRetention"`
+	YoungReviews          int      `json:"youngReviews"`
+	YoungRetention        *float64 `json:"youngRetention"`
+	MatureReviews         int      `json:"matureReviews"`   // Reviews at least 21 days after the previous one
+	MatureRetention       *float64 `json:"matureRetention"` // True retention, to compare with the target
+	NewCards              int      `json:"newCards"`
+	AverageHalflifeGrowth *float64 `json:"averageHalflifeGrowth"` // Mean new/old half-life ratio after a success
+}
+
+// TimeSpentDay is the study time of one study day, from the response times reported by the client.
+type TimeSpentDay struct {
+	Date         string  `json:"date"`
+	Reviews      int     `json:"reviews"`
+	TimedReviews int     `json:"timedReviews"`
+	Seconds      float64 `json:"seconds"`
+}
+
+// SourceMastery breaks down the meanings of a vocabulary source by the user's progress.
+type SourceMastery struct {
+	Source    string `json:"source"`
+	Total     int    `json:"total"`
+	New       int    `json:"new"`
+	Learning  int    `json:"learning"` // Current interval under 21 days
+	Mature    int    `json:"mature"`   // Current interval of 21 days or more
+	Mastered  int    `json:"mastered"`
+	Suspended int    `json:"suspended"`
+}
+
+// CalibrationBin is one bucket of the calibration curve: reviews whose predicted recall probability
+// fell in [Low, High), with the mean prediction and the actual success rate.
+type CalibrationBin struct {
+	Low           float64  `json:"low"`
+	High          float64  `json:"high"`
+	Reviews       int      `json:"reviews"`
+	MeanPredicted *float64 `json:"meanPredicted"`
+	SuccessRate   *float64 `json:"successRate"`
+}
+
+// CalibrationGroup measures how well predicted recall probabilities match outcomes for a set of reviews.
+type CalibrationGroup struct {
+	Name          string           `json:"name"`
+	Reviews       int              `json:"reviews"`
+	MeanPredicted float64          `json:"meanPredicted"`
+	SuccessRate   float64          `json:"successRate"`
+	BrierScore    float64          `json:"brierScore"` // Mean squared error of the predictions, lower is better
+	LogLoss       float64          `json:"logLoss"`    // Mean negative log-likelihood, lower is better
+	Curve         []CalibrationBin `json:"curve"`
+}
+
+// CalibrationReport compares the memory model's recall predictions with past review outcomes.
+type CalibrationReport struct {
+	Since         *time.Time         `json:"since,omitempty"`
+	Overall       CalibrationGroup   `json:"overall"`
+	ByAlgorithm   []CalibrationGroup `json:"byAlgorithm"`
+	ByDifficulty  []CalibrationGroup `json:"byDifficulty"`
+	ByReviewCount []CalibrationGroup `json:"byReviewCount"` // Grouped by the number of earlier reviews of the card
+}
+
+// Experiment statuses.
+const (
+	ExperimentRunning = "running"
+	ExperimentStopped = "stopped"
+)
+
+// SchedulerParams override scheduling parameters for an experiment arm. Nil fields keep the defaults.
+type SchedulerParams struct {
+	TargetRetention  *float64 `json:"targetRetention,omitempty" binding:"omitempty,gte=0.5,lte=0.99"` // SSP-MMC target recall probability
+	MaxIntervalHours *float64 `json:"maxIntervalHours,omitempty" binding:"omitempty,gte=4,lte=87600"` // SSP-MMC longest interval
+	IntervalModifier *float64 `json:"intervalModifier,omitempty" binding:"omitempty,gte=0.1,lte=10"`  // Multiplies every computed interval
+}
+
+// ExperimentArm is one variant of an experiment: a scheduling algorithm with parameter overrides.
+type ExperimentArm struct {
+	ID        int             `json:"id"`
+	Name      string          `json:"name"`
+	Weight    int             `json:"weight"` // Share of users assigned to the arm, relative to the other arms
+	Algorithm string          `json:"algorithm"`
+	Params    SchedulerParams `json:"params"`
+}
+
+// Experiment is an A/B test of scheduling algorithms. Users are assigned to arms by hashing their ID.
+type Experiment struct {
+	ID          uuid.UUID       `json:"id"`
+	Name        string          `json:"name"`
+	Description *string         `json:"description,omitempty"`
+	Status      string          `json:"status"`
+	CreatedAt   time.Time       `json:"createdAt"`
+	StoppedAt   *time.Time      `json:"stoppedAt,omitempty"`
+	Arms        []ExperimentArm `json:"arms"`
+}
+
+// ExperimentArmRequest describes an arm in CreateExperimentRequest.
+type ExperimentArmRequest struct {
+	Name      string          `json:"name" binding:"required,max=50"`
+	Weight    int             `json:"weight" binding:"omitempty,min=1,max=100"` // Defaults to 1
+	Algorithm string          `json:"algorithm" binding:"required,oneof=legacy sspmmc"`
+	Params    SchedulerParams `json:"params"`
+}
+
+// CreateExperimentRequest is the body of POST /admin/experiments. The experiment starts running
+// immediately; only one experiment can run at a time.
+type CreateExperimentRequest struct {
+	Name        string                 `json:"name" binding:"required,max=100"`
+	Description *string                `json:"description"`
+	Arms        []ExperimentArmRequest `json:"arms" binding:"required,min=2,max=10,dive"`
+}
+
+// ExperimentArmReport compares the retention and workload of one arm.
+type ExperimentArmReport struct {
+	ArmID             int      `json:"armId"`
+	Name              string   `json:"name"`
+	Algorithm         string   `json:"algorithm"`
+	Users             int      `json:"users"` // Users who reviewed while assigned to the arm
+	Reviews           int      `json:"reviews"`
+	NewCards          int      `json:"newCards"`
+	Retention         *float64 `json:"retention"` // Success rate of reviews of cards studied before
+	MatureReviews     int      `json:"matureReviews"`
+	MatureRetention   *float64 `json:"matureRetention"`
+	ReviewsPerUserDay *float64 `json:"reviewsPerUserDay"` // Workload: reviews per user per day with reviews
+}
+
+// ExperimentReport is the result of GET /admin/experiments/:id/report.
+type ExperimentReport struct {
+	Experiment Experiment            `json:"experiment"`
+	Arms       []ExperimentArmReport `json:"arms"`
+}
+
+// Study group roles.
+const (
+	GroupOwner   = "owner"   // Created the group, e.g. a teacher; sees member progress
+	GroupTeacher = "teacher" // Co-teacher appointed by the owner; sets assignments and sees member progress
+	GroupMember  = "member"  // Student; receives assignments
+)
+
+// Leaderboard metrics.
+const (
+	LeaderboardReviews  = "reviews"
+	LeaderboardStreak   = "streak"
+	LeaderboardNewWords = "newWords"
+)
+
+// StudyGroup is a group of learners who share leaderboards, e.g. a class.
+type StudyGroup struct {
+	ID          uuid.UUID `json:"id"`
+	Name        string    `json:"name"`
+	Description *string   `json:"description,omitempty"`
+	OwnerID     uuid.UUID `json:"ownerId"`
+	InviteCode  *string   `json:"inviteCode,omitempty"` // Only shown to the owner
+	Role        string    `json:"role"`                 // The requesting user's role in the group
+	MemberCount int       `json:"memberCount"`
+	CreatedAt   time.Time `json:"createdAt"`
+}
+
+// CreateStudyGroupRequest is the body of POST /groups.
+type CreateStudyGroupRequest struct {
+	Name        string  `json:"name" binding:"required,max=100"`
+	Description *string `json:"description" binding:"omitempty,max=1000"`
+}
+
+// JoinStudyGroupRequest is the body of POST /groups/join.
+type JoinStudyGroupRequest struct {
+	InviteCode string `json:"inviteCode" binding:"required,max=12"`
+}
+
+// LeaderboardEntry is one member's position on a group leaderboard.
+type LeaderboardEntry struct {
+	Rank        int       `json:"rank"` // Members with equal values share a rank
+	UserID      uuid.UUID `json:"userId"`
+	DisplayName string    `json:"displayName"`
+	Value       int       `json:"value"`
+	IsYou       bool      `json:"isYou"`
+}
+
+// Leaderboard ranks the members of a study group for the current week. Members who opted out of
+// leaderboards are not listed.
+type Leaderboard struct {
+	Metric    string             `json:"metric"`
+	WeekStart string             `json:"weekStart"` // Monday of the week, in the requesting user's timezone
+	WeekEnd   string             `json:"weekEnd"`
+	Entries   []LeaderboardEntry `json:"entries"`
+}
+
+// GroupMemberProgress is one member's progress, shown to the group's teachers.
+type GroupMemberProgress struct {
+	UserID         uuid.UUID  `json:"userId"`
+	DisplayName    string     `json:"displayName"`
+	Role           string     `json:"role"`
+	JoinedAt       time.Time  `json:"joinedAt"`
+	LearnedWords   int        `json:"learnedWords"`
+	WeekReviews    int        `json:"weekReviews"`
+	WeekNewWords   int        `json:"weekNewWords"`
+	CurrentStreak  int        `json:"currentStreak"`
+	Retention      *float64   `json:"retention"` // Success rate of scheduled reviews over the last 30 days
+	LastReviewedAt *time.Time `json:"lastReviewedAt"`
+}
+
+// GroupProgress is the aggregate progress of a study group, returned by GET /groups/:id/progress.
+type GroupProgress struct {
+	WeekStart        string                `json:"weekStart"`
+	WeekEnd          string                `json:"weekEnd"`
+	ActiveMembers    int                   `json:"activeMembers"` // Members who reviewed this week
+	WeekReviews      int                   `json:"weekReviews"`
+	WeekNewWords     int                   `json:"weekNewWords"`
+	AverageRetention *float64              `json:"averageRetention"` // Mean of the members' retention rates
+	Members          []GroupMemberProgress `json:"members"`
+}
+
+// UpdateGroupMemberRequest is the body of PATCH /groups/:id/members/:userId.
+type UpdateGroupMemberRequest struct {
+	Role string `json:"role" binding:"required,oneof=teacher member"`
+}
+
+// Assignment is a set of meanings a teacher assigned to the students of a study group. Each
+// student gets a daily plan with an even share of the remaining words on every study day from
+// the start date to the due date.
+type Assignment struct {
+	ID             uuid.UUID  `json:"id"`
+	GroupID        uuid.UUID  `json:"groupId"`
+	CreatedBy      *uuid.UUID `json:"createdBy"`
+	Title          string     `json:"title"`
+	StartDate      string     `json:"startDate"` // Study dates in each student's own timezone
+	DueDate        string     `json:"dueDate"`
+	TotalWords     int        `json:"totalWords"`
+	CompletedWords *int       `json:"completedWords,omitempty"` // The requesting student's progress; not set for teachers
+	CreatedAt      time.Time  `json:"createdAt"`
+}
+
+// CreateAssignmentRequest is the body of POST /groups/:id/assignments. The meanings are given
+// either as meaningIds or as a vocabulary source with an optional unit, e.g. unit 5 of KaoYan.
+type CreateAssignmentRequest struct {
+	Title      string  `json:"title" binding:"required,max=200"`
+	MeaningIDs []int   `json:"meaningIds"`
+	Source     *string `json:"source" binding:"omitempty,max=50"`
+	Unit       *string `json:"unit" binding:"omitempty,max=255"`
+	StartDate  *string `json:"startDate" binding:"omitempty,datetime=2006-01-02"` // Defaults to today
+	DueDate    string  `json:"dueDate" binding:"required,datetime=2006-01-02"`
+}
+
+// AssignmentStudentProgress is one student's progress on an assignment.
+type AssignmentStudentProgress struct {
+	UserID         uuid.UUID  `json:"userId"`
+	DisplayName    string     `json:"displayName"`
+	CompletedWords int        `json:"completedWords"` // Assigned meanings the student has studied
+	TotalWords     int        `json:"totalWords"`
+	Completion     float64    `json:"completion"` // CompletedWords / TotalWords
+	Reviews        int        `json:"reviews"`    // Scheduled reviews of the assigned meanings
+	Retention      *float64   `json:"retention"`  // Success rate of those reviews, excluding first reviews
+	LastReviewedAt *time.Time `json:"lastReviewedAt"`
+	Overdue        bool       `json:"overdue"` // The due date has passed and the assignment is not completed
+}
+
+// AssignmentDashboard is the teacher's view of an assignment, returned by
+// GET /groups/:id/assignments/:assignmentId/dashboard.
+type AssignmentDashboard struct {
+	Assignment        Assignment                  `json:"assignment"`
+	CompletedStudents int                         `json:"completedStudents"`
+	AverageCompletion *float64                    `json:"averageCompletion"`
+	AverageRetention  *float64                    `json:"averageRetention"` // Mean of the students' retention rates
+	Students          []AssignmentStudentProgress `json:"students"`
+}
+
+// Gamification events. Each event awards XP and triggers evaluation of the achievement rules
+// listening to it.
+const (
+	EventReviewCompleted = "review_completed" // First review of a card in a study day
+	EventPlanCompleted   = "plan_completed"   // Every word of the day's plans reviewed
+	EventStreakExtended  = "streak_extended"  // First review of a study day that continues a streak
+	EventBookFinished    = "book_finished"    // Every meaning of a vocabulary source studied
+)
+
+// Achievement is an achievement with the user's progress towards it.
+type Achievement struct {
+	ID          string     `json:"id"`
+	Name        string     `json:"name"`
+	Description string     `json:"description"`
+	XP          int        `json:"xp"`
+	Goal        int        `json:"goal"`
+	Progress    int        `json:"progress"` // Capped at Goal
+	Earned      bool       `json:"earned"`
+	EarnedAt    *time.Time `json:"earnedAt,omitempty"`
+}
+
+// AchievementList is returned by GET /achievements.
+type AchievementList struct {
+	XP           int64         `json:"xp"`
+	Earned       int           `json:"earned"`
+	Achievements []Achievement `json:"achievements"` // Earned achievements first
+}
+
+// Rewards is the XP and achievements gained by an action, e.g. a review.
+type Rewards struct {
+	XP           int           `json:"xp"`
+	Achievements []Achievement `json:"achievements"`
+}
+
+// Notification channel types.
+const (
+	ChannelEmail   = "email"
+	ChannelWebhook = "webhook"
+	ChannelWebPush = "webpush"
+)
+
+// Notification delivery statuses.
+const (
+	NotificationSent   = "sent"
+	NotificationFailed = "failed"
+)
+
+// NotificationSettings are the user's reminder preferences. Times are local, HH:MM.
+type NotificationSettings struct {
+	RemindersEnabled bool    `json:"remindersEnabled"`
+	ReminderTime     string  `json:"reminderTime"`
+	QuietHoursStart  *string `json:"quietHoursStart"` // Quiet hours may wrap past midnight, e.g. 22:00-08:00
+	QuietHoursEnd    *string `json:"quietHoursEnd"`
+}
+
+// UpdateNotificationSettingsRequest is the body of PUT /notifications/settings.
+type UpdateNotificationSettingsRequest struct {
+	RemindersEnabled bool    `json:"remindersEnabled"`
+	ReminderTime     string  `json:"reminderTime" binding:"required,datetime=15:04"`
+	QuietHoursStart  *string `json:"quietHoursStart" binding:"omitempty,datetime=15:04"`
+	QuietHoursEnd    *string `json:"quietHoursEnd" binding:"omitempty,datetime=15:04"`
+}
+
+// NotificationChannel is a destination for the user's reminders. Secrets and keys are not returned.
+type NotificationChannel struct {
+	ID        uuid.UUID `json:"id"`
+	Type      string    `json:"type"`
+	Target    string    `json:"target"` // Email address, webhook URL or push subscription endpoint
+	CreatedAt time.Time `json:"createdAt"`
+}
+
+// CreateNotificationChannelRequest is the body of POST /notifications/channels. Web Push channels
+// take the endpoint and keys of the browser's PushSubscription; webhooks may have a signing secret.
+type CreateNotificationChannelRequest struct {
+	Type   string  `json:"type" binding:"required,oneof=email webhook webpush"`
+	Target string  `json:"target" binding:"required,max=2048"`
+	Secret *string `json:"secret" binding:"omitempty,max=256"`
+	P256dh *string `json:"p256dh" binding:"omitempty,max=256"`
+	Auth   *string `json:"auth" binding:"omitempty,max=64"`
+}
+
+// NotificationLogEntry records one reminder delivery attempt.
+type NotificationLogEntry struct {
+	ID          int64      `json:"id"`
+	ChannelID   *uuid.UUID `json:"channelId"` // Nil once the channel is removed
+	ChannelType string     `json:"channelType"`
+	StudyDate   string     `json:"studyDate"`
+	DueReviews  int        `json:"dueReviews"`
+	NewWords    int        `json:"newWords"`
+	Status      string     `json:"status"`
+	Error       *string    `json:"error,omitempty"`
+	CreatedAt   time.Time  `json:"createdAt"`
+}
+
+// Webhook event types.
+const (
+	WebhookReviewCreated  = "review.created"
+	WebhookPlanCompleted  = "plan.completed"
+	WebhookUserRegistered = "user.registered" // Only for admin webhooks
+)
+
+// Webhook delivery statuses.
+const (
+	DeliveryPending   = "pending"
+	DeliveryDelivered = "delivered"
+	DeliveryFailed    = "failed"
+)
+
+// Webhook is an endpoint receiving learning events. Webhooks created by users receive the user's
+// own events; webhooks created by administrators receive the events of all users.
+type Webhook struct {
+	ID        uuid.UUID `json:"id"`
+	URL       string    `json:"url"`
+	Events    []string  `json:"events"`
+	Active    bool      `json:"active"`
+	Secret    string    `json:"secret,omitempty"` // Signing secret, only returned when the webhook is created
+	CreatedAt time.Time `json:"createdAt"`
+}
+
+// CreateWebhookRequest is the body of POST /webhooks and POST /admin/webhooks.
+type CreateWebhookRequest struct {
+	URL    string   `json:"url" binding:"required,max=2048"`
+	Events []string `json:"events" binding:"required,min=1,dive,oneof=review.created plan.completed user.registered"`
+}
+
+// UpdateWebhookRequest is the body of PATCH /webhooks/:id. Omitted fields are left unchanged.
+type UpdateWebhookRequest struct {
+	URL    *string  `json:"url" binding:"omitempty,max=2048"`
+	Events []string `json:"events" binding:"omitempty,min=1,dive,oneof=review.created plan.completed user.registered"`
+	Active *bool    `json:"active"`
+}
+
+// WebhookDelivery is one event queued for one webhook.
+type WebhookDelivery struct {
+	ID            int64      `json:"id"`
+	EventID       uuid.UUID  `json:"eventId"`
+	EventType     string     `json:"eventType"`
+	Status        string     `json:"status"`
+	Attempts      int        `json:"attempts"`
+	NextAttemptAt *time.Time `json:"nextAttemptAt"` // Nil unless pending
+	LastError     *string    `json:"lastError,omitempty"`
+	DeliveredAt   *time.Time `json:"deliveredAt"`
+	CreatedAt     time.Time  `json:"createdAt"`
+}
+
+// WebhookDeliveryAttempt records one attempt to deliver an event.
+type WebhookDeliveryAttempt struct {
+	StatusCode  *int      `json:"statusCode"` // Nil when no response was received
+	Error       *string   `json:"error,omitempty"`
+	DurationMs  int       `json:"durationMs"`
+	AttemptedAt time.Time `json:"attemptedAt"`
+}
+
+// WebhookDeliveryDetail is a delivery with the payload sent and its attempt log.
+type WebhookDeliveryDetail struct {
+	WebhookDelivery
+	Payload    json.RawMessage          `json:"payload"`
+	AttemptLog []WebhookDeliveryAttempt `json:"attemptLog"`
 }
 
 // MeaningInfo represents a single, summarized meaning of a word.
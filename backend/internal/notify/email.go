@@ -0,0 +1,50 @@
+package notify
+
+import (
+	"context"
+	"fmt"
+	"mime"
+	"net"
+	"net/smtp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// SMTPNotifier sends messages as plain-text email.
+type SMTPNotifier struct {
+	Host     string
+	Port     int
+	Username string // Authentication is skipped when empty
+	Password string
+	From     string
+}
+
+// Send implements Notifier. net/smtp does not take a context, so cancellation is only checked
+// before connecting.
+func (n *SMTPNotifier) Send(ctx context.Context, target Target, msg Message) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	body := msg.Body
+	if msg.URL != "" {
+		body += "\r\n\r\n" + msg.URL
+	}
+	var b strings.Builder
+	fmt.Fprintf(&b, "From: %s\r\n", n.From)
+	fmt.Fprintf(&b, "To: %s\r\n", target.Address)
+	fmt.Fprintf(&b, "Subject: %s\r\n", mime.QEncoding.Encode("utf-8", msg.Title))
+	fmt.Fprintf(&b, "Date: %s\r\n", time.Now().Format(time.RFC1123Z))
+	b.WriteString("MIME-Version: 1.0\r\n")
+	b.WriteString("Content-Type: text/plain; charset=utf-8\r\n")
+	b.WriteString("Content-Transfer-Encoding: 8bit\r\n\r\n")
+	b.WriteString(strings.ReplaceAll(body, "\n", "\r\n"))
+
+	var auth smtp.Auth
+	if n.Username != "" {
+		auth = smtp.PlainAuth("", n.Username, n.Password, n.Host)
+	}
+	addr := net.JoinHostPort(n.Host, strconv.Itoa(n.Port))
+	return smtp.SendMail(addr, auth, n.From, []string{target.Address}, []byte(b.String()))
+}
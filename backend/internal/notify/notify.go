@@ -0,0 +1,65 @@
+// Package notify delivers reminders to users through pluggable channels (email, webhooks and Web
+// Push) and schedules the daily study reminders.
+package notify
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/mail"
+
+	"sentencease/backend/internal/models"
+	"sentencease/backend/internal/outbound"
+)
+
+// ErrGone is returned by a Notifier when the target no longer exists, e.g. an expired push
+// subscription. The channel should be removed.
+var ErrGone = errors.New("notification target is gone")
+
+// Message is a notification to one user.
+type Message struct {
+	Title      string `json:"title"`
+	Body       string `json:"body"`
+	URL        string `json:"url,omitempty"` // Where the notification leads, e.g. the learning page
+	StudyDate  string `json:"studyDate"`
+	DueReviews int    `json:"dueReviews"`
+	NewWords   int    `json:"newWords"`
+}
+
+// Target is where a channel delivers: the notification_channels row of a user.
+type Target struct {
+	Address string // Email address, webhook URL or push subscription endpoint
+	Secret  string // Webhook signing secret, optional
+	P256dh  string // Push subscription public key, base64url
+	Auth    string // Push subscription authentication secret, base64url
+}
+
+// Notifier delivers messages through one channel type.
+type Notifier interface {
+	Send(ctx context.Context, target Target, msg Message) error
+}
+
+// ValidateTarget checks that a target is usable for the given channel type before it is stored.
+// Webhook and push URLs must resolve to public addresses.
+func ValidateTarget(ctx context.Context, channelType string, target Target) error {
+	switch channelType {
+	case models.ChannelEmail:
+		if _, err := mail.ParseAddress(target.Address); err != nil {
+			return fmt.Errorf("invalid email address: %w", err)
+		}
+	case models.ChannelWebhook:
+		if err := outbound.ValidateURL(ctx, target.Address, "http", "https"); err != nil {
+			return err
+		}
+	case models.ChannelWebPush:
+		if err := outbound.ValidateURL(ctx, target.Address, "https"); err != nil {
+			return err
+		}
+		if _, _, err := decodeSubscriptionKeys(target); err != nil {
+			return err
+		}
+	default:
+		return fmt.Errorf("unknown channel type %q", channelType)
+	}
+	return nil
+}
@@ -0,0 +1,274 @@
+package notify
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"sentencease/backend/internal/models"
+	"sentencease/backend/internal/srs"
+)
+
+// A reminder that fails on a channel is retried on later runs of the same study day, waiting
+// retryDelay after the first failure and twice as long after each further one, at most
+// maxReminderAttempts times per channel.
+const (
+	maxReminderAttempts = 5
+	retryDelay          = 15 * time.Minute
+)
+
+// Reminders sends each opted-in user one study reminder per study day, at their preferred local
+// time, through every channel they registered.
+type Reminders struct {
+	DB        *pgxpool.Pool
+	Notifiers map[string]Notifier // By channel type; channels without a notifier are skipped
+	AppURL    string              // Link included in reminders, optional
+}
+
+type reminderUser struct {
+	id              uuid.UUID
+	reminderTime    string // HH:MM
+	quietHoursStart *string
+	quietHoursEnd   *string
+}
+
+type reminderChannel struct {
+	id          uuid.UUID
+	channelType string
+	target      Target
+}
+
+// RunOnce sends the reminders due at now. It is safe to call repeatedly: a user is reminded at most
+// once per study day, and a reminder that failed on every channel is retried with backoff.
+func (r *Reminders) RunOnce(ctx context.Context, now time.Time) error {
+	rows, err := r.DB.Query(ctx, `
+		SELECT ns.user_id, to_char(ns.reminder_time, 'HH24:MI'),
+		       to_char(ns.quiet_hours_start, 'HH24:MI'), to_char(ns.quiet_hours_end, 'HH24:MI')
+		FROM notification_settings ns
+		WHERE ns.reminders_enabled
+		  AND EXISTS (SELECT 1 FROM notification_channels nc WHERE nc.user_id = ns.user_id)
+	`)
+	if err != nil {
+		return err
+	}
+	users, err := pgx.CollectRows(rows, func(row pgx.CollectableRow) (reminderUser, error) {
+		var u reminderUser
+		err := row.Scan(&u.id, &u.reminderTime, &u.quietHoursStart, &u.quietHoursEnd)
+		return u, err
+	})
+	if err != nil {
+		return err
+	}
+
+	for _, u := range users {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		if err := r.remind(ctx, u, now); err != nil {
+			log.Printf("Reminders: Error reminding user %s: %v", u.id, err)
+		}
+	}
+	return nil
+}
+
+// remind sends the user's reminder for the current study day if it is due.
+func (r *Reminders) remind(ctx context.Context, u reminderUser, now time.Time) error {
+	loc, dayStartHour, err := srs.GetUserDayConfig(ctx, r.DB, u.id)
+	if err != nil {
+		return err
+	}
+	day := srs.StudyDayAt(now, loc, dayStartHour)
+
+	at, err := reminderAt(day, u.reminderTime)
+	if err != nil {
+		return err
+	}
+	if now.Before(at) || !now.Before(day.End) {
+		return nil
+	}
+	if u.quietHoursStart != nil && u.quietHoursEnd != nil {
+		quiet, err := inQuietHours(now.In(loc), *u.quietHoursStart, *u.quietHoursEnd)
+		if err != nil || quiet {
+			return err
+		}
+	}
+
+	var sent bool
+	err = r.DB.QueryRow(ctx,
+		`SELECT EXISTS(SELECT 1 FROM notification_log WHERE user_id = $1 AND study_date = $2 AND status = $3)`,
+		u.id, day.Date(), models.NotificationSent).Scan(&sent)
+	if err != nil || sent {
+		return err
+	}
+
+	reviews, newWords, err := srs.DueToday(ctx, r.DB, u.id, day)
+	if err != nil {
+		return err
+	}
+	if reviews == 0 && newWords == 0 {
+		return nil
+	}
+
+	channels, err := r.channels(ctx, u.id)
+	if err != nil {
+		return err
+	}
+	failures, err := r.failures(ctx, u.id, day.Date())
+	if err != nil {
+		return err
+	}
+	msg := reminderMessage(day.Date(), reviews, newWords, r.AppURL)
+	for _, ch := range channels {
+		notifier, ok := r.Notifiers[ch.channelType]
+		if !ok {
+			continue
+		}
+		if f, failed := failures[ch.id]; failed && !f.retryDue(now) {
+			continue
+		}
+		r.deliver(ctx, u.id, ch, notifier, msg)
+	}
+	return nil
+}
+
+// channelFailures summarises the failed deliveries of one channel on one study day.
+type channelFailures struct {
+	count int
+	last  time.Time
+}
+
+// retryDue reports whether the channel may be tried again at now.
+func (f channelFailures) retryDue(now time.Time) bool {
+	if f.count >= maxReminderAttempts {
+		return false
+	}
+	return !now.Before(f.last.Add(retryDelay << (f.count - 1)))
+}
+
+// failures returns the failed deliveries of the study day's reminder by channel.
+func (r *Reminders) failures(ctx context.Context, userID uuid.UUID, studyDate string) (map[uuid.UUID]channelFailures, error) {
+	rows, err := r.DB.Query(ctx, `
+		SELECT channel_id, COUNT(*), MAX(created_at) FROM notification_log
+		WHERE user_id = $1 AND study_date = $2 AND status = $3 AND channel_id IS NOT NULL
+		GROUP BY channel_id`, userID, studyDate, models.NotificationFailed)
+	if err != nil {
+		return nil, err
+	}
+	failures := make(map[uuid.UUID]channelFailures)
+	var channelID uuid.UUID
+	var f channelFailures
+	_, err = pgx.ForEachRow(rows, []any{&channelID, &f.count, &f.last}, func() error {
+		failures[channelID] = f
+		return nil
+	})
+	return failures, err
+}
+
+// deliver sends a message through one channel and records the attempt. Channels whose target is
+// gone are removed.
+func (r *Reminders) deliver(ctx context.Context, userID uuid.UUID, ch reminderChannel, notifier Notifier, msg Message) {
+	status, errText := models.NotificationSent, (*string)(nil)
+	sendErr := notifier.Send(ctx, ch.target, msg)
+	if sendErr != nil {
+		status = models.NotificationFailed
+		s := sendErr.Error()
+		errText = &s
+		log.Printf("Reminders: Error sending %s reminder to user %s: %v", ch.channelType, userID, sendErr)
+	}
+
+	_, err := r.DB.Exec(ctx, `
+		INSERT INTO notification_log (user_id, channel_id, channel_type, study_date, due_reviews, new_words, status, error)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+	`, userID, ch.id, ch.channelType, msg.StudyDate, msg.DueReviews, msg.NewWords, status, errText)
+	if err != nil {
+		log.Printf("Reminders: Error logging reminder for user %s: %v", userID, err)
+	}
+
+	if errors.Is(sendErr, ErrGone) {
+		if _, err := r.DB.Exec(ctx, `DELETE FROM notification_channels WHERE id = $1`, ch.id); err != nil {
+			log.Printf("Reminders: Error removing channel %s: %v", ch.id, err)
+		}
+	}
+}
+
+func (r *Reminders) channels(ctx context.Context, userID uuid.UUID) ([]reminderChannel, error) {
+	rows, err := r.DB.Query(ctx, `
+		SELECT id, type, target, COALESCE(secret, ''), COALESCE(p256dh, ''), COALESCE(auth, '')
+		FROM notification_channels WHERE user_id = $1 ORDER BY created_at
+	`, userID)
+	if err != nil {
+		return nil, err
+	}
+	return pgx.CollectRows(rows, func(row pgx.CollectableRow) (reminderChannel, error) {
+		var ch reminderChannel
+		err := row.Scan(&ch.id, &ch.channelType, &ch.target.Address, &ch.target.Secret, &ch.target.P256dh, &ch.target.Auth)
+		return ch, err
+	})
+}
+
+// reminderAt returns when the reminder of a study day is due for the local clock time ("HH:MM").
+func reminderAt(day srs.StudyDay, clock string) (time.Time, error) {
+	at, err := atClock(day.Start, clock)
+	if err != nil {
+		return time.Time{}, err
+	}
+	// 提醒时间在学习日开始之前（如 01:00，而一天从 04:00 开始）时，属于学习日的次日凌晨
+	if at.Before(day.Start) {
+		at = at.AddDate(0, 0, 1)
+	}
+	return at, nil
+}
+
+// atClock returns the time of day clock ("HH:MM") on the date of t, in t's location.
+func atClock(t time.Time, clock string) (time.Time, error) {
+	c, err := time.Parse("15:04", clock)
+	if err != nil {
+		return time.Time{}, err
+	}
+	return time.Date(t.Year(), t.Month(), t.Day(), c.Hour(), c.Minute(), 0, 0, t.Location()), nil
+}
+
+// inQuietHours reports whether the local time t is within [start, end), given as "HH:MM". Quiet
+// hours may wrap around midnight, e.g. 22:00–07:00.
+func inQuietHours(t time.Time, start, end string) (bool, error) {
+	from, err := time.Parse("15:04", start)
+	if err != nil {
+		return false, err
+	}
+	to, err := time.Parse("15:04", end)
+	if err != nil {
+		return false, err
+	}
+	minute := func(c time.Time) int { return c.Hour()*60 + c.Minute() }
+	now, lo, hi := minute(t), minute(from), minute(to)
+	if lo <= hi {
+		return now >= lo && now < hi, nil
+	}
+	return now >= lo || now < hi, nil
+}
+
+func reminderMessage(date string, reviews, newWords int, appURL string) Message {
+	var body string
+	switch {
+	case reviews > 0 && newWords > 0:
+		body = fmt.Sprintf("You have %d reviews and %d new words waiting today.", reviews, newWords)
+	case reviews > 0:
+		body = fmt.Sprintf("You have %d reviews waiting today.", reviews)
+	default:
+		body = fmt.Sprintf("You have %d new words waiting today.", newWords)
+	}
+	return Message{
+		Title:      "Time to study",
+		Body:       body,
+		URL:        appURL,
+		StudyDate:  date,
+		DueReviews: reviews,
+		NewWords:   newWords,
+	}
+}
@@ -0,0 +1,98 @@
+package notify
+
+import (
+	"testing"
+	"time"
+
+	"sentencease/backend/internal/srs"
+)
+
+func TestInQuietHours(t *testing.T) {
+	tests := []struct {
+		name       string
+		now        string
+		start, end string
+		want       bool
+	}{
+		{name: "inside same-day window", now: "13:30", start: "12:00", end: "14:00", want: true},
+		{name: "end is exclusive", now: "14:00", start: "12:00", end: "14:00", want: false},
+		{name: "before same-day window", now: "11:59", start: "12:00", end: "14:00", want: false},
+		{name: "wrapping window before midnight", now: "23:15", start: "22:00", end: "07:00", want: true},
+		{name: "wrapping window after midnight", now: "06:59", start: "22:00", end: "07:00", want: true},
+		{name: "wrapping window start is inclusive", now: "22:00", start: "22:00", end: "07:00", want: true},
+		{name: "outside wrapping window", now: "07:00", start: "22:00", end: "07:00", want: false},
+		{name: "outside wrapping window at noon", now: "12:00", start: "22:00", end: "07:00", want: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			now, err := time.Parse("15:04", tt.now)
+			if err != nil {
+				t.Fatal(err)
+			}
+			got, err := inQuietHours(now, tt.start, tt.end)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != tt.want {
+				t.Errorf("inQuietHours(%s, %s-%s) = %v, want %v", tt.now, tt.start, tt.end, got, tt.want)
+			}
+		})
+	}
+
+	if _, err := inQuietHours(time.Now(), "25:00", "07:00"); err == nil {
+		t.Error("inQuietHours accepted an invalid clock time")
+	}
+}
+
+func TestReminderAt(t *testing.T) {
+	loc, err := time.LoadLocation("Asia/Shanghai")
+	if err != nil {
+		t.Skip("time zone data not available")
+	}
+	// The study day of 10 March starts at 04:00 local time.
+	day := srs.StudyDayAt(time.Date(2026, 3, 10, 12, 0, 0, 0, loc), loc, 4)
+
+	tests := []struct {
+		clock string
+		want  time.Time
+	}{
+		{clock: "19:00", want: time.Date(2026, 3, 10, 19, 0, 0, 0, loc)},
+		{clock: "04:00", want: time.Date(2026, 3, 10, 4, 0, 0, 0, loc)},
+		// Before the day starts: the early morning that still belongs to the study day.
+		{clock: "01:30", want: time.Date(2026, 3, 11, 1, 30, 0, 0, loc)},
+		{clock: "03:59", want: time.Date(2026, 3, 11, 3, 59, 0, 0, loc)},
+	}
+	for _, tt := range tests {
+		got, err := reminderAt(day, tt.clock)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !got.Equal(tt.want) {
+			t.Errorf("reminderAt(%s) = %v, want %v", tt.clock, got, tt.want)
+		}
+		if !day.Contains(got) {
+			t.Errorf("reminderAt(%s) = %v is outside the study day", tt.clock, got)
+		}
+	}
+}
+
+func TestRetryDue(t *testing.T) {
+	last := time.Date(2026, 3, 10, 19, 0, 0, 0, time.UTC)
+	tests := []struct {
+		failures int
+		after    time.Duration
+		want     bool
+	}{
+		{failures: 1, after: 14 * time.Minute, want: false},
+		{failures: 1, after: 15 * time.Minute, want: true},
+		{failures: 2, after: 29 * time.Minute, want: false},
+		{failures: 3, after: time.Hour, want: true},
+		{failures: maxReminderAttempts, after: 24 * time.Hour, want: false},
+	}
+	for _, tt := range tests {
+		f := channelFailures{count: tt.failures, last: last}
+		if got := f.retryDue(last.Add(tt.after)); got != tt.want {
+			t.Errorf("retryDue after %d failures and %v = %v, want %v", tt.failures, tt.after, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,66 @@
+package notify
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"sentencease/backend/internal/outbound"
+)
+
+// WebhookNotifier posts messages as JSON to a user-supplied URL.
+//
+// The body is {"event": "reminder", "sentAt": ..., ...Message}. When the channel has a secret, the
+// request is signed like webhook deliveries (see outbound.Signature), so receivers can verify that
+// it came from us.
+type WebhookNotifier struct {
+	HTTPClient *http.Client
+}
+
+// NewWebhookNotifier creates a webhook notifier with a request timeout. Its client only connects to
+// public addresses and does not follow redirects.
+func NewWebhookNotifier() *WebhookNotifier {
+	return &WebhookNotifier{HTTPClient: outbound.NewClient(10 * time.Second)}
+}
+
+type webhookPayload struct {
+	Event  string    `json:"event"`
+	SentAt time.Time `json:"sentAt"`
+	Message
+}
+
+// Send implements Notifier. 410 Gone responses are reported as ErrGone.
+func (n *WebhookNotifier) Send(ctx context.Context, target Target, msg Message) error {
+	body, err := json.Marshal(webhookPayload{Event: "reminder", SentAt: time.Now(), Message: msg})
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, target.Address, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if target.Secret != "" {
+		outbound.Sign(req, target.Secret, body)
+	}
+
+	resp, err := n.HTTPClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10))
+
+	switch {
+	case resp.StatusCode == http.StatusGone:
+		return ErrGone
+	case resp.StatusCode < 200 || resp.StatusCode > 299:
+		return fmt.Errorf("webhook responded with status %d", resp.StatusCode)
+	}
+	return nil
+}
@@ -0,0 +1,240 @@
+package notify
+
+import (
+	"bytes"
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/ecdh"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"golang.org/x/crypto/hkdf"
+
+	"sentencease/backend/internal/outbound"
+)
+
+// webPushTTL is how long the push service keeps an undelivered reminder.
+const webPushTTL = 12 * time.Hour
+
+// webPushRecordSize is the aes128gcm record size; a reminder always fits in one record.
+const webPushRecordSize = 4096
+
+// WebPushNotifier sends messages to browser push subscriptions, encrypted as in RFC 8291 and
+// authenticated with VAPID (RFC 8292).
+type WebPushNotifier struct {
+	HTTPClient *http.Client
+	Subject    string // Contact for the push service, "mailto:..." or an https URL
+
+	publicKey  string // Uncompressed P-256 point, base64url
+	privateKey *ecdsa.PrivateKey
+}
+
+// NewWebPushNotifier creates a notifier from a VAPID key pair in base64url encoding, as printed by
+// GenerateVAPIDKeys.
+func NewWebPushNotifier(publicKey, privateKey, subject string) (*WebPushNotifier, error) {
+	d, err := decodeBase64URL(privateKey)
+	if err != nil {
+		return nil, fmt.Errorf("invalid VAPID private key: %w", err)
+	}
+	key, err := ecdh.P256().NewPrivateKey(d)
+	if err != nil {
+		return nil, fmt.Errorf("invalid VAPID private key: %w", err)
+	}
+	pub := key.PublicKey().Bytes()
+	if base64.RawURLEncoding.EncodeToString(pub) != publicKey {
+		return nil, errors.New("VAPID public key does not match the private key")
+	}
+
+	return &WebPushNotifier{
+		HTTPClient: outbound.NewClient(10 * time.Second), // Subscription endpoints are supplied by users
+		Subject:    subject,
+		publicKey:  publicKey,
+		privateKey: &ecdsa.PrivateKey{
+			PublicKey: ecdsa.PublicKey{
+				Curve: elliptic.P256(),
+				X:     new(big.Int).SetBytes(pub[1:33]),
+				Y:     new(big.Int).SetBytes(pub[33:]),
+			},
+			D: new(big.Int).SetBytes(d),
+		},
+	}, nil
+}
+
+// GenerateVAPIDKeys returns a new VAPID key pair in base64url encoding.
+func GenerateVAPIDKeys() (publicKey, privateKey string, err error) {
+	key, err := ecdh.P256().GenerateKey(rand.Reader)
+	if err != nil {
+		return "", "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(key.PublicKey().Bytes()),
+		base64.RawURLEncoding.EncodeToString(key.Bytes()), nil
+}
+
+// Send implements Notifier. The push service answers 404 or 410 for expired subscriptions,
+// which are reported as ErrGone.
+func (n *WebPushNotifier) Send(ctx context.Context, target Target, msg Message) error {
+	payload, err := json.Marshal(msg)
+	if err != nil {
+		return err
+	}
+	body, err := encryptPushPayload(target, payload)
+	if err != nil {
+		return err
+	}
+	authorization, err := n.vapidAuthorization(target.Address)
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, target.Address, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Authorization", authorization)
+	req.Header.Set("Content-Encoding", "aes128gcm")
+	req.Header.Set("Content-Type", "application/octet-stream")
+	req.Header.Set("TTL", strconv.Itoa(int(webPushTTL.Seconds())))
+	req.Header.Set("Urgency", "normal")
+
+	resp, err := n.HTTPClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	detail, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+
+	switch {
+	case resp.StatusCode == http.StatusNotFound || resp.StatusCode == http.StatusGone:
+		return ErrGone
+	case resp.StatusCode < 200 || resp.StatusCode > 299:
+		return fmt.Errorf("push service responded with status %d: %s", resp.StatusCode, detail)
+	}
+	return nil
+}
+
+// vapidAuthorization returns the Authorization header for a push endpoint: a JWT signed with the
+// VAPID key whose audience is the origin of the endpoint.
+func (n *WebPushNotifier) vapidAuthorization(endpoint string) (string, error) {
+	u, err := url.Parse(endpoint)
+	if err != nil {
+		return "", err
+	}
+	// aud 必须是字符串；jwt.RegisteredClaims 默认会把它编码为数组
+	claims := jwt.MapClaims{
+		"aud": u.Scheme + "://" + u.Host,
+		"exp": time.Now().Add(webPushTTL).Unix(),
+		"sub": n.Subject,
+	}
+	token, err := jwt.NewWithClaims(jwt.SigningMethodES256, claims).SignedString(n.privateKey)
+	if err != nil {
+		return "", err
+	}
+	return "vapid t=" + token + ", k=" + n.publicKey, nil
+}
+
+// decodeSubscriptionKeys decodes the keys of a push subscription.
+func decodeSubscriptionKeys(target Target) (*ecdh.PublicKey, []byte, error) {
+	p256dh, err := decodeBase64URL(target.P256dh)
+	if err != nil {
+		return nil, nil, errors.New("invalid p256dh key")
+	}
+	uaPublic, err := ecdh.P256().NewPublicKey(p256dh)
+	if err != nil {
+		return nil, nil, errors.New("invalid p256dh key")
+	}
+	authSecret, err := decodeBase64URL(target.Auth)
+	if err != nil || len(authSecret) != 16 {
+		return nil, nil, errors.New("invalid auth secret")
+	}
+	return uaPublic, authSecret, nil
+}
+
+// encryptPushPayload encrypts a payload for a push subscription with the aes128gcm content coding
+// (RFC 8188) and the key derivation of RFC 8291.
+func encryptPushPayload(target Target, payload []byte) ([]byte, error) {
+	asPrivate, err := ecdh.P256().GenerateKey(rand.Reader)
+	if err != nil {
+		return nil, err
+	}
+	salt := make([]byte, 16)
+	if _, err := rand.Read(salt); err != nil {
+		return nil, err
+	}
+	return sealPushPayload(target, payload, asPrivate, salt)
+}
+
+// sealPushPayload encrypts a payload with the given ephemeral key pair and salt.
+func sealPushPayload(target Target, payload []byte, asPrivate *ecdh.PrivateKey, salt []byte) ([]byte, error) {
+	uaPublic, authSecret, err := decodeSubscriptionKeys(target)
+	if err != nil {
+		return nil, err
+	}
+
+	sharedSecret, err := asPrivate.ECDH(uaPublic)
+	if err != nil {
+		return nil, err
+	}
+	asPublic := asPrivate.PublicKey().Bytes()
+
+	// IKM = HKDF(auth_secret, ecdh_secret, "WebPush: info" || 0x00 || ua_public || as_public, 32)
+	keyInfo := append([]byte("WebPush: info\x00"), uaPublic.Bytes()...)
+	keyInfo = append(keyInfo, asPublic...)
+	ikm := make([]byte, 32)
+	if _, err := io.ReadFull(hkdf.New(sha256.New, sharedSecret, authSecret, keyInfo), ikm); err != nil {
+		return nil, err
+	}
+
+	cek := make([]byte, 16)
+	if _, err := io.ReadFull(hkdf.New(sha256.New, ikm, salt, []byte("Content-Encoding: aes128gcm\x00")), cek); err != nil {
+		return nil, err
+	}
+	nonce := make([]byte, 12)
+	if _, err := io.ReadFull(hkdf.New(sha256.New, ikm, salt, []byte("Content-Encoding: nonce\x00")), nonce); err != nil {
+		return nil, err
+	}
+
+	block, err := aes.NewCipher(cek)
+	if err != nil {
+		return nil, err
+	}
+	gcm, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, err
+	}
+	// 单条记录，以 0x02 作为最后一条记录的分隔符
+	plaintext := append(payload, 0x02)
+	if len(plaintext)+gcm.Overhead() > webPushRecordSize {
+		return nil, errors.New("push payload is too large")
+	}
+
+	// Header: salt (16) || record size (4) || key ID length (1) || key ID (as_public)
+	header := make([]byte, 0, 21+len(asPublic))
+	header = append(header, salt...)
+	header = binary.BigEndian.AppendUint32(header, webPushRecordSize)
+	header = append(header, byte(len(asPublic)))
+	header = append(header, asPublic...)
+	return gcm.Seal(header, nonce, plaintext, nil), nil
+}
+
+// decodeBase64URL decodes base64url with or without padding, as browsers vary.
+func decodeBase64URL(s string) ([]byte, error) {
+	if b, err := base64.RawURLEncoding.DecodeString(s); err == nil {
+		return b, nil
+	}
+	return base64.URLEncoding.DecodeString(s)
+}
@@ -0,0 +1,48 @@
+package notify
+
+import (
+	"bytes"
+	"crypto/ecdh"
+	"encoding/base64"
+	"testing"
+)
+
+// TestSealPushPayloadRFC8291 encrypts the example of RFC 8291 Appendix A with its keys and salt.
+func TestSealPushPayloadRFC8291(t *testing.T) {
+	decode := func(s string) []byte {
+		t.Helper()
+		b, err := base64.RawURLEncoding.DecodeString(s)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return b
+	}
+
+	asPrivate, err := ecdh.P256().NewPrivateKey(decode("yfWPiYE-n46HLnH0KqZOF1fJJU3MYrct3AELtAQ-oRw"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := base64.RawURLEncoding.EncodeToString(asPrivate.PublicKey().Bytes()); got != "BP4z9KsN6nGRTbVYI_c7VJSPQTBtkgcy27mlmlMoZIIgDll6e3vCYLocInmYWAmS6TlzAC8wEqKK6PBru3jl7A8" {
+		t.Fatalf("application server public key = %s", got)
+	}
+	target := Target{
+		P256dh: "BCVxsr7N_eNgVRqvHtD0zTZsEc6-VV-JvLexhqUzORcxaOzi6-AYWXvTBHm4bjyPjs7Vd8pZGH6SRpkNtoIAiw4",
+		Auth:   "BTBZMqHH6r4Tts7J_aSIgg",
+	}
+
+	got, err := sealPushPayload(target, []byte("When I grow up, I want to be a watermelon"), asPrivate, decode("DGv6ra1nlYgDCS1FRnbzlw"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := decode("DGv6ra1nlYgDCS1FRnbzlwAAEABBBP4z9KsN6nGRTbVYI_c7VJSPQTBtkgcy27mlmlMoZIIgDll6e3vCYLocInmYWAmS6TlzAC8wEqKK6PBru3jl7A_yl95bQpu6cVPTpK4Mqgkf1CXztLVBSt2Ks3oZwbuwXPXLWyouBWLVWGNWQexSgSxsj_Qulcy4a-fN")
+	if !bytes.Equal(got, want) {
+		t.Errorf("sealPushPayload =\n%s\nwant\n%s", base64.RawURLEncoding.EncodeToString(got), base64.RawURLEncoding.EncodeToString(want))
+	}
+}
+
+func TestEncryptPushPayloadRejectsBadKeys(t *testing.T) {
+	target := Target{P256dh: "not-a-key", Auth: "BTBZMqHH6r4Tts7J_aSIgg"}
+	if _, err := encryptPushPayload(target, []byte("hi")); err == nil {
+		t.Fatal("encryptPushPayload accepted an invalid p256dh key")
+	}
+}
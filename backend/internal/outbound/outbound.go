@@ -0,0 +1,131 @@
+// Package outbound makes HTTP requests to URLs supplied by users, such as webhook endpoints, without
+// letting them reach the server's own network: loopback, private, link-local and other
+// non-public addresses are refused both when a URL is registered and when it is dialled, and
+// redirects are not followed. It also signs request bodies so receivers can authenticate them.
+package outbound
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/netip"
+	"net/url"
+	"slices"
+	"strconv"
+	"syscall"
+	"time"
+)
+
+// ErrForbiddenAddress is returned for URLs that resolve to a non-public address.
+var ErrForbiddenAddress = errors.New("URL must not point to a private or local address")
+
+// resolveTimeout bounds the DNS lookup made when validating a URL.
+const resolveTimeout = 5 * time.Second
+
+// reservedPrefixes are non-public ranges not covered by the netip.Addr predicates.
+var reservedPrefixes = []netip.Prefix{
+	netip.MustParsePrefix("0.0.0.0/8"),     // "This network"
+	netip.MustParsePrefix("100.64.0.0/10"), // Carrier-grade NAT (RFC 6598)
+	netip.MustParsePrefix("198.18.0.0/15"), // Benchmarking (RFC 2544)
+}
+
+// publicAddress reports whether ip may be contacted.
+func publicAddress(ip netip.Addr) bool {
+	ip = ip.Unmap()
+	if !ip.IsValid() || !ip.IsGlobalUnicast() || ip.IsPrivate() {
+		return false
+	}
+	for _, p := range reservedPrefixes {
+		if p.Contains(ip) {
+			return false
+		}
+	}
+	return true
+}
+
+// ValidateURL checks that raw is an absolute URL with one of the given schemes whose host resolves
+// only to public addresses. The dial-time check of NewClient still applies, as DNS answers can change.
+func ValidateURL(ctx context.Context, raw string, schemes ...string) error {
+	u, err := url.Parse(raw)
+	if err != nil || u.Host == "" {
+		return errors.New("invalid URL")
+	}
+	if !slices.Contains(schemes, u.Scheme) {
+		return fmt.Errorf("URL scheme must be one of %v", schemes)
+	}
+
+	host := u.Hostname()
+	if ip, err := netip.ParseAddr(host); err == nil {
+		if !publicAddress(ip) {
+			return ErrForbiddenAddress
+		}
+		return nil
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, resolveTimeout)
+	defer cancel()
+	ips, err := net.DefaultResolver.LookupNetIP(ctx, "ip", host)
+	if err != nil {
+		return fmt.Errorf("could not resolve %s", host)
+	}
+	for _, ip := range ips {
+		if !publicAddress(ip) {
+			return ErrForbiddenAddress
+		}
+	}
+	return nil
+}
+
+// checkDial is a net.Dialer Control function refusing connections to non-public addresses. It sees
+// the resolved address, so it also catches hosts that changed their DNS after validation.
+func checkDial(network, address string, _ syscall.RawConn) error {
+	addrPort, err := netip.ParseAddrPort(address)
+	if err != nil {
+		return err
+	}
+	if !publicAddress(addrPort.Addr()) {
+		return ErrForbiddenAddress
+	}
+	return nil
+}
+
+// NewClient returns an HTTP client for user-supplied URLs. It only connects to public addresses,
+// ignores proxy settings (a proxy would dial on our behalf) and returns redirects as responses
+// instead of following them.
+func NewClient(timeout time.Duration) *http.Client {
+	dialer := &net.Dialer{Timeout: 10 * time.Second, Control: checkDial}
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.Proxy = nil
+	transport.DialContext = dialer.DialContext
+	return &http.Client{
+		Timeout:   timeout,
+		Transport: transport,
+		CheckRedirect: func(*http.Request, []*http.Request) error {
+			return http.ErrUseLastResponse
+		},
+	}
+}
+
+// Signature returns the X-Signature header value for a request body sent with the given
+// X-Webhook-Timestamp header value: "sha256=" and the hex HMAC-SHA256 of timestamp, ".", and the
+// body, keyed with secret. Covering the timestamp lets receivers reject replayed requests.
+func Signature(secret, timestamp string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(timestamp))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// Sign sets the X-Webhook-Timestamp header of req to the current Unix time and the X-Signature
+// header to the Signature of body with that timestamp.
+func Sign(req *http.Request, secret string, body []byte) {
+	timestamp := strconv.FormatInt(time.Now().Unix(), 10)
+	req.Header.Set("X-Webhook-Timestamp", timestamp)
+	req.Header.Set("X-Signature", Signature(secret, timestamp, body))
+}
@@ -67,6 +67,19 @@ func (s *Seeder) SeedDatabase(ctx context.Context, words []models.Word, meanings
 		if err != nil {
 			// Log the error but continue, to not fail the entire batch.
 			log.Printf("Error inserting meaning for word %s: %v", meaning.Lemma, err)
+			continue
+		}
+
+		// Alternative sentences are used by leech remediation.
+		for _, example := range meaning.Examples {
+			_, err := tx.Exec(ctx, `
+				INSERT INTO meaning_examples (meaning_id, sentence, translation)
+				SELECT id, $3, $4 FROM meanings WHERE word_id = $1 AND definition = $2
+				ON CONFLICT (meaning_id, sentence) DO NOTHING
+			`, wordID, meaning.Definition, example.Sentence, example.Translation)
+			if err != nil {
+				log.Printf("Error inserting example sentence for word %s: %v", meaning.Lemma, err)
+			}
 		}
 	}
 
@@ -133,6 +146,16 @@ func (s *Seeder) LoadWordsFromDirectory(path, source string) ([]models.Word, []m
 						ExampleSentenceTranslation: &exampleTranslation,
 						Unit:                       sw.Unit,
 					}
+					// Sentences beyond the translations are not used as a main example. They are
+					// alternatives only when the word has a single translation; otherwise we cannot
+					// tell which meaning they illustrate.
+					if len(sw.Translations) == 1 {
+						for _, extra := range sw.Sentences[min(1, len(sw.Sentences)):] {
+							if extra.Sentence != "" {
+								meaning.Examples = append(meaning.Examples, models.MeaningExample{Sentence: extra.Sentence, Translation: extra.Translation})
+							}
+						}
+					}
 					meanings = append(meanings, meaning)
 				}
 			}
@@ -0,0 +1,317 @@
+package srs
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"time"
+
+	"sentencease/backend/internal/models"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// eventXP is the XP awarded for each event, on top of the XP of achievements earned through it.
+var eventXP = map[string]int{
+	models.EventReviewCompleted: 1,
+	models.EventPlanCompleted:   10,
+	models.EventStreakExtended:  5,
+	models.EventBookFinished:    100,
+}
+
+// Achievement metrics: the user's progress measures that achievement goals refer to.
+const (
+	metricReviews  = "reviews"  // Reviews done, including cram
+	metricNewWords = "newWords" // Cards studied for the first time
+	metricStreak   = "streak"   // Longest run of study days
+	metricPlans    = "plans"    // Days on which every daily plan word was reviewed
+	metricBooks    = "books"    // Vocabulary sources finished
+)
+
+// achievementMetrics computes each metric. They take a querier so that events recorded in the
+// current transaction are counted.
+var achievementMetrics = map[string]func(ctx context.Context, db querier, userID uuid.UUID) (int, error){
+	metricReviews: func(ctx context.Context, db querier, userID uuid.UUID) (int, error) {
+		return queryInt(ctx, db, `SELECT COALESCE(SUM(reviews), 0) FROM review_daily_stats WHERE user_id = $1`, userID)
+	},
+	metricNewWords: func(ctx context.Context, db querier, userID uuid.UUID) (int, error) {
+		return queryInt(ctx, db, `SELECT COALESCE(SUM(new_cards), 0) FROM review_daily_stats WHERE user_id = $1`, userID)
+	},
+	metricStreak: func(ctx context.Context, db querier, userID uuid.UUID) (int, error) {
+		streak, err := StudyStreaks(ctx, db, userID)
+		if err != nil {
+			return 0, err
+		}
+		return streak.Longest, nil
+	},
+	metricPlans: func(ctx context.Context, db querier, userID uuid.UUID) (int, error) {
+		return queryInt(ctx, db, `SELECT COUNT(*) FROM xp_awards WHERE user_id = $1 AND event = $2`, userID, models.EventPlanCompleted)
+	},
+	metricBooks: func(ctx context.Context, db querier, userID uuid.UUID) (int, error) {
+		return queryInt(ctx, db, `SELECT COUNT(*) FROM xp_awards WHERE user_id = $1 AND event = $2`, userID, models.EventBookFinished)
+	},
+}
+
+func queryInt(ctx context.Context, db querier, sql string, args ...any) (int, error) {
+	var n int
+	err := db.QueryRow(ctx, sql, args...).Scan(&n)
+	return n, err
+}
+
+// AchievementRule declares an achievement: it is earned once Metric reaches Goal, and checked after
+// every event of type Event. Rules can be added without schema changes; the ID of a rule must not
+// change once users have earned it.
+type AchievementRule struct {
+	ID          string
+	Name        string
+	Description string
+	XP          int
+	Event       string
+	Metric      string
+	Goal        int
+}
+
+// AchievementRules are all achievements, in display order.
+var AchievementRules = []AchievementRule{
+	{ID: "first-review", Name: "First Steps", Description: "Complete your first review", XP: 10, Event: models.EventReviewCompleted, Metric: metricReviews, Goal: 1},
+	{ID: "reviews-100", Name: "Centurion", Description: "Complete 100 reviews", XP: 50, Event: models.EventReviewCompleted, Metric: metricReviews, Goal: 100},
+	{ID: "reviews-1000", Name: "Dedicated", Description: "Complete 1,000 reviews", XP: 200, Event: models.EventReviewCompleted, Metric: metricReviews, Goal: 1000},
+	{ID: "reviews-10000", Name: "Unstoppable", Description: "Complete 10,000 reviews", XP: 1000, Event: models.EventReviewCompleted, Metric: metricReviews, Goal: 10000},
+	{ID: "words-50", Name: "Word Collector", Description: "Learn 50 new words", XP: 50, Event: models.EventReviewCompleted, Metric: metricNewWords, Goal: 50},
+	{ID: "words-500", Name: "Wordsmith", Description: "Learn 500 new words", XP: 250, Event: models.EventReviewCompleted, Metric: metricNewWords, Goal: 500},
+	{ID: "words-2000", Name: "Walking Dictionary", Description: "Learn 2,000 new words", XP: 1000, Event: models.EventReviewCompleted, Metric: metricNewWords, Goal: 2000},
+	{ID: "streak-3", Name: "Warming Up", Description: "Study 3 days in a row", XP: 20, Event: models.EventStreakExtended, Metric: metricStreak, Goal: 3},
+	{ID: "streak-7", Name: "One Week Strong", Description: "Study 7 days in a row", XP: 50, Event: models.EventStreakExtended, Metric: metricStreak, Goal: 7},
+	{ID: "streak-30", Name: "Monthly Habit", Description: "Study 30 days in a row", XP: 300, Event: models.EventStreakExtended, Metric: metricStreak, Goal: 30},
+	{ID: "streak-100", Name: "Centennial", Description: "Study 100 days in a row", XP: 1000, Event: models.EventStreakExtended, Metric: metricStreak, Goal: 100},
+	{ID: "plan-1", Name: "Plan Keeper", Description: "Finish all words of a daily plan", XP: 20, Event: models.EventPlanCompleted, Metric: metricPlans, Goal: 1},
+	{ID: "plans-30", Name: "Plan Master", Description: "Finish your daily plans on 30 days", XP: 300, Event: models.EventPlanCompleted, Metric: metricPlans, Goal: 30},
+	{ID: "book-1", Name: "Bookworm", Description: "Study every word of a vocabulary book", XP: 500, Event: models.EventBookFinished, Metric: metricBooks, Goal: 1},
+}
+
+// Event is something a user did that may award XP and achievements.
+type Event struct {
+	Type string
+	// Key identifies one-off events, e.g. the study date of a completed plan, so that each is
+	// rewarded once. Events without a key, such as reviews, are rewarded every time.
+	Key string
+}
+
+func (r AchievementRule) achievement(progress int, earnedAt *time.Time) models.Achievement {
+	return models.Achievement{
+		ID:          r.ID,
+		Name:        r.Name,
+		Description: r.Description,
+		XP:          r.XP,
+		Goal:        r.Goal,
+		Progress:    min(progress, r.Goal),
+		Earned:      earnedAt != nil,
+		EarnedAt:    earnedAt,
+	}
+}
+
+// earnedAchievements returns when the user earned each achievement.
+func earnedAchievements(ctx context.Context, db querier, userID uuid.UUID) (map[string]time.Time, error) {
+	rows, err := db.Query(ctx, `SELECT achievement_id, earned_at FROM user_achievements WHERE user_id = $1`, userID)
+	if err != nil {
+		return nil, err
+	}
+	earned := make(map[string]time.Time)
+	var id string
+	var at time.Time
+	_, err = pgx.ForEachRow(rows, []any{&id, &at}, func() error {
+		earned[id] = at
+		return nil
+	})
+	return earned, err
+}
+
+// ReviewEvents returns the events caused by a review that UpdateProgress has just recorded: the
+// review itself, the streak extended by the day's first review, the day's plans being finished and,
+// for a newly learned card, its vocabulary source being finished. One-off events are keyed, so
+// emitting them again on later reviews does nothing. Only the first review of a card in a study
+// day earns review XP, so reviewing the same card over and over does not.
+func ReviewEvents(ctx context.Context, db *pgxpool.Pool, userID uuid.UUID, meaningID int) ([]Event, error) {
+	day, err := GetStudyDay(ctx, db, userID)
+	if err != nil {
+		return nil, err
+	}
+	var events []Event
+
+	var firstToday, streakContinues bool
+	err = db.QueryRow(ctx, `
+		SELECT
+			(SELECT COUNT(*) FROM review_log
+			 WHERE user_id = $1 AND meaning_id = tracked_meaning_id($1, $2) AND NOT is_cram
+			   AND reviewed_at >= $3 AND reviewed_at < $4) = 1,
+			-- 最近一个学习日到今天之间只隔着假期时，连续天数才延续
+			EXISTS (
+				SELECT 1 FROM review_daily_stats s
+				WHERE s.user_id = $1 AND s.study_date = (
+					SELECT MAX(study_date) FROM review_daily_stats
+					WHERE user_id = $1 AND reviews > 0 AND study_date < $5::date)
+				  AND NOT EXISTS (
+					SELECT 1 FROM generate_series(s.study_date + 1, $5::date - 1, interval '1 day') AS d
+					WHERE NOT EXISTS (
+						SELECT 1 FROM vacations v
+						WHERE v.user_id = $1 AND d::date BETWEEN v.start_date AND v.end_date)))`,
+		userID, meaningID, day.Start, day.End, day.Date()).Scan(&firstToday, &streakContinues)
+	if err != nil {
+		return nil, err
+	}
+	if firstToday {
+		events = append(events, Event{Type: models.EventReviewCompleted})
+	}
+	if streakContinues {
+		events = append(events, Event{Type: models.EventStreakExtended, Key: day.Date()})
+	}
+
+	completed, total, err := GetDailyPlanProgress(ctx, db, userID, day)
+	if err != nil {
+		return nil, err
+	}
+	if total > 0 && completed == total {
+		events = append(events, Event{Type: models.EventPlanCompleted, Key: day.Date()})
+	}
+
+	// 只有新学的词才可能让整本词书学完，避免每次复习都扫描整本词书
+	rows, err := db.Query(ctx, `
+		SELECT w.source
+		FROM meanings m
+		JOIN words w ON w.id = m.word_id
+		JOIN user_progress up ON up.user_id = $1 AND up.meaning_id = tracked_meaning_id($1, m.id)
+		WHERE m.id = $2 AND up.review_count = 1 AND COALESCE(w.source, '') <> ''
+		  AND NOT EXISTS (
+			SELECT 1 FROM meanings m2
+			JOIN words w2 ON w2.id = m2.word_id
+			WHERE w2.source = w.source AND m2.id = tracked_meaning_id($1, m2.id)
+			  AND NOT `+hasStudied("$1", "m2.id")+`
+		  )`, userID, meaningID)
+	if err != nil {
+		return nil, err
+	}
+	sources, err := pgx.CollectRows(rows, pgx.RowTo[string])
+	if err != nil {
+		return nil, err
+	}
+	for _, source := range sources {
+		events = append(events, Event{Type: models.EventBookFinished, Key: source})
+	}
+	return events, nil
+}
+
+// EmitEvents awards the XP of the events and every achievement whose rule listens to one of them
+// and whose goal has been reached. It returns the XP gained and the achievements newly earned.
+func EmitEvents(ctx context.Context, db *pgxpool.Pool, userID uuid.UUID, events []Event) (*models.Rewards, error) {
+	tx, err := db.Begin(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback(ctx)
+
+	earned, err := earnedAchievements(ctx, tx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	rewards := &models.Rewards{Achievements: []models.Achievement{}}
+	for _, e := range events {
+		if e.Key != "" {
+			tag, err := tx.Exec(ctx, `
+				INSERT INTO xp_awards (user_id, event, event_key, xp) VALUES ($1, $2, $3, $4)
+				ON CONFLICT (user_id, event, event_key) DO NOTHING`, userID, e.Type, e.Key, eventXP[e.Type])
+			if err != nil {
+				return nil, err
+			}
+			if tag.RowsAffected() == 0 {
+				continue // Already rewarded
+			}
+		}
+		rewards.XP += eventXP[e.Type]
+
+		// 指标在事件记录之后计算，这样本次事件也被计入
+		metrics := make(map[string]int)
+		for _, rule := range AchievementRules {
+			if rule.Event != e.Type {
+				continue
+			}
+			if _, ok := earned[rule.ID]; ok {
+				continue
+			}
+			value, ok := metrics[rule.Metric]
+			if !ok {
+				if value, err = achievementMetrics[rule.Metric](ctx, tx, userID); err != nil {
+					return nil, err
+				}
+				metrics[rule.Metric] = value
+			}
+			if value < rule.Goal {
+				continue
+			}
+
+			var earnedAt time.Time
+			err := tx.QueryRow(ctx, `
+				INSERT INTO user_achievements (user_id, achievement_id, xp) VALUES ($1, $2, $3)
+				ON CONFLICT (user_id, achievement_id) DO NOTHING
+				RETURNING earned_at`, userID, rule.ID, rule.XP).Scan(&earnedAt)
+			if err != nil {
+				if errors.Is(err, pgx.ErrNoRows) {
+					continue // Earned concurrently
+				}
+				return nil, err
+			}
+			earned[rule.ID] = earnedAt
+			rewards.XP += rule.XP
+			rewards.Achievements = append(rewards.Achievements, rule.achievement(value, &earnedAt))
+		}
+	}
+
+	if rewards.XP > 0 {
+		if _, err := tx.Exec(ctx, `UPDATE users SET xp = xp + $2 WHERE id = $1`, userID, rewards.XP); err != nil {
+			return nil, err
+		}
+	}
+	return rewards, tx.Commit(ctx)
+}
+
+// ListAchievements returns the user's XP and every achievement with the user's progress, earned
+// achievements first (most recent first), then locked ones in rule order.
+func ListAchievements(ctx context.Context, db *pgxpool.Pool, userID uuid.UUID) (*models.AchievementList, error) {
+	list := &models.AchievementList{}
+	if err := db.QueryRow(ctx, `SELECT xp FROM users WHERE id = $1`, userID).Scan(&list.XP); err != nil {
+		return nil, err
+	}
+
+	earned, err := earnedAchievements(ctx, db, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	metrics := make(map[string]int)
+	for _, rule := range AchievementRules {
+		if at, ok := earned[rule.ID]; ok {
+			list.Achievements = append(list.Achievements, rule.achievement(rule.Goal, &at))
+			list.Earned++
+			continue
+		}
+		value, ok := metrics[rule.Metric]
+		if !ok {
+			if value, err = achievementMetrics[rule.Metric](ctx, db, userID); err != nil {
+				return nil, err
+			}
+			metrics[rule.Metric] = value
+		}
+		list.Achievements = append(list.Achievements, rule.achievement(value, nil))
+	}
+
+	sort.SliceStable(list.Achievements, func(i, j int) bool {
+		a, b := list.Achievements[i], list.Achievements[j]
+		if a.Earned != b.Earned {
+			return a.Earned
+		}
+		return a.Earned && a.EarnedAt.After(*b.EarnedAt)
+	})
+	return list, nil
+}
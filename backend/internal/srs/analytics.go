@@ -0,0 +1,227 @@
+package srs
+
+import (
+	"context"
+	"time"
+
+	"sentencease/backend/internal/models"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// MaxAnalyticsDays is the longest period the day-by-day analytics cover.
+const MaxAnalyticsDays = 366
+
+// analyticsDates returns the last n study dates of the user, oldest first, ending today.
+func analyticsDates(ctx context.Context, db *pgxpool.Pool, userID uuid.UUID, n int) ([]string, error) {
+	today, err := GetStudyDay(ctx, db, userID)
+	if err != nil {
+		return nil, err
+	}
+	dates := make([]string, n)
+	for i := range dates {
+		dates[i] = today.AddDays(i - n + 1).Date()
+	}
+	return dates, nil
+}
+
+// ReviewHeatmap returns the number of reviews on each of the last days study days, oldest first.
+func ReviewHeatmap(ctx context.Context, db *pgxpool.Pool, userID uuid.UUID, days int) ([]models.HeatmapDay, error) {
+	dates, err := analyticsDates(ctx, db, userID, days)
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := db.Query(ctx, `
+		SELECT to_char(study_date, 'YYYY-MM-DD'), reviews FROM review_daily_stats
+		WHERE user_id = $1 AND study_date BETWEEN $2 AND $3`, userID, dates[0], dates[len(dates)-1])
+	if err != nil {
+		return nil, err
+	}
+	counts := make(map[string]int)
+	var date string
+	var reviews int
+	_, err = pgx.ForEachRow(rows, []any{&date, &reviews}, func() error {
+		counts[date] = reviews
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	heatmap := make([]models.HeatmapDay, len(dates))
+	for i, d := range dates {
+		heatmap[i] = models.HeatmapDay{Date: d, Reviews: counts[d]}
+	}
+	return heatmap, nil
+}
+
+// StudyStreaks returns the user's current and longest runs of study days. A day counts when at
+// least one review was made; vacation days are skipped over. Today only extends the streak once
+// the user has studied, so an unbroken streak up to yesterday is still current.
+func StudyStreaks(ctx context.Context, db querier, userID uuid.UUID) (*models.StudyStreak, error) {
+	today, err := GetStudyDay(ctx, db, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := db.Query(ctx, `
+		SELECT to_char(study_date, 'YYYY-MM-DD') FROM review_daily_stats
+		WHERE user_id = $1 AND reviews > 0
+		ORDER BY study_date`, userID)
+	if err != nil {
+		return nil, err
+	}
+	studiedDates, err := pgx.CollectRows(rows, pgx.RowTo[string])
+	if err != nil {
+		return nil, err
+	}
+	streak := &models.StudyStreak{}
+	if len(studiedDates) == 0 {
+		return streak, nil
+	}
+	studied := make(map[string]bool, len(studiedDates))
+	for _, d := range studiedDates {
+		studied[d] = true
+	}
+
+	rows, err = db.Query(ctx, `
+		SELECT to_char(d, 'YYYY-MM-DD')
+		FROM vacations v, generate_series(v.start_date, v.end_date, interval '1 day') AS d
+		WHERE v.user_id = $1`, userID)
+	if err != nil {
+		return nil, err
+	}
+	vacationDates, err := pgx.CollectRows(rows, pgx.RowTo[string])
+	if err != nil {
+		return nil, err
+	}
+	onVacation := make(map[string]bool, len(vacationDates))
+	for _, d := range vacationDates {
+		onVacation[d] = true
+	}
+
+	// 从第一天学习到今天逐日计算连续天数
+	first, err := time.Parse("2006-01-02", studiedDates[0])
+	if err != nil {
+		return nil, err
+	}
+	todayDate := today.Date()
+	run := 0
+	for d := first; d.Format("2006-01-02") <= todayDate; d = d.AddDate(0, 0, 1) {
+		date := d.Format("2006-01-02")
+		switch {
+		case studied[date]:
+			run++
+		case onVacation[date], date == todayDate:
+		default:
+			run = 0
+		}
+		streak.Longest = max(streak.Longest, run)
+	}
+	streak.Current = run
+	streak.StudiedToday = studied[todayDate]
+	return streak, nil
+}
+
+// Retention returns the success rates of scheduled (non-cram) reviews over the last days study days.
+// Mature retention is the true retention rate, to be compared with the scheduler's target.
+func Retention(ctx context.Context, db *pgxpool.Pool, userID uuid.UUID, days int) (*models.RetentionStats, error) {
+	dates, err := analyticsDates(ctx, db, userID, days)
+	if err != nil {
+		return nil, err
+	}
+
+	stats := &models.RetentionStats{Days: days, TargetRetention: targetRecall}
+	var youngSuccesses, matureSuccesses, growthCount int
+	var growthSum float64
+	err = db.QueryRow(ctx, `
+		SELECT COALESCE(SUM(young_reviews), 0), COALESCE(SUM(young_successes), 0),
+		       COALESCE(SUM(mature_reviews), 0), COALESCE(SUM(mature_successes), 0),
+		       COALESCE(SUM(new_cards), 0),
+		       COALESCE(SUM(halflife_growth_sum), 0), COALESCE(SUM(halflife_growth_count), 0)
+		FROM review_daily_stats
+		WHERE user_id = $1 AND study_date >= $2`, userID, dates[0]).Scan(
+		&stats.YoungReviews, &youngSuccesses, &stats.MatureReviews, &matureSuccesses,
+		&stats.NewCards, &growthSum, &growthCount,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	stats.YoungRetention = ratio(float64(youngSuccesses), stats.YoungReviews)
+	stats.MatureRetention = ratio(float64(matureSuccesses), stats.MatureReviews)
+	stats.AverageHalflifeGrowth = ratio(growthSum, growthCount)
+	return stats, nil
+}
+
+// ratio returns sum/n, or nil when there is nothing to average.
+func ratio(sum float64, n int) *float64 {
+	if n == 0 {
+		return nil
+	}
+	r := sum / float64(n)
+	return &r
+}
+
+// TimeSpent returns the study time of each of the last days study days, oldest first.
+func TimeSpent(ctx context.Context, db *pgxpool.Pool, userID uuid.UUID, days int) ([]models.TimeSpentDay, error) {
+	dates, err := analyticsDates(ctx, db, userID, days)
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := db.Query(ctx, `
+		SELECT to_char(study_date, 'YYYY-MM-DD'), reviews, timed_reviews, time_spent_ms / 1000.0
+		FROM review_daily_stats
+		WHERE user_id = $1 AND study_date BETWEEN $2 AND $3`, userID, dates[0], dates[len(dates)-1])
+	if err != nil {
+		return nil, err
+	}
+	byDate := make(map[string]models.TimeSpentDay)
+	var day models.TimeSpentDay
+	_, err = pgx.ForEachRow(rows, []any{&day.Date, &day.Reviews, &day.TimedReviews, &day.Seconds}, func() error {
+		byDate[day.Date] = day
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	spent := make([]models.TimeSpentDay, len(dates))
+	for i, d := range dates {
+		spent[i] = byDate[d]
+		spent[i].Date = d
+	}
+	return spent, nil
+}
+
+// SourceMasteryBreakdown counts the meanings of every vocabulary source the user has started by
+// progress: new, learning, mature, mastered or suspended. In word mode a meaning follows its word.
+func SourceMasteryBreakdown(ctx context.Context, db *pgxpool.Pool, userID uuid.UUID) ([]models.SourceMastery, error) {
+	query := `
+		SELECT w.source, COUNT(*),
+		       COUNT(*) FILTER (WHERE (up.meaning_id IS NULL OR ` + neverStudiedProgress + `)
+		                          AND COALESCE(up.card_state, 'active') IN ('active', 'buried')),
+		       COUNT(*) FILTER (WHERE NOT (` + neverStudiedProgress + `) AND up.card_state IN ('active', 'buried')
+		                          AND up.next_review_at - COALESCE(up.last_reviewed_at, now()) < $2 * interval '1 hour'),
+		       COUNT(*) FILTER (WHERE NOT (` + neverStudiedProgress + `) AND up.card_state IN ('active', 'buried')
+		                          AND up.next_review_at - COALESCE(up.last_reviewed_at, now()) >= $2 * interval '1 hour'),
+		       COUNT(*) FILTER (WHERE up.card_state = 'mastered'),
+		       COUNT(*) FILTER (WHERE up.card_state = 'suspended')
+		FROM meanings m
+		JOIN words w ON w.id = m.word_id
+		LEFT JOIN user_progress up ON up.user_id = $1 AND up.meaning_id = tracked_meaning_id($1, m.id)
+		WHERE w.source IS NOT NULL
+		GROUP BY w.source
+		HAVING COUNT(up.meaning_id) > 0
+		ORDER BY w.source
+	`
+	rows, err := db.Query(ctx, query, userID, MatureIntervalHours)
+	if err != nil {
+		return nil, err
+	}
+	return pgx.CollectRows(rows, pgx.RowToStructByPos[models.SourceMastery])
+}
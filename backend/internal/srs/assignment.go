@@ -0,0 +1,339 @@
+package srs
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"sentencease/backend/internal/database"
+	"sentencease/backend/internal/models"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// MaxAssignmentDays is the longest period from an assignment's start date to its due date.
+const MaxAssignmentDays = 366
+
+// MaxAssignmentSize is the largest number of meanings a single assignment may hold.
+const MaxAssignmentSize = 2000
+
+var (
+	// ErrAssignmentInvalidDates is returned for a due date before the start date or today, or too far out.
+	ErrAssignmentInvalidDates = errors.New("due date must be on or after the start date and today, and at most 366 days after the start date")
+	// ErrAssignmentEmpty is returned when the given source and unit contain no meanings.
+	ErrAssignmentEmpty = errors.New("no meanings found for the given source and unit")
+	// ErrAssignmentTooLarge is returned when the given source and unit hold more than MaxAssignmentSize meanings.
+	ErrAssignmentTooLarge = fmt.Errorf("an assignment can hold at most %d meanings; choose a unit", MaxAssignmentSize)
+)
+
+// assignmentColumns selects an assignment with its word count and, for $2, the completed words.
+var assignmentColumns = `
+	a.id, a.group_id, a.created_by, a.title,
+	to_char(a.start_date, 'YYYY-MM-DD'), to_char(a.due_date, 'YYYY-MM-DD'),
+	(SELECT COUNT(*) FROM assignment_words aw WHERE aw.assignment_id = a.id),
+	(SELECT COUNT(*) FROM assignment_words aw WHERE aw.assignment_id = a.id AND ` + hasStudied("$2", "aw.meaning_id") + `),
+	a.created_at
+`
+
+// scanAssignment scans assignmentColumns. The completed word count is only kept for students.
+func scanAssignment(student bool) pgx.RowToFunc[models.Assignment] {
+	return func(row pgx.CollectableRow) (models.Assignment, error) {
+		var a models.Assignment
+		var completed int
+		err := row.Scan(&a.ID, &a.GroupID, &a.CreatedBy, &a.Title, &a.StartDate, &a.DueDate, &a.TotalWords, &completed, &a.CreatedAt)
+		if student {
+			a.CompletedWords = &completed
+		}
+		return a, err
+	}
+}
+
+// ListAssignments returns the assignments of a group, latest due date first. For students the
+// completed word count of userID is included.
+func ListAssignments(ctx context.Context, db *pgxpool.Pool, groupID, userID uuid.UUID, student bool) ([]models.Assignment, error) {
+	rows, err := db.Query(ctx, `
+		SELECT `+assignmentColumns+` FROM assignments a
+		WHERE a.group_id = $1
+		ORDER BY a.due_date DESC, a.created_at DESC`, groupID, userID)
+	if err != nil {
+		return nil, err
+	}
+	return pgx.CollectRows(rows, scanAssignment(student))
+}
+
+// GetAssignment returns an assignment of a group, or database.ErrNotFound.
+func GetAssignment(ctx context.Context, db *pgxpool.Pool, groupID, assignmentID, userID uuid.UUID, student bool) (*models.Assignment, error) {
+	rows, err := db.Query(ctx, `
+		SELECT `+assignmentColumns+` FROM assignments a
+		WHERE a.group_id = $1 AND a.id = $3`, groupID, userID, assignmentID)
+	if err != nil {
+		return nil, err
+	}
+	a, err := pgx.CollectOneRow(rows, scanAssignment(student))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, database.ErrNotFound
+		}
+		return nil, err
+	}
+	return &a, nil
+}
+
+// CreateAssignment assigns meanings to the students of a group. meaningIDs must exist; when empty,
+// the meanings of source (and unit, if given) are assigned in unit order. The start date defaults to
+// the teacher's today. Students' daily plans are generated on their next learning request.
+func CreateAssignment(ctx context.Context, db *pgxpool.Pool, groupID, teacherID uuid.UUID, req models.CreateAssignmentRequest) (*models.Assignment, error) {
+	today, err := GetStudyDay(ctx, db, teacherID)
+	if err != nil {
+		return nil, err
+	}
+	startDate := today.Date()
+	if req.StartDate != nil {
+		startDate = *req.StartDate
+	}
+	start, err := time.Parse("2006-01-02", startDate)
+	if err != nil {
+		return nil, err
+	}
+	due, err := time.Parse("2006-01-02", req.DueDate)
+	if err != nil {
+		return nil, err
+	}
+	if due.Before(start) || req.DueDate < today.Date() || due.After(start.AddDate(0, 0, MaxAssignmentDays)) {
+		return nil, ErrAssignmentInvalidDates
+	}
+
+	tx, err := db.Begin(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback(ctx)
+
+	var assignmentID uuid.UUID
+	err = tx.QueryRow(ctx, `
+		INSERT INTO assignments (group_id, created_by, title, start_date, due_date)
+		VALUES ($1, $2, $3, $4, $5) RETURNING id`,
+		groupID, teacherID, req.Title, startDate, req.DueDate).Scan(&assignmentID)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(req.MeaningIDs) > 0 {
+		_, err = tx.Exec(ctx, `
+			INSERT INTO assignment_words (assignment_id, meaning_id, position)
+			SELECT $1, t.id, t.ord FROM unnest($2::int[]) WITH ORDINALITY AS t(id, ord)`,
+			assignmentID, req.MeaningIDs)
+		if err != nil {
+			return nil, err
+		}
+	} else {
+		tag, err := tx.Exec(ctx, `
+			INSERT INTO assignment_words (assignment_id, meaning_id, position)
+			SELECT $1, m.id, row_number() OVER (ORDER BY `+unitOrder+`)
+			FROM meanings m
+			JOIN words w ON w.id = m.word_id
+			WHERE w.source = $2 AND ($3::text IS NULL OR m.unit = $3)
+			LIMIT $4`,
+			assignmentID, req.Source, req.Unit, MaxAssignmentSize+1)
+		if err != nil {
+			return nil, err
+		}
+		if tag.RowsAffected() == 0 {
+			return nil, ErrAssignmentEmpty
+		}
+		if tag.RowsAffected() > MaxAssignmentSize {
+			return nil, ErrAssignmentTooLarge
+		}
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, err
+	}
+	return GetAssignment(ctx, db, groupID, assignmentID, teacherID, false)
+}
+
+// ensureAssignmentPlans generates today's daily plan for every open assignment of the groups in
+// which the user is a student.
+func ensureAssignmentPlans(ctx context.Context, db *pgxpool.Pool, userID uuid.UUID, day StudyDay) error {
+	rows, err := db.Query(ctx, `
+		SELECT a.id, to_char(a.due_date, 'YYYY-MM-DD')
+		FROM assignments a
+		JOIN study_group_members m ON m.group_id = a.group_id AND m.user_id = $1 AND m.role = $2
+		WHERE $3::date BETWEEN a.start_date AND a.due_date
+		ORDER BY a.due_date, a.created_at`, userID, models.GroupMember, day.Date())
+	if err != nil {
+		return err
+	}
+	type openAssignment struct {
+		id      uuid.UUID
+		dueDate string
+	}
+	open, err := pgx.CollectRows(rows, func(row pgx.CollectableRow) (openAssignment, error) {
+		var a openAssignment
+		err := row.Scan(&a.id, &a.dueDate)
+		return a, err
+	})
+	if err != nil {
+		return err
+	}
+
+	for _, a := range open {
+		if err := generateAssignmentPlan(ctx, db, userID, a.id, a.dueDate, day); err != nil {
+			log.Printf("Error generating daily plan from assignment %s for user %s: %v", a.id, userID, err)
+		}
+	}
+	return nil
+}
+
+// generateAssignmentPlan creates a student's daily plan for one assignment and study day. The words
+// not yet studied are spread evenly over the days left until the due date, so a missed day is
+// caught up over the remaining days; words planned on earlier days come first.
+func generateAssignmentPlan(ctx context.Context, db *pgxpool.Pool, userID, assignmentID uuid.UUID, dueDate string, day StudyDay) error {
+	tx, err := db.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	var exists bool
+	err = tx.QueryRow(ctx,
+		`SELECT EXISTS(SELECT 1 FROM daily_plans WHERE assignment_id = $1 AND user_id = $2 AND plan_date = $3)`,
+		assignmentID, userID, day.Date()).Scan(&exists)
+	if err != nil || exists {
+		return err
+	}
+
+	remainingQuery := `
+		SELECT meaning_id FROM (
+			SELECT tracked_meaning_id($2, aw.meaning_id) AS meaning_id, MIN(aw.position) AS position
+			FROM assignment_words aw
+			WHERE aw.assignment_id = $1 AND NOT ` + hasStudied("$2", "aw.meaning_id") + `
+			GROUP BY 1
+		) w
+		ORDER BY EXISTS (
+			SELECT 1 FROM daily_plans dp
+			JOIN daily_plan_words dpw ON dpw.plan_id = dp.id
+			WHERE dp.assignment_id = $1 AND dp.user_id = $2 AND dpw.meaning_id = w.meaning_id
+		) DESC, position
+	`
+	rows, err := tx.Query(ctx, remainingQuery, assignmentID, userID)
+	if err != nil {
+		return err
+	}
+	meaningIDs, err := pgx.CollectRows(rows, pgx.RowTo[int])
+	if err != nil || len(meaningIDs) == 0 {
+		return err
+	}
+
+	due, err := time.Parse("2006-01-02", dueDate)
+	if err != nil {
+		return err
+	}
+	today, err := time.Parse("2006-01-02", day.Date())
+	if err != nil {
+		return err
+	}
+	daysLeft := int(due.Sub(today).Hours()/24) + 1
+	quota := (len(meaningIDs) + daysLeft - 1) / daysLeft
+	meaningIDs = meaningIDs[:quota]
+
+	var planID uuid.UUID
+	err = tx.QueryRow(ctx,
+		`INSERT INTO daily_plans (user_id, assignment_id, plan_date) VALUES ($1, $2, $3)
+		 ON CONFLICT (assignment_id, user_id, plan_date) DO NOTHING
+		 RETURNING id`,
+		userID, assignmentID, day.Date()).Scan(&planID)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			// A concurrent request generated the plan first.
+			return nil
+		}
+		return err
+	}
+
+	_, err = tx.Exec(ctx,
+		`INSERT INTO daily_plan_words (plan_id, meaning_id) SELECT $1, unnest($2::int[])`,
+		planID, meaningIDs)
+	if err != nil {
+		return err
+	}
+
+	log.Printf("Generated daily plan %s for user %s from assignment %s: %d words, %d days left",
+		planID, userID, assignmentID, len(meaningIDs), daysLeft)
+	return tx.Commit(ctx)
+}
+
+// AssignmentDashboard returns the progress of every student of the group on an assignment: the
+// assigned meanings studied, and the retention of scheduled reviews of those meanings.
+func AssignmentDashboard(ctx context.Context, db *pgxpool.Pool, groupID, assignmentID, teacherID uuid.UUID) (*models.AssignmentDashboard, error) {
+	assignment, err := GetAssignment(ctx, db, groupID, assignmentID, teacherID, false)
+	if err != nil {
+		return nil, err
+	}
+	today, err := GetStudyDay(ctx, db, teacherID)
+	if err != nil {
+		return nil, err
+	}
+	pastDue := today.Date() > assignment.DueDate
+
+	query := `
+		SELECT m.user_id, COALESCE(NULLIF(u.display_name, ''), 'Learner'),
+		       (SELECT COUNT(*) FROM assignment_words aw
+		        WHERE aw.assignment_id = $1 AND ` + hasStudied("m.user_id", "aw.meaning_id") + `),
+		       r.reviews, r.scheduled, r.successes, r.last_reviewed_at
+		FROM study_group_members m
+		JOIN users u ON u.id = m.user_id
+		CROSS JOIN LATERAL (
+			SELECT COUNT(*) AS reviews,
+			       COUNT(*) FILTER (WHERE rl.elapsed_hours IS NOT NULL) AS scheduled,
+			       COUNT(*) FILTER (WHERE rl.elapsed_hours IS NOT NULL AND rl.success) AS successes,
+			       MAX(rl.reviewed_at) AS last_reviewed_at
+			FROM review_log rl
+			WHERE rl.user_id = m.user_id AND NOT rl.is_cram
+			  AND tracked_meaning_id(m.user_id, rl.meaning_id) IN (
+				SELECT tracked_meaning_id(m.user_id, aw.meaning_id) FROM assignment_words aw WHERE aw.assignment_id = $1
+			  )
+		) r
+		WHERE m.group_id = $2 AND m.role = $3
+		ORDER BY 2, m.user_id
+	`
+	rows, err := db.Query(ctx, query, assignmentID, groupID, models.GroupMember)
+	if err != nil {
+		return nil, err
+	}
+	students, err := pgx.CollectRows(rows, func(row pgx.CollectableRow) (models.AssignmentStudentProgress, error) {
+		s := models.AssignmentStudentProgress{TotalWords: assignment.TotalWords}
+		var scheduled, successes int
+		err := row.Scan(&s.UserID, &s.DisplayName, &s.CompletedWords, &s.Reviews, &scheduled, &successes, &s.LastReviewedAt)
+		if s.TotalWords > 0 {
+			s.Completion = float64(s.CompletedWords) / float64(s.TotalWords)
+		}
+		s.Retention = ratio(float64(successes), scheduled)
+		s.Overdue = pastDue && s.CompletedWords < s.TotalWords
+		return s, err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	dashboard := &models.AssignmentDashboard{Assignment: *assignment, Students: students}
+	var completionSum, retentionSum float64
+	var retentionCount int
+	for _, s := range students {
+		if s.CompletedWords >= s.TotalWords {
+			dashboard.CompletedStudents++
+		}
+		completionSum += s.Completion
+		if s.Retention != nil {
+			retentionSum += *s.Retention
+			retentionCount++
+		}
+	}
+	dashboard.AverageCompletion = ratio(completionSum, len(students))
+	dashboard.AverageRetention = ratio(retentionSum, retentionCount)
+	return dashboard, nil
+}
@@ -0,0 +1,73 @@
+package srs
+
+import (
+	"context"
+	"time"
+
+	"sentencease/backend/internal/models"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// RescheduleBacklog spreads the user's overdue cards evenly over the next days study days, starting
+// today. The most overdue cards stay first; cards moved to a later day become due when it starts.
+func RescheduleBacklog(ctx context.Context, db *pgxpool.Pool, userID uuid.UUID, days int) (*models.BacklogReschedule, error) {
+	today, err := GetStudyDay(ctx, db, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	tx, err := db.Begin(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback(ctx)
+
+	rows, err := tx.Query(ctx, `
+		SELECT up.meaning_id FROM user_progress up
+		WHERE up.user_id = $1 AND up.next_review_at <= now() AND `+SchedulableCard+`
+		ORDER BY up.next_review_at, up.meaning_id
+		FOR UPDATE`, userID)
+	if err != nil {
+		return nil, err
+	}
+	meaningIDs, err := pgx.CollectRows(rows, pgx.RowTo[int])
+	if err != nil {
+		return nil, err
+	}
+
+	result := &models.BacklogReschedule{Rescheduled: len(meaningIDs), Days: make([]models.BacklogDay, days)}
+	for i := range result.Days {
+		result.Days[i].Date = today.AddDays(i).Date()
+	}
+
+	// 第 i 张卡片分到第 i*days/n 天，今天的卡片保持原到期时间
+	var moved []int
+	var dueAt []time.Time
+	for i, meaningID := range meaningIDs {
+		d := i * days / len(meaningIDs)
+		result.Days[d].Cards++
+		if d > 0 {
+			moved = append(moved, meaningID)
+			dueAt = append(dueAt, today.AddDays(d).Start)
+		}
+	}
+
+	if len(moved) > 0 {
+		_, err = tx.Exec(ctx, `
+			UPDATE user_progress up SET next_review_at = v.due
+			FROM unnest($2::int[], $3::timestamptz[]) AS v(meaning_id, due)
+			WHERE up.user_id = $1 AND up.meaning_id = v.meaning_id`,
+			userID, moved, dueAt)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
@@ -0,0 +1,196 @@
+package srs
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"sort"
+	"time"
+
+	"sentencease/backend/internal/models"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// DefaultCalibrationBins is the number of calibration curve buckets when none is given.
+const DefaultCalibrationBins = 10
+
+// calibrationEpsilon keeps log-loss finite for predictions of exactly 0 or 1.
+const calibrationEpsilon = 1e-6
+
+// difficultyBands are the upper bounds of the difficulty bands in the report.
+var difficultyBands = []float64{0.25, 0.5, 0.75}
+
+// reviewCountBands are the lower bounds of the earlier-review-count bands in the report.
+var reviewCountBands = []int{1, 2, 3, 5, 10}
+
+// calibrationAcc accumulates predictions and outcomes for one group.
+type calibrationAcc struct {
+	n          int
+	sumP       float64
+	successes  int
+	brier      float64
+	logLoss    float64
+	binN       []int
+	binSumP    []float64
+	binSuccess []int
+}
+
+func newCalibrationAcc(bins int) *calibrationAcc {
+	return &calibrationAcc{binN: make([]int, bins), binSumP: make([]float64, bins), binSuccess: make([]int, bins)}
+}
+
+func (a *calibrationAcc) add(p float64, success bool) {
+	y := 0.0
+	if success {
+		y = 1
+		a.successes++
+	}
+	a.n++
+	a.sumP += p
+	a.brier += (p - y) * (p - y)
+	q := math.Min(math.Max(p, calibrationEpsilon), 1-calibrationEpsilon)
+	a.logLoss -= y*math.Log(q) + (1-y)*math.Log(1-q)
+
+	bin := min(int(p*float64(len(a.binN))), len(a.binN)-1)
+	a.binN[bin]++
+	a.binSumP[bin] += p
+	if success {
+		a.binSuccess[bin]++
+	}
+}
+
+func (a *calibrationAcc) group(name string) models.CalibrationGroup {
+	g := models.CalibrationGroup{Name: name, Reviews: a.n, Curve: make([]models.CalibrationBin, len(a.binN))}
+	if a.n > 0 {
+		g.MeanPredicted = a.sumP / float64(a.n)
+		g.SuccessRate = float64(a.successes) / float64(a.n)
+		g.BrierScore = a.brier / float64(a.n)
+		g.LogLoss = a.logLoss / float64(a.n)
+	}
+	width := 1 / float64(len(a.binN))
+	for i := range a.binN {
+		g.Curve[i] = models.CalibrationBin{
+			Low:           float64(i) * width,
+			High:          float64(i+1) * width,
+			Reviews:       a.binN[i],
+			MeanPredicted: ratio(a.binSumP[i], a.binN[i]),
+			SuccessRate:   ratio(float64(a.binSuccess[i]), a.binN[i]),
+		}
+	}
+	return g
+}
+
+// difficultyBand names the difficulty band of a meaning difficulty in [0, 1].
+func difficultyBand(d float64) string {
+	low := 0.0
+	for _, high := range difficultyBands {
+		if d < high {
+			return fmt.Sprintf("%.2f-%.2f", low, high)
+		}
+		low = high
+	}
+	return fmt.Sprintf("%.2f-1.00", low)
+}
+
+// reviewCountBand names the band of the number of earlier reviews of a card.
+func reviewCountBand(n int) string {
+	for i := len(reviewCountBands) - 1; i >= 0; i-- {
+		low := reviewCountBands[i]
+		if n < low {
+			continue
+		}
+		switch {
+		case i == len(reviewCountBands)-1:
+			return fmt.Sprintf("%d+", low)
+		case reviewCountBands[i+1]-1 == low:
+			return fmt.Sprintf("%d", low)
+		default:
+			return fmt.Sprintf("%d-%d", low, reviewCountBands[i+1]-1)
+		}
+	}
+	return "0"
+}
+
+// Calibration buckets past scheduled reviews by the recall probability calculateRecallProbability
+// predicted at review time (from the elapsed time and the half-life before the review) and compares
+// them with the actual outcomes. since limits the report to reviews after that time when not nil.
+// Only 认识 counts as a successful recall.
+func Calibration(ctx context.Context, db *pgxpool.Pool, since *time.Time, bins int) (*models.CalibrationReport, error) {
+	if bins <= 0 {
+		bins = DefaultCalibrationBins
+	}
+
+	// 按卡片统计复习序号（只计入影响调度的复习），再筛选出有预测值的记录
+	query := `
+		SELECT algorithm, difficulty, prior_reviews, elapsed_hours, memory_halflife, success
+		FROM (
+			SELECT COALESCE(rl.algorithm, 'unknown') AS algorithm,
+			       COALESCE(m.difficulty, $2) AS difficulty,
+			       row_number() OVER (PARTITION BY rl.user_id, rl.meaning_id ORDER BY rl.reviewed_at, rl.id) - 1 AS prior_reviews,
+			       rl.elapsed_hours, rl.memory_halflife, rl.success, rl.reviewed_at
+			FROM review_log rl
+			JOIN meanings m ON m.id = rl.meaning_id
+			WHERE NOT rl.is_cram
+		) r
+		WHERE elapsed_hours IS NOT NULL AND memory_halflife > 0
+		  AND ($1::timestamptz IS NULL OR reviewed_at >= $1)
+	`
+	rows, err := db.Query(ctx, query, since, defaultDifficulty)
+	if err != nil {
+		return nil, err
+	}
+
+	overall := newCalibrationAcc(bins)
+	groups := map[string]map[string]*calibrationAcc{"algorithm": {}, "difficulty": {}, "reviews": {}}
+	addTo := func(dimension, name string, p float64, success bool) {
+		acc, ok := groups[dimension][name]
+		if !ok {
+			acc = newCalibrationAcc(bins)
+			groups[dimension][name] = acc
+		}
+		acc.add(p, success)
+	}
+
+	var algorithm string
+	var difficulty, elapsedHours, halflife float64
+	var priorReviews int
+	var success bool
+	_, err = pgx.ForEachRow(rows, []any{&algorithm, &difficulty, &priorReviews, &elapsedHours, &halflife, &success}, func() error {
+		p := calculateRecallProbability(elapsedHours, halflife)
+		overall.add(p, success)
+		addTo("algorithm", algorithm, p, success)
+		addTo("difficulty", difficultyBand(difficulty), p, success)
+		addTo("reviews", reviewCountBand(priorReviews), p, success)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	sorted := func(dimension string) []models.CalibrationGroup {
+		result := make([]models.CalibrationGroup, 0, len(groups[dimension]))
+		for name, acc := range groups[dimension] {
+			result = append(result, acc.group(name))
+		}
+		sort.Slice(result, func(i, j int) bool { return result[i].Name < result[j].Name })
+		return result
+	}
+
+	report := &models.CalibrationReport{
+		Since:        since,
+		Overall:      overall.group("all"),
+		ByAlgorithm:  sorted("algorithm"),
+		ByDifficulty: sorted("difficulty"),
+	}
+	// 复习次数按数值排序，而不是按字符串
+	report.ByReviewCount = sorted("reviews")
+	sort.SliceStable(report.ByReviewCount, func(i, j int) bool {
+		var a, b int
+		fmt.Sscanf(report.ByReviewCount[i].Name, "%d", &a)
+		fmt.Sscanf(report.ByReviewCount[j].Name, "%d", &b)
+		return a < b
+	})
+	return report, nil
+}
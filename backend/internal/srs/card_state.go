@@ -0,0 +1,156 @@
+package srs
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"sentencease/backend/internal/database"
+	"sentencease/backend/internal/models"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// SchedulableCard matches progress rows (alias up, NULL for never-studied meanings when LEFT JOINed)
+// that the scheduler may show now: active cards and buried cards whose bury has expired.
+const SchedulableCard = `(up.card_state IS NULL OR up.card_state = 'active' OR (up.card_state = 'buried' AND up.buried_until <= now()))`
+
+// neverStudiedProgress matches progress rows created only to hold a card state: the meaning has
+// never been reviewed. Placement tests start known words at a later stage, so they don't match.
+const neverStudiedProgress = `up.last_reviewed_at IS NULL AND up.srs_stage = 0`
+
+// reviewedCardState is the card state after a review: reviewing a buried card lifts the bury,
+// other states are kept.
+const reviewedCardState = `CASE WHEN user_progress.card_state = 'buried' THEN 'active' ELSE user_progress.card_state END`
+
+// hasStudied returns a condition that is true when the user's progress takes the meaning out of the
+// new-word pool. A never-studied word that was only buried for the day still counts as new.
+// In word mode, progress on any meaning of the word counts.
+func hasStudied(userParam, meaningColumn string) string {
+	return `EXISTS (
+		SELECT 1 FROM user_progress up
+		WHERE up.user_id = ` + userParam + ` AND up.meaning_id = tracked_meaning_id(` + userParam + `, ` + meaningColumn + `)
+		  AND NOT (up.card_state = 'buried' AND ` + neverStudiedProgress + `)
+	)`
+}
+
+// EffectiveCardState returns the state a card is in now: an expired bury is active again.
+func EffectiveCardState(state *string, buriedUntil *time.Time) string {
+	switch {
+	case state == nil:
+		return models.CardActive
+	case *state == models.CardBuried && (buriedUntil == nil || !buriedUntil.After(time.Now())):
+		return models.CardActive
+	default:
+		return *state
+	}
+}
+
+// SetCardState changes the card state of a meaning. Burying skips the card until the start of the
+// next study day. Restoring a card that was never studied makes it a new word again.
+// In word mode the state applies to the whole word.
+func SetCardState(ctx context.Context, db *pgxpool.Pool, userID uuid.UUID, meaningID int, state string) (*models.CardState, error) {
+	if err := db.QueryRow(ctx, `SELECT tracked_meaning_id($1, $2)`, userID, meaningID).Scan(&meaningID); err != nil {
+		return nil, err
+	}
+
+	tx, err := db.Begin(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback(ctx)
+
+	if state == models.CardActive {
+		_, err = tx.Exec(ctx,
+			`DELETE FROM user_progress up WHERE up.user_id = $1 AND up.meaning_id = $2 AND `+neverStudiedProgress,
+			userID, meaningID)
+		if err != nil {
+			return nil, err
+		}
+		_, err = tx.Exec(ctx, `
+			UPDATE user_progress SET card_state = $3, buried_until = NULL, state_changed_at = now()
+			WHERE user_id = $1 AND meaning_id = $2`, userID, meaningID, state)
+		if err != nil {
+			return nil, err
+		}
+	} else {
+		var buriedUntil *time.Time
+		if state == models.CardBuried {
+			day, err := GetStudyDay(ctx, db, userID)
+			if err != nil {
+				return nil, err
+			}
+			buriedUntil = &day.End
+		}
+
+		_, err = tx.Exec(ctx, `
+			INSERT INTO user_progress (user_id, meaning_id, card_state, buried_until, state_changed_at)
+			VALUES ($1, $2, $3, $4, now())
+			ON CONFLICT (user_id, meaning_id) DO UPDATE SET
+				card_state = EXCLUDED.card_state,
+				buried_until = EXCLUDED.buried_until,
+				state_changed_at = EXCLUDED.state_changed_at`,
+			userID, meaningID, state, buriedUntil)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, err
+	}
+	return GetCardState(ctx, db, userID, meaningID)
+}
+
+// GetCardState returns the card state of a meaning. Meanings without progress are active.
+func GetCardState(ctx context.Context, db *pgxpool.Pool, userID uuid.UUID, meaningID int) (*models.CardState, error) {
+	rows, err := db.Query(ctx, `
+		SELECT m.id, w.lemma, m.definition, up.card_state, up.buried_until, up.state_changed_at
+		FROM meanings m
+		JOIN words w ON w.id = m.word_id
+		LEFT JOIN user_progress up ON up.meaning_id = m.id AND up.user_id = $1
+		WHERE m.id = $2`, userID, meaningID)
+	if err != nil {
+		return nil, err
+	}
+	card, err := pgx.CollectOneRow(rows, scanCardState)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, database.ErrNotFound
+		}
+		return nil, err
+	}
+	return &card, nil
+}
+
+// ListCards returns the user's cards currently in the given state (suspended, buried or mastered),
+// most recently changed first.
+func ListCards(ctx context.Context, db *pgxpool.Pool, userID uuid.UUID, state string) ([]models.CardState, error) {
+	query := `
+		SELECT m.id, w.lemma, m.definition, up.card_state, up.buried_until, up.state_changed_at
+		FROM user_progress up
+		JOIN meanings m ON m.id = up.meaning_id
+		JOIN words w ON w.id = m.word_id
+		WHERE up.user_id = $1 AND up.card_state = $2
+		  AND (up.card_state <> 'buried' OR up.buried_until > now())
+		ORDER BY up.state_changed_at DESC NULLS LAST, m.id
+	`
+	rows, err := db.Query(ctx, query, userID, state)
+	if err != nil {
+		return nil, err
+	}
+	return pgx.CollectRows(rows, scanCardState)
+}
+
+func scanCardState(row pgx.CollectableRow) (models.CardState, error) {
+	var card models.CardState
+	var state *string
+	err := row.Scan(&card.MeaningID, &card.Lemma, &card.Definition, &state, &card.BuriedUntil, &card.StateChangedAt)
+	card.State = EffectiveCardState(state, card.BuriedUntil)
+	if card.State != models.CardBuried {
+		card.BuriedUntil = nil
+	}
+	return card, err
+}
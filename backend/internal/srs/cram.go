@@ -0,0 +1,202 @@
+package srs
+
+import (
+	"context"
+	"errors"
+
+	"sentencease/backend/internal/database"
+	"sentencease/backend/internal/models"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// defaultCramSessionSize is the number of cards in a cram session when no limit is given.
+const defaultCramSessionSize = 100
+
+var (
+	// ErrCramSessionComplete is returned when every card of a cram session has been answered 认识.
+	ErrCramSessionComplete = errors.New("cram session complete")
+	// ErrCramWordNotInSession is returned when a review names a meaning outside the session.
+	ErrCramWordNotInSession = errors.New("meaning is not part of this cram session")
+)
+
+// StartCramSession creates a custom study session from the meanings matching all given filters,
+// in random order. It returns database.ErrNotFound if no meaning matches.
+func StartCramSession(ctx context.Context, db *pgxpool.Pool, userID uuid.UUID, req models.CreateCramSessionRequest) (*models.CramSession, error) {
+	limit := req.Limit
+	if limit == 0 {
+		limit = defaultCramSessionSize
+	}
+
+	tx, err := db.Begin(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback(ctx)
+
+	var sessionID uuid.UUID
+	err = tx.QueryRow(ctx, `
+		INSERT INTO cram_sessions (user_id, source, unit, tag, failed_within_days)
+		VALUES ($1, $2, $3, $4, $5) RETURNING id`,
+		userID, req.Source, req.Unit, req.Tag, req.FailedWithinDays).Scan(&sessionID)
+	if err != nil {
+		return nil, err
+	}
+
+	// In word mode every meaning is replaced by the meaning that tracks its word.
+	query := `
+		WITH candidates AS (
+			SELECT DISTINCT tracked_meaning_id($1, m.id) AS meaning_id
+			FROM meanings m
+			JOIN words w ON w.id = m.word_id
+			WHERE ($2::text IS NULL OR w.source = $2)
+			  AND ($3::text IS NULL OR m.unit = $3)
+			  AND ($4::text IS NULL OR ($4 = 'leech' AND EXISTS (
+				SELECT 1 FROM user_progress up
+				WHERE up.user_id = $1 AND up.meaning_id = tracked_meaning_id($1, m.id) AND up.is_leech
+			  )))
+			  AND ($5::int IS NULL OR EXISTS (
+				SELECT 1 FROM review_log rl
+				WHERE rl.user_id = $1 AND rl.meaning_id = tracked_meaning_id($1, m.id)
+				  AND NOT rl.success AND rl.reviewed_at >= now() - $5 * interval '1 day'
+			  ))
+		),
+		picked AS (
+			SELECT meaning_id FROM candidates ORDER BY random() LIMIT $7
+		)
+		INSERT INTO cram_session_words (session_id, meaning_id, position)
+		SELECT $6, meaning_id, row_number() OVER (ORDER BY random()) FROM picked
+	`
+	tag, err := tx.Exec(ctx, query, userID, req.Source, req.Unit, req.Tag, req.FailedWithinDays, sessionID, limit)
+	if err != nil {
+		return nil, err
+	}
+	if tag.RowsAffected() == 0 {
+		return nil, database.ErrNotFound
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, err
+	}
+	return GetCramSession(ctx, db, userID, sessionID)
+}
+
+// GetCramSession returns a cram session of the user with its progress.
+func GetCramSession(ctx context.Context, db *pgxpool.Pool, userID, sessionID uuid.UUID) (*models.CramSession, error) {
+	query := `
+		SELECT cs.id, cs.source, cs.unit, cs.tag, cs.failed_within_days, cs.created_at, cs.finished_at,
+		       COUNT(csw.meaning_id), COUNT(*) FILTER (WHERE csw.done), COALESCE(SUM(csw.attempts), 0)
+		FROM cram_sessions cs
+		LEFT JOIN cram_session_words csw ON csw.session_id = cs.id
+		WHERE cs.id = $1 AND cs.user_id = $2
+		GROUP BY cs.id
+	`
+	var s models.CramSession
+	err := db.QueryRow(ctx, query, sessionID, userID).Scan(
+		&s.ID, &s.Source, &s.Unit, &s.Tag, &s.FailedWithinDays, &s.CreatedAt, &s.FinishedAt,
+		&s.TotalCards, &s.DoneCards, &s.Attempts,
+	)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, database.ErrNotFound
+		}
+		return nil, err
+	}
+	return &s, nil
+}
+
+// NextCramCard returns the next card of a cram session. Cards not yet answered come first, then
+// cards answered 模糊/不认识, least recently answered first. When every card is done the session is
+// marked finished and ErrCramSessionComplete is returned.
+func NextCramCard(ctx context.Context, db *pgxpool.Pool, userID, sessionID uuid.UUID) (*models.WordReviewCard, error) {
+	var owned bool
+	err := db.QueryRow(ctx,
+		`SELECT EXISTS(SELECT 1 FROM cram_sessions WHERE id = $1 AND user_id = $2)`, sessionID, userID).Scan(&owned)
+	if err != nil {
+		return nil, err
+	}
+	if !owned {
+		return nil, database.ErrNotFound
+	}
+
+	var contextualMeaning models.Meaning
+	err = db.QueryRow(ctx, `
+		SELECT m.id, m.word_id, m.part_of_speech, m.definition, m.example_sentence, m.example_sentence_translation, w.lemma
+		FROM cram_session_words csw
+		JOIN meanings m ON m.id = csw.meaning_id
+		JOIN words w ON w.id = m.word_id
+		WHERE csw.session_id = $1 AND NOT csw.done
+		ORDER BY csw.last_answered_at NULLS FIRST, csw.position
+		LIMIT 1`, sessionID).Scan(
+		&contextualMeaning.ID, &contextualMeaning.WordID, &contextualMeaning.PartOfSpeech, &contextualMeaning.Definition,
+		&contextualMeaning.ExampleSentence, &contextualMeaning.ExampleSentenceTranslation, &contextualMeaning.Lemma,
+	)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			_, err = db.Exec(ctx,
+				`UPDATE cram_sessions SET finished_at = now() WHERE id = $1 AND finished_at IS NULL`, sessionID)
+			if err != nil {
+				return nil, err
+			}
+			return nil, ErrCramSessionComplete
+		}
+		return nil, err
+	}
+	return buildWordReviewCard(ctx, db, &contextualMeaning)
+}
+
+// RecordCramReview records an answer in a cram session. The review is written to review_log
+// flagged as cram; user_progress and scheduling are left untouched.
+func RecordCramReview(ctx context.Context, db *pgxpool.Pool, userID, sessionID uuid.UUID, meaningID int, userChoice string, responseMs *int) (*models.CramSession, error) {
+	tx, err := db.Begin(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback(ctx)
+
+	var owned bool
+	err = tx.QueryRow(ctx,
+		`SELECT EXISTS(SELECT 1 FROM cram_sessions WHERE id = $1 AND user_id = $2)`, sessionID, userID).Scan(&owned)
+	if err != nil {
+		return nil, err
+	}
+	if !owned {
+		return nil, database.ErrNotFound
+	}
+
+	if err := tx.QueryRow(ctx, `SELECT tracked_meaning_id($1, $2)`, userID, meaningID).Scan(&meaningID); err != nil {
+		return nil, err
+	}
+
+	tag, err := tx.Exec(ctx, `
+		UPDATE cram_session_words SET
+			attempts = attempts + 1,
+			done = done OR $3,
+			last_answered_at = now()
+		WHERE session_id = $1 AND meaning_id = $2`, sessionID, meaningID, userChoice == "认识")
+	if err != nil {
+		return nil, err
+	}
+	if tag.RowsAffected() == 0 {
+		return nil, ErrCramWordNotInSession
+	}
+
+	err = logReview(ctx, tx, reviewLogEntry{
+		UserID:        userID,
+		MeaningID:     meaningID,
+		UserChoice:    userChoice,
+		IsCram:        true,
+		CramSessionID: &sessionID,
+		ResponseMs:    responseMs,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, err
+	}
+	return GetCramSession(ctx, db, userID, sessionID)
+}
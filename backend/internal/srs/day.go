@@ -0,0 +1,89 @@
+package srs
+
+import (
+	"context"
+	"errors"
+	"log"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+)
+
+// DefaultDayStartHour is the local hour at which a new study day begins, like Anki's 4am rollover.
+const DefaultDayStartHour = 4
+
+// StudyDay is one of a user's study days expressed as a half-open range [Start, End) of absolute time.
+// All day-bucketed queries (daily plans, "reviewed today", ...) compare timestamps against this range
+// instead of the database server's current_date.
+type StudyDay struct {
+	Start    time.Time      `json:"start"`
+	End      time.Time      `json:"end"`
+	Location *time.Location `json:"-"`
+}
+
+// Date returns the calendar date of the study day in the user's timezone, e.g. "2024-07-22".
+func (d StudyDay) Date() string {
+	return d.Start.In(d.Location).Format("2006-01-02")
+}
+
+// Contains reports whether t falls within the study day.
+func (d StudyDay) Contains(t time.Time) bool {
+	return !t.Before(d.Start) && t.Before(d.End)
+}
+
+// AddDays returns the study day n days after d (or before, for negative n).
+func (d StudyDay) AddDays(n int) StudyDay {
+	start := d.Start.In(d.Location).AddDate(0, 0, n)
+	return StudyDay{Start: start, End: start.AddDate(0, 0, 1), Location: d.Location}
+}
+
+// StudyDayAt returns the study day containing t for a user in loc whose day begins at dayStartHour.
+// Days are computed with calendar arithmetic, so DST transitions yield 23 or 25 hour days.
+func StudyDayAt(t time.Time, loc *time.Location, dayStartHour int) StudyDay {
+	if loc == nil {
+		loc = time.UTC
+	}
+	if dayStartHour < 0 || dayStartHour > 23 {
+		dayStartHour = DefaultDayStartHour
+	}
+
+	local := t.In(loc)
+	start := time.Date(local.Year(), local.Month(), local.Day(), dayStartHour, 0, 0, 0, loc)
+	if local.Before(start) {
+		start = start.AddDate(0, 0, -1)
+	}
+
+	return StudyDay{Start: start, End: start.AddDate(0, 0, 1), Location: loc}
+}
+
+// GetUserDayConfig loads a user's timezone and day-start hour.
+// Unknown users and invalid zones fall back to UTC and DefaultDayStartHour.
+func GetUserDayConfig(ctx context.Context, db querier, userID uuid.UUID) (*time.Location, int, error) {
+	var tz string
+	var dayStartHour int
+	err := db.QueryRow(ctx, `SELECT timezone, day_start_hour FROM users WHERE id = $1`, userID).Scan(&tz, &dayStartHour)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return time.UTC, DefaultDayStartHour, nil
+		}
+		return nil, 0, err
+	}
+
+	loc, err := time.LoadLocation(tz)
+	if err != nil {
+		log.Printf("Invalid timezone %q for user %s, using UTC: %v", tz, userID, err)
+		loc = time.UTC
+	}
+
+	return loc, dayStartHour, nil
+}
+
+// GetStudyDay returns the user's current study day.
+func GetStudyDay(ctx context.Context, db querier, userID uuid.UUID) (StudyDay, error) {
+	loc, dayStartHour, err := GetUserDayConfig(ctx, db, userID)
+	if err != nil {
+		return StudyDay{}, err
+	}
+	return StudyDayAt(time.Now(), loc, dayStartHour), nil
+}
@@ -0,0 +1,42 @@
+package srs
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// DueToday returns the work waiting for the user on the study day: reviews that fall due before the
+// day ends, and words of the day's plan not studied yet. Daily plans are generated first, so the
+// count includes the words a study plan or assignment will add today. Vacation days have no work.
+func DueToday(ctx context.Context, db *pgxpool.Pool, userID uuid.UUID, day StudyDay) (reviews, newWords int, err error) {
+	vacation, err := onVacation(ctx, db, userID, day)
+	if err != nil || vacation {
+		return 0, 0, err
+	}
+	if err := EnsureDailyPlans(ctx, db, userID, day); err != nil {
+		return 0, 0, err
+	}
+
+	// 与 Forecast 相同的筛选条件：埋藏的卡片在解除埋藏后才算到期
+	err = db.QueryRow(ctx, `
+		SELECT COUNT(*) FROM user_progress up
+		WHERE up.user_id = $1 AND up.card_state IN ('active', 'buried')
+		  AND NOT (`+neverStudiedProgress+`)
+		  AND up.meaning_id = tracked_meaning_id($1, up.meaning_id)
+		  AND GREATEST(up.next_review_at, COALESCE(up.buried_until, up.next_review_at)) < $2
+	`, userID, day.End).Scan(&reviews)
+	if err != nil {
+		return 0, 0, err
+	}
+
+	err = db.QueryRow(ctx, `
+		WITH `+todaysPlanWordsCTE+`
+		SELECT COUNT(*) FROM todays_plan_words tpw
+		WHERE NOT `+hasStudied("$1", "tpw.meaning_id"), userID, day.Start, day.End).Scan(&newWords)
+	if err != nil {
+		return 0, 0, err
+	}
+	return reviews, newWords, nil
+}
@@ -0,0 +1,279 @@
+package srs
+
+import (
+	"context"
+	"errors"
+	"hash/fnv"
+	"math"
+	"time"
+
+	"sentencease/backend/internal/database"
+	"sentencease/backend/internal/models"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+var (
+	// ErrExperimentRunning is returned when starting an experiment while another one is running.
+	ErrExperimentRunning = errors.New("another experiment is already running")
+	// ErrExperimentNameTaken is returned when an experiment with the same name exists.
+	ErrExperimentNameTaken = errors.New("an experiment with this name already exists")
+)
+
+// UserScheduler is the scheduling configuration in effect for a user: the global algorithm, or the
+// algorithm and parameter overrides of the user's arm in the running experiment.
+type UserScheduler struct {
+	Algorithm    string
+	Params       models.SchedulerParams
+	ExperimentID *uuid.UUID
+	ArmID        *int
+}
+
+// GetUserScheduler returns the scheduling configuration for a user. While an experiment is running,
+// every user is assigned to one of its arms by hashing the experiment and user IDs, so the
+// assignment is stable without being stored.
+func GetUserScheduler(ctx context.Context, db *pgxpool.Pool, userID uuid.UUID) (UserScheduler, error) {
+	rows, err := db.Query(ctx, `
+		SELECT e.id, a.id, a.weight, a.algorithm, a.params
+		FROM experiments e
+		JOIN experiment_arms a ON a.experiment_id = e.id
+		WHERE e.status = $1
+		ORDER BY a.id`, models.ExperimentRunning)
+	if err != nil {
+		return UserScheduler{}, err
+	}
+	var experimentID uuid.UUID
+	var arms []models.ExperimentArm
+	var arm models.ExperimentArm
+	_, err = pgx.ForEachRow(rows, []any{&experimentID, &arm.ID, &arm.Weight, &arm.Algorithm, &arm.Params}, func() error {
+		arms = append(arms, arm)
+		return nil
+	})
+	if err != nil {
+		return UserScheduler{}, err
+	}
+
+	if len(arms) == 0 {
+		algorithm, err := GetSRSAlgorithm(ctx, db)
+		return UserScheduler{Algorithm: algorithm}, err
+	}
+
+	assigned := assignArm(experimentID, userID, arms)
+	return UserScheduler{
+		Algorithm:    assigned.Algorithm,
+		Params:       assigned.Params,
+		ExperimentID: &experimentID,
+		ArmID:        &assigned.ID,
+	}, nil
+}
+
+// assignArm picks the user's arm in proportion to the arm weights.
+func assignArm(experimentID, userID uuid.UUID, arms []models.ExperimentArm) models.ExperimentArm {
+	total := 0
+	for _, a := range arms {
+		total += a.Weight
+	}
+
+	h := fnv.New64a()
+	h.Write(experimentID[:])
+	h.Write(userID[:])
+	bucket := int(h.Sum64() % uint64(total))
+
+	for _, a := range arms {
+		if bucket < a.Weight {
+			return a
+		}
+		bucket -= a.Weight
+	}
+	return arms[len(arms)-1]
+}
+
+// optimalInterval is calculateOptimalInterval with the arm's target retention and maximum interval.
+func optimalInterval(halflife float64, params models.SchedulerParams) float64 {
+	target, maxHours := targetRecall, maxInterval
+	if params.TargetRetention != nil {
+		target = *params.TargetRetention
+	}
+	if params.MaxIntervalHours != nil {
+		maxHours = *params.MaxIntervalHours
+	}
+	return math.Min(math.Max(-halflife*math.Log2(target), minInterval), maxHours)
+}
+
+// modifyInterval applies the arm's interval modifier.
+func modifyInterval(interval time.Duration, params models.SchedulerParams) time.Duration {
+	if params.IntervalModifier == nil {
+		return interval
+	}
+	return time.Duration(float64(interval) * *params.IntervalModifier)
+}
+
+const experimentColumns = `id, name, description, status, created_at, stopped_at`
+
+func scanExperiment(row pgx.CollectableRow) (models.Experiment, error) {
+	var e models.Experiment
+	err := row.Scan(&e.ID, &e.Name, &e.Description, &e.Status, &e.CreatedAt, &e.StoppedAt)
+	e.Arms = []models.ExperimentArm{}
+	return e, err
+}
+
+// loadArms fills in the arms of the given experiments.
+func loadArms(ctx context.Context, db querier, experiments []models.Experiment) error {
+	index := make(map[uuid.UUID]int, len(experiments))
+	ids := make([]uuid.UUID, len(experiments))
+	for i, e := range experiments {
+		index[e.ID], ids[i] = i, e.ID
+	}
+
+	rows, err := db.Query(ctx, `
+		SELECT experiment_id, id, name, weight, algorithm, params
+		FROM experiment_arms WHERE experiment_id = ANY($1)
+		ORDER BY id`, ids)
+	if err != nil {
+		return err
+	}
+	var experimentID uuid.UUID
+	var arm models.ExperimentArm
+	_, err = pgx.ForEachRow(rows, []any{&experimentID, &arm.ID, &arm.Name, &arm.Weight, &arm.Algorithm, &arm.Params}, func() error {
+		e := &experiments[index[experimentID]]
+		e.Arms = append(e.Arms, arm)
+		return nil
+	})
+	return err
+}
+
+// ListExperiments returns all experiments, newest first.
+func ListExperiments(ctx context.Context, db *pgxpool.Pool) ([]models.Experiment, error) {
+	rows, err := db.Query(ctx, `SELECT `+experimentColumns+` FROM experiments ORDER BY created_at DESC`)
+	if err != nil {
+		return nil, err
+	}
+	experiments, err := pgx.CollectRows(rows, scanExperiment)
+	if err != nil {
+		return nil, err
+	}
+	return experiments, loadArms(ctx, db, experiments)
+}
+
+// GetExperiment returns an experiment with its arms.
+func GetExperiment(ctx context.Context, db *pgxpool.Pool, experimentID uuid.UUID) (*models.Experiment, error) {
+	rows, err := db.Query(ctx, `SELECT `+experimentColumns+` FROM experiments WHERE id = $1`, experimentID)
+	if err != nil {
+		return nil, err
+	}
+	e, err := pgx.CollectOneRow(rows, scanExperiment)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, database.ErrNotFound
+		}
+		return nil, err
+	}
+	experiments := []models.Experiment{e}
+	if err := loadArms(ctx, db, experiments); err != nil {
+		return nil, err
+	}
+	return &experiments[0], nil
+}
+
+// CreateExperiment starts an experiment. It returns ErrExperimentRunning if one is already running.
+func CreateExperiment(ctx context.Context, db *pgxpool.Pool, req models.CreateExperimentRequest) (*models.Experiment, error) {
+	tx, err := db.Begin(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback(ctx)
+
+	var experimentID uuid.UUID
+	err = tx.QueryRow(ctx,
+		`INSERT INTO experiments (name, description) VALUES ($1, $2) RETURNING id`,
+		req.Name, req.Description).Scan(&experimentID)
+	if err != nil {
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgErr.Code == "23505" {
+			if pgErr.ConstraintName == "idx_experiments_one_running" {
+				return nil, ErrExperimentRunning
+			}
+			return nil, ErrExperimentNameTaken
+		}
+		return nil, err
+	}
+
+	for _, arm := range req.Arms {
+		weight := arm.Weight
+		if weight == 0 {
+			weight = 1
+		}
+		_, err = tx.Exec(ctx, `
+			INSERT INTO experiment_arms (experiment_id, name, weight, algorithm, params)
+			VALUES ($1, $2, $3, $4, $5)`, experimentID, arm.Name, weight, arm.Algorithm, arm.Params)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, err
+	}
+	return GetExperiment(ctx, db, experimentID)
+}
+
+// StopExperiment stops a running experiment; all users return to the global algorithm.
+// Stopping an experiment that has already stopped does nothing.
+func StopExperiment(ctx context.Context, db *pgxpool.Pool, experimentID uuid.UUID) (*models.Experiment, error) {
+	_, err := db.Exec(ctx, `
+		UPDATE experiments SET status = $2, stopped_at = now()
+		WHERE id = $1 AND status = $3`, experimentID, models.ExperimentStopped, models.ExperimentRunning)
+	if err != nil {
+		return nil, err
+	}
+	return GetExperiment(ctx, db, experimentID)
+}
+
+// ExperimentReport compares retention and workload between the arms of an experiment, from the
+// scheduled (non-cram) reviews recorded while users were assigned to each arm. Reviews per user-day
+// count the days on which each user reviewed in their own study days.
+func ExperimentReport(ctx context.Context, db *pgxpool.Pool, experimentID uuid.UUID) (*models.ExperimentReport, error) {
+	experiment, err := GetExperiment(ctx, db, experimentID)
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := db.Query(ctx, `
+		SELECT a.id, a.name, a.algorithm,
+		       COUNT(DISTINCT rl.user_id),
+		       COUNT(rl.id),
+		       COUNT(rl.id) FILTER (WHERE rl.elapsed_hours IS NULL),
+		       COUNT(rl.id) FILTER (WHERE rl.elapsed_hours IS NOT NULL),
+		       COUNT(rl.id) FILTER (WHERE rl.elapsed_hours IS NOT NULL AND rl.success),
+		       COUNT(rl.id) FILTER (WHERE rl.elapsed_hours >= $2),
+		       COUNT(rl.id) FILTER (WHERE rl.elapsed_hours >= $2 AND rl.success),
+		       -- 按每个用户自己的学习日（时区与每日开始时间，同 StudyDayAt）统计活跃天数
+		       COUNT(DISTINCT (rl.user_id, (rl.reviewed_at AT TIME ZONE u.timezone - make_interval(hours => u.day_start_hour))::date))
+		FROM experiment_arms a
+		LEFT JOIN review_log rl ON rl.arm_id = a.id AND NOT rl.is_cram
+		LEFT JOIN users u ON u.id = rl.user_id
+		WHERE a.experiment_id = $1
+		GROUP BY a.id, a.name, a.algorithm
+		ORDER BY a.id`, experimentID, MatureIntervalHours)
+	if err != nil {
+		return nil, err
+	}
+	arms, err := pgx.CollectRows(rows, func(row pgx.CollectableRow) (models.ExperimentArmReport, error) {
+		var r models.ExperimentArmReport
+		var reviewed, successes, matureSuccesses, userDays int
+		err := row.Scan(&r.ArmID, &r.Name, &r.Algorithm, &r.Users, &r.Reviews, &r.NewCards,
+			&reviewed, &successes, &r.MatureReviews, &matureSuccesses, &userDays)
+		r.Retention = ratio(float64(successes), reviewed)
+		r.MatureRetention = ratio(float64(matureSuccesses), r.MatureReviews)
+		r.ReviewsPerUserDay = ratio(float64(r.Reviews), userDays)
+		return r, err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &models.ExperimentReport{Experiment: *experiment, Arms: arms}, nil
+}
@@ -0,0 +1,213 @@
+package srs
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	"sentencease/backend/internal/models"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// MaxForecastDays is the longest forecast horizon.
+const MaxForecastDays = 90
+
+// forecastCard is the scheduling state of one card in the workload simulation.
+type forecastCard struct {
+	halflife   float64
+	stage      int
+	difficulty float64
+}
+
+// forecaster buckets simulated reviews into the user's upcoming study days.
+type forecaster struct {
+	algorithm string
+	params    models.SchedulerParams
+	now       time.Time
+	starts    []time.Time // Start of each forecast day; starts[len-1] is the end of the horizon
+	days      []models.ForecastDay
+}
+
+// Forecast returns the review workload of the user's next n study days, starting today.
+//
+// DueReviews counts cards by their current next_review_at. The projection additionally assumes the
+// user does all of each day's work and answers every review 认识: each review is rescheduled with the
+// scheduler's interval for a successful recall, so reviews generated by earlier days (including the
+// first reviews of new words) are counted too. A card is reviewed at most once per study day, as in
+// daily plans. New words come from today's plan and the daily quota of active study plans.
+func Forecast(ctx context.Context, db *pgxpool.Pool, userID uuid.UUID, n int) ([]models.ForecastDay, error) {
+	today, err := GetStudyDay(ctx, db, userID)
+	if err != nil {
+		return nil, err
+	}
+	scheduler, err := GetUserScheduler(ctx, db, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	f := &forecaster{algorithm: scheduler.Algorithm, params: scheduler.Params, now: time.Now()}
+	for i := 0; i < n; i++ {
+		day := today.AddDays(i)
+		f.starts = append(f.starts, day.Start)
+		f.days = append(f.days, models.ForecastDay{Date: day.Date()})
+	}
+	f.starts = append(f.starts, today.AddDays(n).Start)
+	horizon := f.starts[n]
+
+	// 1. 已有卡片：按下次复习时间计数，并模拟之后的复习
+	query := `
+		SELECT GREATEST(up.next_review_at, COALESCE(up.buried_until, up.next_review_at)),
+		       COALESCE(up.memory_halflife, 4.0), up.srs_stage, COALESCE(m.difficulty, $3)
+		FROM user_progress up
+		JOIN meanings m ON m.id = up.meaning_id
+		WHERE up.user_id = $1 AND up.card_state IN ('active', 'buried')
+		  AND NOT (` + neverStudiedProgress + `)
+		  AND up.meaning_id = tracked_meaning_id($1, up.meaning_id)
+		  AND up.next_review_at < $2
+	`
+	rows, err := db.Query(ctx, query, userID, horizon, defaultDifficulty)
+	if err != nil {
+		return nil, err
+	}
+	var due time.Time
+	var card forecastCard
+	_, err = pgx.ForEachRow(rows, []any{&due, &card.halflife, &card.stage, &card.difficulty}, func() error {
+		idx := f.dayIndex(due)
+		if idx < n {
+			f.days[idx].DueReviews++
+			f.days[idx].ProjectedReviews++
+			f.simulate(card, f.reviewTime(due, idx), idx)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// 2. 新词：今天计划中尚未学习的词，以及各学习计划之后每天的配额
+	newPerDay, err := projectedNewWords(ctx, db, userID, today, n)
+	if err != nil {
+		return nil, err
+	}
+	for idx, count := range newPerDay {
+		f.days[idx].ProjectedNewCards += count
+		for i := 0; i < count; i++ {
+			f.simulate(forecastCard{halflife: minInterval, difficulty: defaultDifficulty}, f.reviewTime(f.starts[idx], idx), idx)
+		}
+	}
+
+	for i := range f.days {
+		f.days[i].ProjectedTotal = f.days[i].ProjectedNewCards + f.days[i].ProjectedReviews
+	}
+	return f.days, nil
+}
+
+// dayIndex returns the forecast day containing t; times before today count as today.
+func (f *forecaster) dayIndex(t time.Time) int {
+	return sort.Search(len(f.starts)-1, func(i int) bool { return t.Before(f.starts[i+1]) })
+}
+
+// reviewTime is when a card due at t is assumed to be reviewed on forecast day idx.
+func (f *forecaster) reviewTime(t time.Time, idx int) time.Time {
+	if idx == 0 && t.Before(f.now) {
+		return f.now
+	}
+	return t
+}
+
+// simulate counts the follow-up reviews of a card reviewed successfully at t on day idx.
+func (f *forecaster) simulate(card forecastCard, t time.Time, idx int) {
+	horizonDays := len(f.days)
+	for {
+		var interval time.Duration
+		if f.algorithm == "sspmmc" {
+			// Mirrors UpdateProgressWithSSPMMC for a 认识 answer.
+			card.halflife = calculateMemoryHalflife(card.difficulty, 1, 0, card.halflife)
+			interval = time.Duration(optimalInterval(card.halflife, f.params) * float64(time.Hour))
+		} else {
+			card.stage = calculateNextStage(card.stage, "认识")
+			interval = calculateNextInterval(card.stage)
+		}
+		interval = modifyInterval(interval, f.params)
+
+		next := t.Add(interval)
+		if idx+1 >= horizonDays {
+			return
+		}
+		if next.Before(f.starts[idx+1]) {
+			next = f.starts[idx+1]
+		}
+		idx = f.dayIndex(next)
+		if idx >= horizonDays {
+			return
+		}
+		f.days[idx].ProjectedReviews++
+		t = next
+	}
+}
+
+// projectedNewWords returns the number of new words expected on each of the next n study days:
+// words never studied in today's plan, plus the daily quota of every active study plan until its
+// source runs out. Study plans that already generated today's plan contribute through it.
+func projectedNewWords(ctx context.Context, db *pgxpool.Pool, userID uuid.UUID, today StudyDay, n int) ([]int, error) {
+	perDay := make([]int, n)
+
+	err := db.QueryRow(ctx, `
+		WITH `+todaysPlanWordsCTE+`
+		SELECT COUNT(*) FROM todays_plan_words tpw
+		WHERE NOT `+hasStudied("$1", "tpw.meaning_id"), userID, today.Start, today.End).Scan(&perDay[0])
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := db.Query(ctx,
+		`SELECT id, source, words_per_day, status, created_at, completed_at FROM study_plans WHERE user_id = $1 AND status = $2`,
+		userID, models.StudyPlanActive)
+	if err != nil {
+		return nil, err
+	}
+	plans, err := pgx.CollectRows(rows, func(row pgx.CollectableRow) (models.StudyPlan, error) {
+		var p models.StudyPlan
+		err := row.Scan(&p.ID, &p.Source, &p.WordsPerDay, &p.Status, &p.CreatedAt, &p.CompletedAt)
+		return p, err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	for _, plan := range plans {
+		if err := ProjectStudyPlan(ctx, db, userID, &plan, today); err != nil {
+			return nil, err
+		}
+
+		// Words of today's generated plan are already counted above.
+		var generated bool
+		var todaysNew int
+		err := db.QueryRow(ctx, `
+			SELECT EXISTS(SELECT 1 FROM daily_plans WHERE study_plan_id = $1 AND plan_date = $2),
+			       (SELECT COUNT(*) FROM daily_plans dp
+			        JOIN daily_plan_words dpw ON dpw.plan_id = dp.id
+			        WHERE dp.study_plan_id = $1 AND dp.plan_date = $2
+			          AND NOT `+hasStudied("$3", "dpw.meaning_id")+`)`,
+			plan.ID, today.Date(), userID).Scan(&generated, &todaysNew)
+		if err != nil {
+			return nil, err
+		}
+
+		remaining := plan.RemainingWords - todaysNew
+		first := 1
+		if !generated {
+			first = 0
+		}
+		for i := first; i < n && remaining > 0; i++ {
+			count := min(plan.WordsPerDay, remaining)
+			perDay[i] += count
+			remaining -= count
+		}
+	}
+
+	return perDay, nil
+}
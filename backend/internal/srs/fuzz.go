@@ -0,0 +1,142 @@
+package srs
+
+import (
+	"context"
+	"encoding/binary"
+	"hash/fnv"
+	"math/rand"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// SchedulingSettings are a user's options for adjusting computed review times.
+type SchedulingSettings struct {
+	IntervalFuzz  bool // Move each due date by a random amount within its fuzz window
+	LoadBalancing bool // Move each due date to the least loaded study day within its fuzz window
+	Location      *time.Location
+	DayStartHour  int
+}
+
+// GetSchedulingSettings loads a user's interval fuzz and load balancing options and study day config.
+func GetSchedulingSettings(ctx context.Context, db *pgxpool.Pool, userID uuid.UUID) (SchedulingSettings, error) {
+	settings := SchedulingSettings{IntervalFuzz: true}
+
+	loc, dayStartHour, err := GetUserDayConfig(ctx, db, userID)
+	if err != nil {
+		return settings, err
+	}
+	settings.Location, settings.DayStartHour = loc, dayStartHour
+
+	err = db.QueryRow(ctx, `SELECT interval_fuzz, load_balancing FROM users WHERE id = $1`, userID).
+		Scan(&settings.IntervalFuzz, &settings.LoadBalancing)
+	return settings, err
+}
+
+// fuzzWindow returns how far a due date may move either way for the given interval.
+// Intervals under an hour (relearning steps) are not fuzzed.
+func fuzzWindow(interval time.Duration) time.Duration {
+	day := 24 * time.Hour
+	switch {
+	case interval < time.Hour:
+		return 0
+	case interval < day:
+		return interval / 10
+	case interval < 7*day:
+		return interval * 15 / 100
+	case interval < 20*day:
+		return interval / 10
+	default:
+		return interval / 20
+	}
+}
+
+// FuzzInterval returns the interval moved by a uniformly random amount within its fuzz window.
+func FuzzInterval(interval time.Duration, r *rand.Rand) time.Duration {
+	w := fuzzWindow(interval)
+	if w <= 0 {
+		return interval
+	}
+	return interval - w + time.Duration(r.Int63n(int64(2*w)+1))
+}
+
+// BalanceDueDate returns the due date for a review at reviewedAt with the given interval, shifted
+// by whole days within the fuzz window to the study day with the fewest scheduled reviews.
+// load maps study dates (YYYY-MM-DD) to review counts. Ties are broken randomly, so cards learned
+// together spread over the equally loaded days. Windows shorter than a day fall back to FuzzInterval.
+func BalanceDueDate(reviewedAt time.Time, interval time.Duration, loc *time.Location, dayStartHour int, load map[string]int, r *rand.Rand) time.Time {
+	maxShift := int(fuzzWindow(interval) / (24 * time.Hour))
+	if maxShift == 0 {
+		return reviewedAt.Add(FuzzInterval(interval, r))
+	}
+
+	due := reviewedAt.Add(interval).In(loc)
+	var best []time.Time
+	bestLoad := -1
+	for k := -maxShift; k <= maxShift; k++ {
+		candidate := due.AddDate(0, 0, k)
+		l := load[StudyDayAt(candidate, loc, dayStartHour).Date()]
+		switch {
+		case bestLoad < 0 || l < bestLoad:
+			best, bestLoad = []time.Time{candidate}, l
+		case l == bestLoad:
+			best = append(best, candidate)
+		}
+	}
+	return best[r.Intn(len(best))]
+}
+
+// reviewRand returns the random source for one review. It is seeded from the user, meaning and
+// review count, so a given review always gets the same fuzz and tests are reproducible.
+func reviewRand(userID uuid.UUID, meaningID, reviewCount int) *rand.Rand {
+	h := fnv.New64a()
+	h.Write(userID[:])
+	var buf [16]byte
+	binary.BigEndian.PutUint64(buf[:8], uint64(meaningID))
+	binary.BigEndian.PutUint64(buf[8:], uint64(reviewCount))
+	h.Write(buf[:])
+	return rand.New(rand.NewSource(int64(h.Sum64())))
+}
+
+// adjustDueDate applies the user's fuzz and load balancing settings to a computed review time.
+func adjustDueDate(ctx context.Context, db querier, userID uuid.UUID, meaningID, reviewCount int, reviewedAt time.Time, interval time.Duration, settings SchedulingSettings) (time.Time, error) {
+	r := reviewRand(userID, meaningID, reviewCount)
+
+	window := fuzzWindow(interval)
+	switch {
+	case settings.LoadBalancing && window >= 24*time.Hour:
+		load, err := scheduledLoad(ctx, db, userID, meaningID,
+			reviewedAt.Add(interval-window-24*time.Hour), reviewedAt.Add(interval+window+24*time.Hour), settings)
+		if err != nil {
+			return time.Time{}, err
+		}
+		return BalanceDueDate(reviewedAt, interval, settings.Location, settings.DayStartHour, load, r), nil
+	case settings.IntervalFuzz || settings.LoadBalancing:
+		return reviewedAt.Add(FuzzInterval(interval, r)), nil
+	default:
+		return reviewedAt.Add(interval), nil
+	}
+}
+
+// scheduledLoad counts the user's other scheduled reviews per study day between from and to.
+func scheduledLoad(ctx context.Context, db querier, userID uuid.UUID, meaningID int, from, to time.Time, settings SchedulingSettings) (map[string]int, error) {
+	rows, err := db.Query(ctx, `
+		SELECT next_review_at FROM user_progress
+		WHERE user_id = $1 AND meaning_id <> $2 AND card_state IN ('active', 'buried')
+		  AND next_review_at >= $3 AND next_review_at < $4`, userID, meaningID, from, to)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	load := make(map[string]int)
+	for rows.Next() {
+		var due time.Time
+		if err := rows.Scan(&due); err != nil {
+			return nil, err
+		}
+		load[StudyDayAt(due, settings.Location, settings.DayStartHour).Date()]++
+	}
+	return load, rows.Err()
+}
@@ -0,0 +1,117 @@
+package srs
+
+import (
+	"math/rand"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const oneDay = 24 * time.Hour
+
+func TestFuzzIntervalRange(t *testing.T) {
+	tests := []struct {
+		name     string
+		interval time.Duration
+		min, max time.Duration
+	}{
+		{name: "relearning step", interval: 10 * time.Minute, min: 10 * time.Minute, max: 10 * time.Minute},
+		{name: "hours", interval: 10 * time.Hour, min: 9 * time.Hour, max: 11 * time.Hour},
+		{name: "days", interval: 4 * oneDay, min: 4*oneDay - 4*oneDay*15/100, max: 4*oneDay + 4*oneDay*15/100},
+		{name: "weeks", interval: 10 * oneDay, min: 9 * oneDay, max: 11 * oneDay},
+		{name: "months", interval: 100 * oneDay, min: 95 * oneDay, max: 105 * oneDay},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := rand.New(rand.NewSource(1))
+			seen := make(map[time.Duration]bool)
+			for range 1000 {
+				got := FuzzInterval(tt.interval, r)
+				if got < tt.min || got > tt.max {
+					t.Fatalf("FuzzInterval(%v) = %v, want within [%v, %v]", tt.interval, got, tt.min, tt.max)
+				}
+				seen[got] = true
+			}
+			if tt.min != tt.max && len(seen) < 2 {
+				t.Errorf("FuzzInterval(%v) never varied", tt.interval)
+			}
+		})
+	}
+}
+
+func TestReviewRandDeterministic(t *testing.T) {
+	userID := uuid.MustParse("9b2f6c1e-4d8a-4f3b-a1c2-0e5d7f9a3b61")
+	interval := 30 * oneDay
+
+	a := FuzzInterval(interval, reviewRand(userID, 42, 3))
+	b := FuzzInterval(interval, reviewRand(userID, 42, 3))
+	if a != b {
+		t.Fatalf("same review fuzzed differently: %v and %v", a, b)
+	}
+
+	// Other reviews get their own draw; across a few review counts at least one must differ.
+	differs := false
+	for count := 4; count < 10 && !differs; count++ {
+		differs = FuzzInterval(interval, reviewRand(userID, 42, count)) != a
+	}
+	if !differs {
+		t.Error("fuzz does not depend on the review count")
+	}
+}
+
+func TestBalanceDueDate(t *testing.T) {
+	loc := time.UTC
+	reviewedAt := time.Date(2026, 3, 1, 12, 0, 0, 0, loc)
+	interval := 100 * oneDay // window of 5 days each way
+	due := reviewedAt.Add(interval)
+	date := func(offset int) string {
+		return StudyDayAt(due.AddDate(0, 0, offset), loc, DefaultDayStartHour).Date()
+	}
+
+	tests := []struct {
+		name  string
+		load  map[string]int
+		wants []int // acceptable day offsets from the unbalanced due date
+	}{
+		{
+			name:  "single least loaded day",
+			load:  map[string]int{date(-5): 9, date(-4): 8, date(-3): 7, date(-2): 6, date(-1): 5, date(0): 5, date(1): 4, date(2): 3, date(3): 1, date(4): 2, date(5): 6},
+			wants: []int{3},
+		},
+		{
+			name:  "empty day outside the window is ignored",
+			load:  map[string]int{date(-6): 0, date(6): 0, date(-5): 2, date(-4): 2, date(-3): 2, date(-2): 2, date(-1): 2, date(0): 2, date(1): 2, date(2): 2, date(3): 2, date(4): 1, date(5): 2},
+			wants: []int{4},
+		},
+		{
+			name:  "tie between two days",
+			load:  map[string]int{date(-5): 3, date(-4): 3, date(-3): 0, date(-2): 3, date(-1): 3, date(0): 3, date(1): 3, date(2): 3, date(3): 3, date(4): 3, date(5): 0},
+			wants: []int{-3, 5},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for seed := range int64(20) {
+				got := BalanceDueDate(reviewedAt, interval, loc, DefaultDayStartHour, tt.load, rand.New(rand.NewSource(seed)))
+				ok := false
+				for _, offset := range tt.wants {
+					ok = ok || got.Equal(due.AddDate(0, 0, offset))
+				}
+				if !ok {
+					t.Fatalf("BalanceDueDate = %v (load %d), want one of offsets %v from %v",
+						got, tt.load[StudyDayAt(got, loc, DefaultDayStartHour).Date()], tt.wants, due)
+				}
+			}
+		})
+	}
+}
+
+func TestBalanceDueDateShortWindowFuzzes(t *testing.T) {
+	reviewedAt := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	interval := 3 * oneDay // window under a day: no whole-day shift possible
+	got := BalanceDueDate(reviewedAt, interval, time.UTC, DefaultDayStartHour, map[string]int{}, rand.New(rand.NewSource(1)))
+	if d := got.Sub(reviewedAt); d < interval-fuzzWindow(interval) || d > interval+fuzzWindow(interval) {
+		t.Errorf("BalanceDueDate moved a short interval to %v", d)
+	}
+}
@@ -0,0 +1,251 @@
+package srs
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	"sentencease/backend/internal/models"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// progressRetentionDays is the period of the retention rate in the group progress view.
+const progressRetentionDays = 30
+
+// weekOf returns the first and last study dates of the Monday-to-Sunday week containing day.
+func weekOf(day StudyDay) (string, string) {
+	offset := (int(day.Start.In(day.Location).Weekday()) + 6) % 7
+	monday := day.AddDays(-offset)
+	return monday.Date(), monday.AddDays(6).Date()
+}
+
+// groupWeekStats is one member's review totals for a week.
+type groupWeekStats struct {
+	userID      uuid.UUID
+	displayName string
+	role        string
+	joinedAt    time.Time
+	reviews     int
+	newWords    int
+}
+
+// groupWeek returns the review totals of the group's members for the given week. Each member's
+// rollups are bucketed by their own study days, so the week runs in every member's own timezone.
+// With leaderboardOnly, members who opted out of leaderboards are left out.
+func groupWeek(ctx context.Context, db *pgxpool.Pool, groupID uuid.UUID, weekStart, weekEnd string, leaderboardOnly bool) ([]groupWeekStats, error) {
+	rows, err := db.Query(ctx, `
+		SELECT m.user_id, COALESCE(NULLIF(u.display_name, ''), 'Learner'), m.role, m.joined_at,
+		       COALESCE(SUM(s.reviews), 0), COALESCE(SUM(s.new_cards), 0)
+		FROM study_group_members m
+		JOIN users u ON u.id = m.user_id
+		LEFT JOIN review_daily_stats s ON s.user_id = m.user_id AND s.study_date BETWEEN $2 AND $3
+		WHERE m.group_id = $1 AND (NOT $4 OR u.show_on_leaderboards)
+		GROUP BY m.user_id, u.display_name, m.role, m.joined_at
+		ORDER BY m.joined_at`, groupID, weekStart, weekEnd, leaderboardOnly)
+	if err != nil {
+		return nil, err
+	}
+	return pgx.CollectRows(rows, func(row pgx.CollectableRow) (groupWeekStats, error) {
+		var s groupWeekStats
+		err := row.Scan(&s.userID, &s.displayName, &s.role, &s.joinedAt, &s.reviews, &s.newWords)
+		return s, err
+	})
+}
+
+// GroupLeaderboard ranks the group's members by reviews done, new words learned or current streak
+// for the viewer's current week. Members who opted out of leaderboards are not listed.
+func GroupLeaderboard(ctx context.Context, db *pgxpool.Pool, groupID, viewerID uuid.UUID, metric string) (*models.Leaderboard, error) {
+	today, err := GetStudyDay(ctx, db, viewerID)
+	if err != nil {
+		return nil, err
+	}
+	weekStart, weekEnd := weekOf(today)
+
+	members, err := groupWeek(ctx, db, groupID, weekStart, weekEnd, true)
+	if err != nil {
+		return nil, err
+	}
+
+	var streaks map[uuid.UUID]int
+	if metric == models.LeaderboardStreak {
+		if streaks, err = groupStreaks(ctx, db, groupID); err != nil {
+			return nil, err
+		}
+	}
+
+	entries := make([]models.LeaderboardEntry, len(members))
+	for i, m := range members {
+		entries[i] = models.LeaderboardEntry{UserID: m.userID, DisplayName: m.displayName, IsYou: m.userID == viewerID}
+		switch metric {
+		case models.LeaderboardReviews:
+			entries[i].Value = m.reviews
+		case models.LeaderboardNewWords:
+			entries[i].Value = m.newWords
+		case models.LeaderboardStreak:
+			entries[i].Value = streaks[m.userID]
+		}
+	}
+
+	// 同分同名次（1, 2, 2, 4）
+	sort.SliceStable(entries, func(i, j int) bool { return entries[i].Value > entries[j].Value })
+	for i := range entries {
+		if i > 0 && entries[i].Value == entries[i-1].Value {
+			entries[i].Rank = entries[i-1].Rank
+		} else {
+			entries[i].Rank = i + 1
+		}
+	}
+
+	return &models.Leaderboard{Metric: metric, WeekStart: weekStart, WeekEnd: weekEnd, Entries: entries}, nil
+}
+
+// groupStreaks returns the current study streak of every member of the group, computed like
+// StudyStreaks in one query: study days and vacation days up to each member's own today are split
+// into runs of consecutive dates, and the streak is the number of study days in the last run.
+// Today is always part of the last run, so an unbroken streak up to yesterday is still current.
+func groupStreaks(ctx context.Context, db *pgxpool.Pool, groupID uuid.UUID) (map[uuid.UUID]int, error) {
+	rows, err := db.Query(ctx, `
+		WITH member AS (
+			SELECT m.user_id, (now() AT TIME ZONE u.timezone - make_interval(hours => u.day_start_hour))::date AS today
+			FROM study_group_members m
+			JOIN users u ON u.id = m.user_id
+			WHERE m.group_id = $1
+		), day AS (
+			SELECT user_id, d, bool_or(studied) AS studied
+			FROM (
+				SELECT s.user_id, s.study_date AS d, TRUE AS studied
+				FROM review_daily_stats s JOIN member m ON m.user_id = s.user_id
+				WHERE s.reviews > 0 AND s.study_date <= m.today
+				UNION ALL
+				SELECT v.user_id, g::date, FALSE
+				FROM vacations v JOIN member m ON m.user_id = v.user_id,
+				     generate_series(v.start_date, LEAST(v.end_date, m.today), interval '1 day') AS g
+				UNION ALL
+				SELECT user_id, today, FALSE FROM member
+			) days
+			GROUP BY user_id, d
+		), run AS (
+			-- 连续日期减去序号后相同，即属于同一段
+			SELECT user_id, studied, d - (ROW_NUMBER() OVER (PARTITION BY user_id ORDER BY d))::int AS run_key
+			FROM day
+		)
+		SELECT user_id, COUNT(*) FILTER (WHERE studied)
+		FROM (SELECT *, MAX(run_key) OVER (PARTITION BY user_id) AS last_key FROM run) r
+		WHERE run_key = last_key
+		GROUP BY user_id`, groupID)
+	if err != nil {
+		return nil, err
+	}
+	streaks := make(map[uuid.UUID]int)
+	var userID uuid.UUID
+	var streak int
+	_, err = pgx.ForEachRow(rows, []any{&userID, &streak}, func() error {
+		streaks[userID] = streak
+		return nil
+	})
+	return streaks, err
+}
+
+// groupMemberStats is one member's all-time and recent totals for the group progress view.
+type groupMemberStats struct {
+	learnedWords   int
+	lastReviewedAt *time.Time
+	reviews        int // Scheduled reviews of studied cards over the retention period
+	successes      int
+}
+
+// groupMembersStats returns the learned words, last review and recent scheduled reviews of every
+// member of the group. The retention period ends on each member's own today.
+func groupMembersStats(ctx context.Context, db *pgxpool.Pool, groupID uuid.UUID) (map[uuid.UUID]groupMemberStats, error) {
+	rows, err := db.Query(ctx, `
+		SELECT m.user_id, COALESCE(p.learned, 0), p.last_reviewed_at, COALESCE(r.reviews, 0), COALESCE(r.successes, 0)
+		FROM study_group_members m
+		JOIN users u ON u.id = m.user_id
+		LEFT JOIN (
+			SELECT up.user_id,
+			       COUNT(DISTINCT tracked_meaning_id(up.user_id, up.meaning_id)) FILTER (WHERE up.last_reviewed_at IS NOT NULL) AS learned,
+			       MAX(up.last_reviewed_at) AS last_reviewed_at
+			FROM user_progress up
+			JOIN study_group_members gm ON gm.user_id = up.user_id AND gm.group_id = $1
+			GROUP BY up.user_id
+		) p ON p.user_id = m.user_id
+		LEFT JOIN LATERAL (
+			SELECT SUM(s.young_reviews + s.mature_reviews) AS reviews,
+			       SUM(s.young_successes + s.mature_successes) AS successes
+			FROM review_daily_stats s
+			WHERE s.user_id = m.user_id
+			  AND s.study_date > (now() AT TIME ZONE u.timezone - make_interval(hours => u.day_start_hour))::date - $2::int
+		) r ON TRUE
+		WHERE m.group_id = $1`, groupID, progressRetentionDays)
+	if err != nil {
+		return nil, err
+	}
+	stats := make(map[uuid.UUID]groupMemberStats)
+	var userID uuid.UUID
+	var st groupMemberStats
+	_, err = pgx.ForEachRow(rows, []any{&userID, &st.learnedWords, &st.lastReviewedAt, &st.reviews, &st.successes}, func() error {
+		stats[userID] = st
+		return nil
+	})
+	return stats, err
+}
+
+// GroupProgress returns the progress of every member of the group, for its teachers. Unlike the
+// leaderboard it lists members who opted out of leaderboards by name: show_on_leaderboards only
+// hides a member from other members, and teachers always see individual progress. The week is the
+// teacher's week; streaks and retention run on each member's own study days.
+func GroupProgress(ctx context.Context, db *pgxpool.Pool, groupID, teacherID uuid.UUID) (*models.GroupProgress, error) {
+	today, err := GetStudyDay(ctx, db, teacherID)
+	if err != nil {
+		return nil, err
+	}
+	weekStart, weekEnd := weekOf(today)
+
+	members, err := groupWeek(ctx, db, groupID, weekStart, weekEnd, false)
+	if err != nil {
+		return nil, err
+	}
+	stats, err := groupMembersStats(ctx, db, groupID)
+	if err != nil {
+		return nil, err
+	}
+	streaks, err := groupStreaks(ctx, db, groupID)
+	if err != nil {
+		return nil, err
+	}
+
+	progress := &models.GroupProgress{WeekStart: weekStart, WeekEnd: weekEnd, Members: make([]models.GroupMemberProgress, len(members))}
+	var retentionSum float64
+	var retentionCount int
+	for i, m := range members {
+		st := stats[m.userID]
+		p := models.GroupMemberProgress{
+			UserID:         m.userID,
+			DisplayName:    m.displayName,
+			Role:           m.role,
+			JoinedAt:       m.joinedAt,
+			LearnedWords:   st.learnedWords,
+			WeekReviews:    m.reviews,
+			WeekNewWords:   m.newWords,
+			CurrentStreak:  streaks[m.userID],
+			Retention:      ratio(float64(st.successes), st.reviews),
+			LastReviewedAt: st.lastReviewedAt,
+		}
+		if p.Retention != nil {
+			retentionSum += *p.Retention
+			retentionCount++
+		}
+
+		if m.reviews > 0 {
+			progress.ActiveMembers++
+		}
+		progress.WeekReviews += m.reviews
+		progress.WeekNewWords += m.newWords
+		progress.Members[i] = p
+	}
+	progress.AverageRetention = ratio(retentionSum, retentionCount)
+	return progress, nil
+}
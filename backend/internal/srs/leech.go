@@ -0,0 +1,297 @@
+package srs
+
+import (
+	"context"
+	"errors"
+	"log"
+	"math/rand"
+	"regexp"
+	"slices"
+	"strconv"
+	"time"
+
+	"sentencease/backend/internal/database"
+	"sentencease/backend/internal/models"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// Leech actions, set by the leech_action app setting.
+const (
+	LeechActionTag     = "tag"     // Flag the card but keep scheduling it
+	LeechActionSuspend = "suspend" // Flag and suspend the card
+)
+
+const (
+	// leechRecoveryStreak is the number of correct remediation answers in a row that clears a leech.
+	leechRecoveryStreak = 3
+	// leechQuizChoices is the number of definitions offered in a remediation quiz.
+	leechQuizChoices = 4
+)
+
+// LeechSettings controls when a card becomes a leech and what happens to it.
+type LeechSettings struct {
+	Threshold int    // Lapses after which a card is a leech
+	Action    string // LeechActionTag or LeechActionSuspend
+}
+
+var defaultLeechSettings = LeechSettings{Threshold: 8, Action: LeechActionTag}
+
+// GetLeechSettings 从数据库获取顽固词设置（leech_threshold、leech_action），缺省时使用默认值
+func GetLeechSettings(ctx context.Context, db *pgxpool.Pool) (LeechSettings, error) {
+	settings := defaultLeechSettings
+
+	rows, err := db.Query(ctx, `SELECT key, value FROM app_settings WHERE key IN ('leech_threshold', 'leech_action')`)
+	if err != nil {
+		return settings, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var key, value string
+		if err := rows.Scan(&key, &value); err != nil {
+			return settings, err
+		}
+		switch key {
+		case "leech_threshold":
+			n, err := strconv.Atoi(value)
+			if err != nil || n < 1 {
+				return defaultLeechSettings, errors.New("invalid leech_threshold setting: " + value)
+			}
+			settings.Threshold = n
+		case "leech_action":
+			if value != LeechActionTag && value != LeechActionSuspend {
+				return defaultLeechSettings, errors.New("invalid leech_action setting: " + value)
+			}
+			settings.Action = value
+		}
+	}
+	return settings, rows.Err()
+}
+
+// recordLapse counts a failed review of a card the user had learned before. When the lapse count
+// reaches the threshold the card becomes a leech and, depending on the settings, is suspended.
+func recordLapse(ctx context.Context, tx pgx.Tx, userID uuid.UUID, meaningID int, settings LeechSettings) error {
+	query := `
+		UPDATE user_progress SET
+			lapse_count = lapse_count + 1,
+			remediation_streak = 0,
+			is_leech = is_leech OR lapse_count + 1 >= $3,
+			leeched_at = CASE WHEN NOT is_leech AND lapse_count + 1 >= $3 THEN now() ELSE leeched_at END,
+			card_state = CASE WHEN NOT is_leech AND lapse_count + 1 >= $3 AND $4 THEN 'suspended' ELSE card_state END,
+			state_changed_at = CASE WHEN NOT is_leech AND lapse_count + 1 >= $3 AND $4 THEN now() ELSE state_changed_at END
+		WHERE user_id = $1 AND meaning_id = $2
+		RETURNING lapse_count, is_leech
+	`
+	var lapses int
+	var isLeech bool
+	err := tx.QueryRow(ctx, query, userID, meaningID, settings.Threshold, settings.Action == LeechActionSuspend).
+		Scan(&lapses, &isLeech)
+	if err != nil {
+		return err
+	}
+
+	if isLeech && lapses == settings.Threshold {
+		log.Printf("Meaning %d became a leech for user %s after %d lapses (action: %s)", meaningID, userID, lapses, settings.Action)
+	}
+	return nil
+}
+
+// ListLeeches returns the user's leeches, most lapsed first.
+func ListLeeches(ctx context.Context, db *pgxpool.Pool, userID uuid.UUID) ([]models.Leech, error) {
+	query := `
+		SELECT m.id, m.word_id, w.lemma, m.definition, up.lapse_count, up.card_state, up.buried_until,
+		       up.leeched_at, up.remediation_streak
+		FROM user_progress up
+		JOIN meanings m ON m.id = up.meaning_id
+		JOIN words w ON w.id = m.word_id
+		WHERE up.user_id = $1 AND up.is_leech
+		ORDER BY up.lapse_count DESC, up.leeched_at DESC, m.id
+	`
+	rows, err := db.Query(ctx, query, userID)
+	if err != nil {
+		return nil, err
+	}
+	return pgx.CollectRows(rows, func(row pgx.CollectableRow) (models.Leech, error) {
+		var l models.Leech
+		var state string
+		var buriedUntil *time.Time
+		err := row.Scan(&l.MeaningID, &l.WordID, &l.Lemma, &l.Definition, &l.LapseCount, &state, &buriedUntil,
+			&l.LeechedAt, &l.RemediationStreak)
+		l.CardState = EffectiveCardState(&state, buriedUntil)
+		return l, err
+	})
+}
+
+// NextLeechRemediation returns a remediation exercise for the leech practised least recently:
+// the word blanked out of an alternative example sentence, with a choice of definitions.
+func NextLeechRemediation(ctx context.Context, db *pgxpool.Pool, userID uuid.UUID) (*models.LeechRemediationCard, error) {
+	var card models.LeechRemediationCard
+	var wordID int
+	var definition string
+	var source *string
+	err := db.QueryRow(ctx, `
+		SELECT m.id, m.word_id, w.lemma, m.definition, m.example_sentence, m.example_sentence_translation,
+		       w.source, up.remediation_streak
+		FROM user_progress up
+		JOIN meanings m ON m.id = up.meaning_id
+		JOIN words w ON w.id = m.word_id
+		WHERE up.user_id = $1 AND up.is_leech
+		ORDER BY up.last_remediated_at NULLS FIRST, up.lapse_count DESC, m.id
+		LIMIT 1`, userID).Scan(
+		&card.MeaningID, &wordID, &card.Lemma, &definition, &card.Sentence, &card.SentenceTranslation,
+		&source, &card.RemediationStreak,
+	)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, database.ErrNotFound
+		}
+		return nil, err
+	}
+	card.StreakToRecover = leechRecoveryStreak
+
+	// 优先使用备用例句，避免重复同一张卡片；没有时借用其他词库中同一单词、同一释义的例句
+	var sentence string
+	var translation *string
+	err = db.QueryRow(ctx, `
+		SELECT sentence, translation FROM (
+			SELECT sentence, translation, 0 AS preference
+			FROM meaning_examples WHERE meaning_id = $1
+			UNION ALL
+			SELECT m.example_sentence, m.example_sentence_translation, 1
+			FROM meanings m
+			JOIN words w ON w.id = m.word_id
+			WHERE w.lemma = $2 AND m.definition = $3 AND m.id <> $1
+			  AND m.example_sentence <> '' AND m.example_sentence <> $4
+		) alternatives
+		ORDER BY preference, random()
+		LIMIT 1`, card.MeaningID, card.Lemma, definition, card.Sentence).Scan(&sentence, &translation)
+	switch {
+	case err == nil:
+		card.Sentence, card.SentenceTranslation = sentence, translation
+	case !errors.Is(err, pgx.ErrNoRows):
+		return nil, err
+	}
+	card.ClozeSentence = clozeSentence(card.Sentence, card.Lemma)
+
+	// 干扰项优先取自同一词库的其他单词；释义与正确答案相同或彼此重复的不能作为干扰项
+	rows, err := db.Query(ctx, `
+		SELECT m.definition
+		FROM meanings m
+		JOIN words w ON w.id = m.word_id
+		WHERE m.word_id <> $1 AND m.definition <> $4
+		ORDER BY (w.source IS NOT DISTINCT FROM $2) DESC, random()
+		LIMIT $3`, wordID, source, 4*(leechQuizChoices-1), definition)
+	if err != nil {
+		return nil, err
+	}
+	candidates, err := pgx.CollectRows(rows, pgx.RowTo[string])
+	if err != nil {
+		return nil, err
+	}
+	var definitions []string
+	for _, d := range candidates {
+		if len(definitions) < leechQuizChoices-1 && !slices.Contains(definitions, d) {
+			definitions = append(definitions, d)
+		}
+	}
+	correct := rand.Intn(len(definitions) + 1)
+	definitions = slices.Insert(definitions, correct, definition)
+	for _, d := range definitions {
+		card.Choices = append(card.Choices, models.LeechQuizChoice{Definition: d})
+	}
+
+	// 正确答案只记录在服务器端；新题目替换该用户尚未作答的旧题目
+	err = db.QueryRow(ctx, `
+		INSERT INTO leech_quizzes (user_id, meaning_id, correct_choice)
+		VALUES ($1, $2, $3)
+		ON CONFLICT (user_id) DO UPDATE SET
+			id = gen_random_uuid(),
+			meaning_id = EXCLUDED.meaning_id,
+			correct_choice = EXCLUDED.correct_choice,
+			created_at = now()
+		RETURNING id`, userID, card.MeaningID, correct).Scan(&card.QuizID)
+	if err != nil {
+		return nil, err
+	}
+
+	return &card, nil
+}
+
+// AnswerLeechRemediation records the answer to the user's current remediation quiz, given as the
+// index of the chosen definition. Each quiz can be answered once. After leechRecoveryStreak correct
+// answers in a row the card stops being a leech: it is unsuspended, due immediately, and its lapse
+// count is halved so a relapse turns it back into a leech sooner.
+func AnswerLeechRemediation(ctx context.Context, db *pgxpool.Pool, userID, quizID uuid.UUID, choice int) (*models.LeechAnswerResult, error) {
+	tx, err := db.Begin(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback(ctx)
+
+	var meaningID, correctChoice int
+	err = tx.QueryRow(ctx, `
+		DELETE FROM leech_quizzes WHERE id = $1 AND user_id = $2
+		RETURNING meaning_id, correct_choice`, quizID, userID).Scan(&meaningID, &correctChoice)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, database.ErrNotFound
+		}
+		return nil, err
+	}
+
+	var streak int
+	err = tx.QueryRow(ctx, `
+		SELECT remediation_streak FROM user_progress
+		WHERE user_id = $1 AND meaning_id = $2 AND is_leech
+		FOR UPDATE`, userID, meaningID).Scan(&streak)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, database.ErrNotFound
+		}
+		return nil, err
+	}
+
+	result := &models.LeechAnswerResult{
+		Correct:       choice == correctChoice,
+		CorrectChoice: correctChoice,
+	}
+	if result.Correct {
+		result.RemediationStreak = streak + 1
+	}
+	result.Recovered = result.RemediationStreak >= leechRecoveryStreak
+
+	if result.Recovered {
+		_, err = tx.Exec(ctx, `
+			UPDATE user_progress SET
+				is_leech = FALSE,
+				lapse_count = lapse_count / 2,
+				remediation_streak = 0,
+				last_remediated_at = now(),
+				next_review_at = now(),
+				card_state = CASE WHEN card_state = 'suspended' THEN 'active' ELSE card_state END,
+				state_changed_at = CASE WHEN card_state = 'suspended' THEN now() ELSE state_changed_at END
+			WHERE user_id = $1 AND meaning_id = $2`, userID, meaningID)
+	} else {
+		_, err = tx.Exec(ctx, `
+			UPDATE user_progress SET remediation_streak = $3, last_remediated_at = now()
+			WHERE user_id = $1 AND meaning_id = $2`, userID, meaningID, result.RemediationStreak)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// clozeSentence blanks out the word (in whatever form it appears) from the sentence.
+func clozeSentence(sentence, lemma string) string {
+	form := findWordInSentence(sentence, lemma)
+	re := regexp.MustCompile(`(?i)\b` + regexp.QuoteMeta(form) + `\b`)
+	return re.ReplaceAllString(sentence, "____")
+}
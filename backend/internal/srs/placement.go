@@ -0,0 +1,440 @@
+package srs
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log"
+	"math"
+
+	"sentencease/backend/internal/database"
+	"sentencease/backend/internal/models"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// 词汇量测试参数
+const (
+	placementBands               = 5     // 按难度把词书分成的档数
+	placementDefaultMaxQuestions = 30    // 默认问题数量
+	placementKnownThreshold      = 0.8   // 认识概率达到该值的档位整体视为已掌握
+	placementMinDelayHours       = 336   // 已掌握单词的首次复习最早在14天后
+	placementDelaySpreadHours    = 744   // 再在31天内随机分散，避免同一天集中到期
+	placementHalflife            = 720.0 // 已掌握单词的初始记忆半衰期（小时）
+	placementSRSStage            = 3     // 已掌握单词的初始阶段（传统算法）
+)
+
+var (
+	// ErrPlacementFinished is returned when answering a test that is no longer in progress.
+	ErrPlacementFinished = errors.New("placement test is already finished")
+	// ErrPlacementWrongWord is returned when the answer is not for the pending question.
+	ErrPlacementWrongWord = errors.New("answer does not match the current question")
+	// ErrPlacementNotCompleted is returned when applying a test that is in progress or already applied.
+	ErrPlacementNotCompleted = errors.New("placement test must be completed and not yet applied")
+)
+
+// placementBandsCTE assigns every word of source $1 to one of $2 difficulty bands (1 = easiest).
+// Words without a difficulty keep the book order, which roughly follows the units.
+const placementBandsCTE = `
+	placement_bands AS (
+		SELECT w.id AS word_id, NTILE($2) OVER (ORDER BY COALESCE(w.difficulty, 0.5), w.id) AS band
+		FROM words w
+		WHERE w.source = $1
+	)`
+
+// StartPlacementTest creates a placement test over a source and returns its first question.
+func StartPlacementTest(ctx context.Context, db *pgxpool.Pool, userID uuid.UUID, source string, maxQuestions int) (*models.PlacementTest, *models.PlacementQuestion, error) {
+	if maxQuestions <= 0 {
+		maxQuestions = placementDefaultMaxQuestions
+	}
+
+	tx, err := db.Begin(ctx)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer tx.Rollback(ctx)
+
+	startBand := (placementBands + 1) / 2
+	var testID uuid.UUID
+	err = tx.QueryRow(ctx, `
+		INSERT INTO placement_tests (user_id, source, num_bands, max_questions, current_band)
+		VALUES ($1, $2, $3, $4, $5) RETURNING id`,
+		userID, source, placementBands, maxQuestions, startBand).Scan(&testID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	// Returns database.ErrNotFound if the source has no words.
+	question, err := pickPlacementQuestion(ctx, tx, testID, source, placementBands, startBand)
+	if err != nil {
+		return nil, nil, err
+	}
+	if err := setPendingQuestion(ctx, tx, testID, question); err != nil {
+		return nil, nil, err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, nil, err
+	}
+
+	test, err := GetPlacementTest(ctx, db, userID, testID)
+	if err != nil {
+		return nil, nil, err
+	}
+	return test, question, nil
+}
+
+// AnswerPlacementQuestion records a known/unknown answer and returns the next question,
+// or a nil question once the test is finished and the vocabulary estimate is available.
+func AnswerPlacementQuestion(ctx context.Context, db *pgxpool.Pool, userID, testID uuid.UUID, wordID int, known bool) (*models.PlacementTest, *models.PlacementQuestion, error) {
+	tx, err := db.Begin(ctx)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer tx.Rollback(ctx)
+
+	var source, status string
+	var numBands, maxQuestions, currentBand int
+	var pendingWordID *int
+	err = tx.QueryRow(ctx, `
+		SELECT source, status, num_bands, max_questions, current_band, pending_word_id
+		FROM placement_tests WHERE id = $1 AND user_id = $2
+		FOR UPDATE`, testID, userID).Scan(&source, &status, &numBands, &maxQuestions, &currentBand, &pendingWordID)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, nil, database.ErrNotFound
+		}
+		return nil, nil, err
+	}
+	if status != models.PlacementInProgress {
+		return nil, nil, ErrPlacementFinished
+	}
+	if pendingWordID == nil || *pendingWordID != wordID {
+		return nil, nil, ErrPlacementWrongWord
+	}
+
+	// current_band is always the band of the pending question.
+	_, err = tx.Exec(ctx,
+		`INSERT INTO placement_test_answers (test_id, word_id, band, known) VALUES ($1, $2, $3, $4)`,
+		testID, wordID, currentBand, known)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var asked int
+	if err := tx.QueryRow(ctx, `SELECT COUNT(*) FROM placement_test_answers WHERE test_id = $1`, testID).Scan(&asked); err != nil {
+		return nil, nil, err
+	}
+
+	var question *models.PlacementQuestion
+	if asked < maxQuestions {
+		question, err = pickPlacementQuestion(ctx, tx, testID, source, numBands, nextPlacementBand(currentBand, numBands, known))
+		if err != nil && !errors.Is(err, database.ErrNotFound) {
+			return nil, nil, err
+		}
+	}
+
+	if question != nil {
+		err = setPendingQuestion(ctx, tx, testID, question)
+	} else {
+		err = finishPlacementTest(ctx, tx, testID, source, numBands)
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, nil, err
+	}
+
+	test, err := GetPlacementTest(ctx, db, userID, testID)
+	if err != nil {
+		return nil, nil, err
+	}
+	return test, question, nil
+}
+
+// GetPlacementTest loads a placement test. Band statistics are live while the test is in
+// progress and frozen once it is completed.
+func GetPlacementTest(ctx context.Context, db *pgxpool.Pool, userID, testID uuid.UUID) (*models.PlacementTest, error) {
+	var test models.PlacementTest
+	var numBands int
+	var bandResults []byte
+	err := db.QueryRow(ctx, `
+		SELECT id, source, status, max_questions, num_bands, estimated_vocabulary, band_results,
+		       created_at, completed_at, applied_at,
+		       (SELECT COUNT(*) FROM placement_test_answers a WHERE a.test_id = t.id)
+		FROM placement_tests t WHERE id = $1 AND user_id = $2`, testID, userID).Scan(
+		&test.ID, &test.Source, &test.Status, &test.MaxQuestions, &numBands, &test.EstimatedVocabulary, &bandResults,
+		&test.CreatedAt, &test.CompletedAt, &test.AppliedAt, &test.QuestionsAsked,
+	)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, database.ErrNotFound
+		}
+		return nil, err
+	}
+
+	if bandResults != nil {
+		if err := json.Unmarshal(bandResults, &test.Bands); err != nil {
+			return nil, err
+		}
+	} else {
+		test.Bands, err = placementBandStats(ctx, db, testID, test.Source, numBands)
+		if err != nil {
+			return nil, err
+		}
+		EstimatePlacement(test.Bands)
+	}
+
+	for _, b := range test.Bands {
+		test.TotalWords += b.Words
+	}
+	return &test, nil
+}
+
+// ApplyPlacementTest adds user_progress rows with long intervals for the words the test
+// found to be probably known, so the scheduler focuses on unknown words. Existing progress is
+// never overwritten. It returns the number of meanings added.
+func ApplyPlacementTest(ctx context.Context, db *pgxpool.Pool, userID, testID uuid.UUID) (int, error) {
+	tx, err := db.Begin(ctx)
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback(ctx)
+
+	var source, status string
+	var numBands int
+	var bandResults []byte
+	err = tx.QueryRow(ctx, `
+		SELECT source, status, num_bands, band_results
+		FROM placement_tests WHERE id = $1 AND user_id = $2
+		FOR UPDATE`, testID, userID).Scan(&source, &status, &numBands, &bandResults)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return 0, database.ErrNotFound
+		}
+		return 0, err
+	}
+	if status != models.PlacementCompleted {
+		return 0, ErrPlacementNotCompleted
+	}
+
+	var bands []models.PlacementBand
+	if err := json.Unmarshal(bandResults, &bands); err != nil {
+		return 0, err
+	}
+	knownBands := []int{}
+	for _, b := range bands {
+		if b.PKnown >= placementKnownThreshold {
+			knownBands = append(knownBands, b.Band)
+		}
+	}
+
+	// Words in known bands, minus those answered "unknown", plus every word answered "known".
+	query := `
+		WITH ` + placementBandsCTE + `,
+		known_words AS (
+			SELECT pb.word_id FROM placement_bands pb
+			WHERE pb.band = ANY($3)
+			  AND NOT EXISTS (
+				SELECT 1 FROM placement_test_answers a
+				WHERE a.test_id = $4 AND a.word_id = pb.word_id AND NOT a.known
+			  )
+			UNION
+			SELECT a.word_id FROM placement_test_answers a WHERE a.test_id = $4 AND a.known
+		)
+		INSERT INTO user_progress
+			(user_id, meaning_id, srs_stage, next_review_at,
+			 memory_halflife, optimal_interval, review_count, last_recall_success)
+		SELECT $5, m.id, $6, now() + ($7 + random() * $8) * interval '1 hour', $9, $9, 0, true
+		FROM meanings m
+		JOIN known_words kw ON kw.word_id = m.word_id
+		WHERE m.id = tracked_meaning_id($5, m.id)
+		ON CONFLICT (user_id, meaning_id) DO NOTHING
+	`
+	tag, err := tx.Exec(ctx, query, source, numBands, knownBands, testID, userID,
+		placementSRSStage, float64(placementMinDelayHours), float64(placementDelaySpreadHours), placementHalflife)
+	if err != nil {
+		return 0, err
+	}
+
+	_, err = tx.Exec(ctx,
+		`UPDATE placement_tests SET status = $2, applied_at = now() WHERE id = $1`, testID, models.PlacementApplied)
+	if err != nil {
+		return 0, err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return 0, err
+	}
+
+	log.Printf("Applied placement test %s for user %s: %d meanings marked as known", testID, userID, tag.RowsAffected())
+	return int(tag.RowsAffected()), nil
+}
+
+// nextPlacementBand implements a simple staircase: a known word moves the next question one band
+// harder, an unknown word one band easier.
+func nextPlacementBand(current, numBands int, known bool) int {
+	if known {
+		return int(math.Min(float64(current+1), float64(numBands)))
+	}
+	return int(math.Max(float64(current-1), 1))
+}
+
+// EstimatePlacement fills in PKnown for every band and returns the estimated number of known words.
+// Each asked band uses a Laplace-smoothed success rate; unasked bands take the value of the nearest
+// asked band. Since harder bands should not be better known than easier ones, the estimates are
+// then made non-increasing with weighted isotonic regression (pool adjacent violators).
+func EstimatePlacement(bands []models.PlacementBand) int {
+	n := len(bands)
+	if n == 0 {
+		return 0
+	}
+
+	p := make([]float64, n)
+	asked := false
+	for i, b := range bands {
+		if b.Asked > 0 {
+			p[i] = float64(b.Known+1) / float64(b.Asked+2)
+			asked = true
+		}
+	}
+	if !asked {
+		for i := range bands {
+			bands[i].PKnown = 0
+		}
+		return 0
+	}
+
+	for i, b := range bands {
+		if b.Asked > 0 {
+			continue
+		}
+		best := -1
+		for j := range bands {
+			if bands[j].Asked > 0 && (best < 0 || abs(j-i) < abs(best-i)) {
+				best = j
+			}
+		}
+		p[i] = p[best]
+	}
+
+	// Pool adjacent violators for a non-increasing sequence.
+	type block struct {
+		value, weight float64
+		size          int
+	}
+	blocks := make([]block, 0, n)
+	for i, b := range bands {
+		w := math.Max(float64(b.Asked), 1)
+		blocks = append(blocks, block{value: p[i], weight: w, size: 1})
+		for len(blocks) > 1 && blocks[len(blocks)-2].value < blocks[len(blocks)-1].value {
+			last, prev := blocks[len(blocks)-1], blocks[len(blocks)-2]
+			merged := block{
+				value:  (prev.value*prev.weight + last.value*last.weight) / (prev.weight + last.weight),
+				weight: prev.weight + last.weight,
+				size:   prev.size + last.size,
+			}
+			blocks = append(blocks[:len(blocks)-2], merged)
+		}
+	}
+
+	estimate := 0.0
+	i := 0
+	for _, blk := range blocks {
+		for k := 0; k < blk.size; k++ {
+			bands[i].PKnown = math.Round(blk.value*1000) / 1000
+			estimate += blk.value * float64(bands[i].Words)
+			i++
+		}
+	}
+	return int(math.Round(estimate))
+}
+
+func abs(x int) int {
+	if x < 0 {
+		return -x
+	}
+	return x
+}
+
+// pickPlacementQuestion chooses a random unanswered word from the band closest to the preferred one.
+func pickPlacementQuestion(ctx context.Context, tx pgx.Tx, testID uuid.UUID, source string, numBands, preferredBand int) (*models.PlacementQuestion, error) {
+	query := `
+		WITH ` + placementBandsCTE + `
+		SELECT pb.word_id, pb.band, w.lemma,
+		       COALESCE((SELECT m.example_sentence FROM meanings m WHERE m.word_id = w.id ORDER BY m.id LIMIT 1), '')
+		FROM placement_bands pb
+		JOIN words w ON w.id = pb.word_id
+		WHERE NOT EXISTS (SELECT 1 FROM placement_test_answers a WHERE a.test_id = $3 AND a.word_id = pb.word_id)
+		ORDER BY abs(pb.band - $4), random()
+		LIMIT 1
+	`
+	var q models.PlacementQuestion
+	err := tx.QueryRow(ctx, query, source, numBands, testID, preferredBand).Scan(&q.WordID, &q.Band, &q.Lemma, &q.ExampleSentence)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, database.ErrNotFound
+		}
+		return nil, err
+	}
+	return &q, nil
+}
+
+// setPendingQuestion remembers the question the next answer must refer to.
+func setPendingQuestion(ctx context.Context, tx pgx.Tx, testID uuid.UUID, q *models.PlacementQuestion) error {
+	_, err := tx.Exec(ctx,
+		`UPDATE placement_tests SET pending_word_id = $2, current_band = $3 WHERE id = $1`,
+		testID, q.WordID, q.Band)
+	return err
+}
+
+// finishPlacementTest computes and stores the final band estimates.
+func finishPlacementTest(ctx context.Context, tx pgx.Tx, testID uuid.UUID, source string, numBands int) error {
+	bands, err := placementBandStats(ctx, tx, testID, source, numBands)
+	if err != nil {
+		return err
+	}
+	estimate := EstimatePlacement(bands)
+
+	bandResults, err := json.Marshal(bands)
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.Exec(ctx, `
+		UPDATE placement_tests
+		SET status = $2, pending_word_id = NULL, estimated_vocabulary = $3, band_results = $4, completed_at = now()
+		WHERE id = $1`, testID, models.PlacementCompleted, estimate, bandResults)
+	return err
+}
+
+// querier is satisfied by both the pool and a transaction.
+type querier interface {
+	Query(ctx context.Context, sql string, args ...interface{}) (pgx.Rows, error)
+	QueryRow(ctx context.Context, sql string, args ...interface{}) pgx.Row
+}
+
+// placementBandStats counts the words, questions and known answers of every band.
+func placementBandStats(ctx context.Context, db querier, testID uuid.UUID, source string, numBands int) ([]models.PlacementBand, error) {
+	query := `
+		WITH ` + placementBandsCTE + `
+		SELECT pb.band, COUNT(*), COUNT(a.word_id), COUNT(a.word_id) FILTER (WHERE a.known)
+		FROM placement_bands pb
+		LEFT JOIN placement_test_answers a ON a.test_id = $3 AND a.word_id = pb.word_id
+		GROUP BY pb.band
+		ORDER BY pb.band
+	`
+	rows, err := db.Query(ctx, query, source, numBands, testID)
+	if err != nil {
+		return nil, err
+	}
+	return pgx.CollectRows(rows, func(row pgx.CollectableRow) (models.PlacementBand, error) {
+		var b models.PlacementBand
+		err := row.Scan(&b.Band, &b.Words, &b.Asked, &b.Known)
+		return b, err
+	})
+}
@@ -0,0 +1,110 @@
+package srs
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+)
+
+const (
+	// MatureIntervalHours is the time since the previous review from which a review counts as mature.
+	MatureIntervalHours = 21 * 24
+	// maxCountedResponseMs caps the time counted for one answer, so a card left open does not
+	// count as hours of study.
+	maxCountedResponseMs = 60_000
+)
+
+// reviewLogEntry is one review to be recorded in review_log.
+type reviewLogEntry struct {
+	UserID         uuid.UUID
+	MeaningID      int
+	UserChoice     string
+	IsCram         bool
+	Algorithm      *string // Scheduling algorithm in use, nil for cram reviews
+	ExperimentID   *uuid.UUID
+	ArmID          *int
+	CramSessionID  *uuid.UUID
+	Elapsed        *time.Duration // Study time since the previous review given to the scheduler, nil for a new word
+	MemoryHalfLife *float64       // Half-life before this review, nil for a new word
+	NewHalfLife    *float64       // Half-life after this review, nil unless scheduled by SSP-MMC
+	ResponseMs     *int           // Time the user took to answer, if the client reported it
+}
+
+// logReview appends a review to review_log and adds it to the user's daily rollup.
+func logReview(ctx context.Context, tx pgx.Tx, e reviewLogEntry) error {
+	reviewedAt := time.Now()
+
+	var elapsedHours *float64
+	if e.Elapsed != nil {
+		hours := e.Elapsed.Hours()
+		elapsedHours = &hours
+	}
+
+	_, err := tx.Exec(ctx, `
+		INSERT INTO review_log
+			(user_id, meaning_id, user_choice, success, is_cram, cram_session_id, elapsed_hours, memory_halflife,
+			 new_halflife, response_ms, algorithm, experiment_id, arm_id, reviewed_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14)`,
+		e.UserID, e.MeaningID, e.UserChoice, e.UserChoice == "认识", e.IsCram, e.CramSessionID,
+		elapsedHours, e.MemoryHalfLife, e.NewHalfLife, e.ResponseMs, e.Algorithm, e.ExperimentID, e.ArmID, reviewedAt)
+	if err != nil {
+		return err
+	}
+
+	return addToDailyStats(ctx, tx, e, reviewedAt, elapsedHours)
+}
+
+// addToDailyStats adds one review to the review_daily_stats row of the user's study day.
+func addToDailyStats(ctx context.Context, tx pgx.Tx, e reviewLogEntry, reviewedAt time.Time, elapsedHours *float64) error {
+	loc, dayStartHour, err := GetUserDayConfig(ctx, tx, e.UserID)
+	if err != nil {
+		return err
+	}
+
+	b := func(v bool) int {
+		if v {
+			return 1
+		}
+		return 0
+	}
+	success := e.UserChoice == "认识"
+	scheduled := !e.IsCram
+	mature := scheduled && elapsedHours != nil && *elapsedHours >= MatureIntervalHours
+	young := scheduled && elapsedHours != nil && !mature
+
+	var timed, spentMs int
+	if e.ResponseMs != nil {
+		timed, spentMs = 1, min(*e.ResponseMs, maxCountedResponseMs)
+	}
+
+	var growth float64
+	var grew int
+	if scheduled && success && e.NewHalfLife != nil && e.MemoryHalfLife != nil && *e.MemoryHalfLife > 0 {
+		growth, grew = *e.NewHalfLife / *e.MemoryHalfLife, 1
+	}
+
+	_, err = tx.Exec(ctx, `
+		INSERT INTO review_daily_stats AS s
+			(user_id, study_date, reviews, successes, cram_reviews, new_cards, young_reviews, young_successes,
+			 mature_reviews, mature_successes, timed_reviews, time_spent_ms, halflife_growth_sum, halflife_growth_count)
+		VALUES ($1, $2, 1, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13)
+		ON CONFLICT (user_id, study_date) DO UPDATE SET
+			reviews = s.reviews + 1,
+			successes = s.successes + EXCLUDED.successes,
+			cram_reviews = s.cram_reviews + EXCLUDED.cram_reviews,
+			new_cards = s.new_cards + EXCLUDED.new_cards,
+			young_reviews = s.young_reviews + EXCLUDED.young_reviews,
+			young_successes = s.young_successes + EXCLUDED.young_successes,
+			mature_reviews = s.mature_reviews + EXCLUDED.mature_reviews,
+			mature_successes = s.mature_successes + EXCLUDED.mature_successes,
+			timed_reviews = s.timed_reviews + EXCLUDED.timed_reviews,
+			time_spent_ms = s.time_spent_ms + EXCLUDED.time_spent_ms,
+			halflife_growth_sum = s.halflife_growth_sum + EXCLUDED.halflife_growth_sum,
+			halflife_growth_count = s.halflife_growth_count + EXCLUDED.halflife_growth_count`,
+		e.UserID, StudyDayAt(reviewedAt, loc, dayStartHour).Date(),
+		b(success), b(e.IsCram), b(scheduled && elapsedHours == nil), b(young), b(young && success),
+		b(mature), b(mature && success), timed, spentMs, growth, grew)
+	return err
+}
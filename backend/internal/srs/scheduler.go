@@ -0,0 +1,70 @@
+package srs
+
+import (
+	"fmt"
+	"time"
+
+	"sentencease/backend/internal/models"
+)
+
+// SchedulerNames lists the scheduling algorithms, as stored in the srs_algorithm app setting.
+var SchedulerNames = []string{"legacy", "sspmmc"}
+
+// SchedulerCard is the scheduling state of one card, independent of the database.
+// A new card has the zero value with HalfLife set to NewCardHalfLife.
+type SchedulerCard struct {
+	Stage       int
+	HalfLife    float64 // Memory half-life in hours, used by SSP-MMC
+	ReviewCount int
+	Difficulty  float64 // Meaning difficulty in [0, 1]; 0 means unknown
+}
+
+// NewCardHalfLife is the half-life UpdateProgress starts a new word with.
+const NewCardHalfLife = 4.0
+
+// Scheduler computes the interval until the next review after an answer, updating the card state.
+// Implementations apply the same rules as UpdateProgress, without interval fuzz and load balancing,
+// so tools can run the schedulers offline. elapsed is the time since the previous review (zero for
+// a new card).
+type Scheduler interface {
+	Review(card *SchedulerCard, userChoice string, elapsed time.Duration) time.Duration
+}
+
+// NewScheduler returns the scheduler for an algorithm name from SchedulerNames, with the given
+// parameter overrides as in an experiment arm.
+func NewScheduler(algorithm string, params models.SchedulerParams) (Scheduler, error) {
+	switch algorithm {
+	case "legacy":
+		return legacyScheduler{params}, nil
+	case "sspmmc":
+		return sspmmcScheduler{params}, nil
+	default:
+		return nil, fmt.Errorf("unknown scheduling algorithm %q", algorithm)
+	}
+}
+
+// legacyScheduler is the stage ladder with exponentially growing intervals.
+type legacyScheduler struct{ params models.SchedulerParams }
+
+func (s legacyScheduler) Review(card *SchedulerCard, userChoice string, elapsed time.Duration) time.Duration {
+	stage := calculateNextStage(card.Stage, userChoice)
+	if userChoice == "认识" {
+		stage = lateRecallStage(stage, elapsed)
+	}
+	card.Stage = stage
+	card.ReviewCount++
+	return modifyInterval(calculateNextInterval(stage), s.params)
+}
+
+// sspmmcScheduler is the SSP-MMC scheduler of UpdateProgressWithSSPMMC.
+type sspmmcScheduler struct{ params models.SchedulerParams }
+
+func (s sspmmcScheduler) Review(card *SchedulerCard, userChoice string, elapsed time.Duration) time.Duration {
+	progress := models.UserProgress{SRSStage: card.Stage, MemoryHalfLife: card.HalfLife, ReviewCount: card.ReviewCount}
+	meaning := models.Meaning{Difficulty: card.Difficulty}
+	UpdateProgressWithSSPMMC(&progress, &meaning, userChoice, elapsed.Hours())
+
+	card.Stage, card.HalfLife, card.ReviewCount = progress.SRSStage, progress.MemoryHalfLife, progress.ReviewCount
+	interval := sspmmcReviewInterval(optimalInterval(progress.MemoryHalfLife, s.params), progress.LastRecallSuccess)
+	return modifyInterval(interval, s.params)
+}
@@ -0,0 +1,56 @@
+package srs
+
+import (
+	"context"
+	"errors"
+	"log"
+	"strconv"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// DefaultSiblingBuryWindow is how long sibling meanings of a just-reviewed word are deferred
+// when app_settings has no sibling_bury_minutes entry.
+const DefaultSiblingBuryWindow = 30 * time.Minute
+
+// siblingReviewedSince returns a condition that is true when another meaning of m's word was
+// reviewed by the user ($1) after the time in cutoffParam. A review card shows all meanings of
+// its word, so showing a sibling right afterwards would give the answer away.
+func siblingReviewedSince(cutoffParam string) string {
+	return `EXISTS (
+		SELECT 1 FROM user_progress sp
+		JOIN meanings sm ON sm.id = sp.meaning_id
+		WHERE sp.user_id = $1 AND sm.word_id = m.word_id AND sm.id <> m.id
+		  AND sp.last_reviewed_at > ` + cutoffParam + `
+	)`
+}
+
+// siblingCutoff returns the time before which sibling reviews no longer defer a meaning.
+func siblingCutoff(ctx context.Context, db *pgxpool.Pool) time.Time {
+	window, err := GetSiblingBuryWindow(ctx, db)
+	if err != nil {
+		log.Printf("Error getting sibling bury window: %v, using default (%v)", err, DefaultSiblingBuryWindow)
+		window = DefaultSiblingBuryWindow
+	}
+	return time.Now().Add(-window)
+}
+
+// GetSiblingBuryWindow 从数据库获取同词其他词义的推迟时长（sibling_bury_minutes，0 表示不推迟）
+func GetSiblingBuryWindow(ctx context.Context, db *pgxpool.Pool) (time.Duration, error) {
+	var value string
+	err := db.QueryRow(ctx, `SELECT value FROM app_settings WHERE key = 'sibling_bury_minutes'`).Scan(&value)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return DefaultSiblingBuryWindow, nil
+		}
+		return 0, err
+	}
+
+	minutes, err := strconv.Atoi(value)
+	if err != nil || minutes < 0 {
+		return 0, errors.New("invalid sibling_bury_minutes setting: " + value)
+	}
+	return time.Duration(minutes) * time.Minute, nil
+}
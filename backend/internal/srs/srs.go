@@ -19,15 +19,26 @@ import (
 
 // GetNextWordForReview finds the next word for a user to review, returning a full WordReviewCard.
 func GetNextWordForReview(ctx context.Context, db *pgxpool.Pool, userID uuid.UUID, source string) (*models.WordReviewCard, error) {
+	// 0. Determine the user's current study day.
+	day, err := GetStudyDay(ctx, db, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	// Generate today's plan from the user's recurring study plans, if any.
+	if err := EnsureDailyPlans(ctx, db, userID, day); err != nil {
+		log.Printf("Error generating daily plans for user %s: %v", userID, err)
+	}
+
 	// 1. Check for a daily plan.
-	hasDailyPlan, err := checkDailyPlanExists(ctx, db, userID)
+	hasDailyPlan, err := checkDailyPlanExists(ctx, db, userID, day)
 	if err != nil {
 		log.Printf("Error checking if daily plan exists: %v", err)
 	}
 
 	// 2. If a daily plan exists, try to get the next word from it.
 	if hasDailyPlan {
-		completed, total, err := getDailyPlanProgress(ctx, db, userID)
+		completed, total, err := GetDailyPlanProgress(ctx, db, userID, day)
 		if err != nil {
 			log.Printf("Error getting daily plan progress: %v", err)
 		} else if completed >= total && total > 0 {
@@ -35,7 +46,7 @@ func GetNextWordForReview(ctx context.Context, db *pgxpool.Pool, userID uuid.UUI
 			return nil, database.ErrNotFound
 		}
 
-		wordCard, err := getNextWordFromDailyPlan(ctx, db, userID)
+		wordCard, err := getNextWordFromDailyPlan(ctx, db, userID, day)
 		if err == nil && wordCard != nil {
 			log.Printf("Found next word from daily plan for user %s", userID)
 			return wordCard, nil
@@ -51,6 +62,36 @@ func GetNextWordForReview(ctx context.Context, db *pgxpool.Pool, userID uuid.UUI
 	// 3. Fallback to the original logic if no daily plan or plan is empty.
 	log.Printf("No daily plan found for user %s, falling back to original logic", userID)
 
+	return getNextDueWord(ctx, db, userID, source)
+}
+
+// PeekNextWordForReview previews the word GetNextWordForReview would return, so the client can
+// preload it. Apart from generating the day's plans, which GetNextWordForReview would also do, it
+// does not change any state.
+func PeekNextWordForReview(ctx context.Context, db *pgxpool.Pool, userID uuid.UUID, source string) (*models.WordReviewCard, error) {
+	day, err := GetStudyDay(ctx, db, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	// Like GetNextWordForReview, so the preview comes from the same plan on the first request of a day.
+	if err := EnsureDailyPlans(ctx, db, userID, day); err != nil {
+		log.Printf("Error generating daily plans for user %s: %v", userID, err)
+	}
+
+	hasDailyPlan, err := checkDailyPlanExists(ctx, db, userID, day)
+	if err != nil {
+		log.Printf("Error checking if daily plan exists: %v", err)
+	}
+	if hasDailyPlan {
+		return getNextWordFromDailyPlan(ctx, db, userID, day)
+	}
+	return getNextDueWord(ctx, db, userID, source)
+}
+
+// getNextDueWord picks the next due or new word outside of a daily plan, optionally limited to
+// words from source.
+func getNextDueWord(ctx context.Context, db *pgxpool.Pool, userID uuid.UUID, source string) (*models.WordReviewCard, error) {
 	// Step 1: Find a contextual meaning to review.
 	contextualMeaningQuery := `
 		SELECT m.id, m.word_id, m.part_of_speech, m.definition, m.example_sentence, m.example_sentence_translation, w.lemma
@@ -58,17 +99,20 @@ func GetNextWordForReview(ctx context.Context, db *pgxpool.Pool, userID uuid.UUI
 		JOIN words w ON m.word_id = w.id
 		LEFT JOIN user_progress up ON m.id = up.meaning_id AND up.user_id = $1
 		WHERE ((up.user_id = $1 AND up.next_review_at <= $2) OR up.user_id IS NULL)
+		  AND ` + SchedulableCard + `
+		  AND m.id = tracked_meaning_id($1, m.id)
 	`
-	args := []interface{}{userID, time.Now()}
+	args := []interface{}{userID, time.Now(), siblingCutoff(ctx, db)}
 
 	if source != "" {
-		contextualMeaningQuery += " AND w.source = $3"
+		contextualMeaningQuery += " AND w.source = $4"
 		args = append(args, source)
 	}
-	contextualMeaningQuery += " ORDER BY up.next_review_at ASC NULLS LAST, random() LIMIT 1;"
+	// Siblings of a just-reviewed word go last.
+	contextualMeaningQuery += " ORDER BY " + siblingReviewedSince("$3") + ", up.next_review_at ASC NULLS LAST, random() LIMIT 1;"
 
 	var contextualMeaning models.Meaning
-	err = db.QueryRow(ctx, contextualMeaningQuery, args...).Scan(
+	err := db.QueryRow(ctx, contextualMeaningQuery, args...).Scan(
 		&contextualMeaning.ID,
 		&contextualMeaning.WordID,
 		&contextualMeaning.PartOfSpeech,
@@ -89,79 +133,37 @@ func GetNextWordForReview(ctx context.Context, db *pgxpool.Pool, userID uuid.UUI
 	return buildWordReviewCard(ctx, db, &contextualMeaning)
 }
 
-// PeekNextWordForReview previews the next word without marking it as shown.
-func PeekNextWordForReview(ctx context.Context, db *pgxpool.Pool, userID uuid.UUID, source string) (*models.WordReviewCard, error) {
-	// This function should ideally mirror the logic of GetNextWordForReview but without state changes.
-	// For now, it will prioritize the daily plan.
-	wordCard, err := peekNextWordFromDailyPlan(ctx, db, userID)
-	if err != nil {
-		if errors.Is(err, pgx.ErrNoRows) {
-			return nil, database.ErrNotFound
-		}
-		return nil, err
-	}
-	return wordCard, nil
-}
+// todaysPlanWordsCTE selects the meanings of the user's plan for the study day [$2, $3).
+// When a user has several plans on the same day (e.g. one generated from a study plan and one
+// created manually), they are merged: the day's plan is the union of their meanings.
+// In word mode each meaning is replaced by the meaning that tracks its word (see tracked_meaning_id).
+const todaysPlanWordsCTE = `
+	todays_plan_words AS (
+		SELECT DISTINCT tracked_meaning_id($1, dpw.meaning_id) AS meaning_id
+		FROM daily_plans dp
+		JOIN daily_plan_words dpw ON dpw.plan_id = dp.id
+		WHERE dp.user_id = $1 AND dp.created_at >= $2 AND dp.created_at < $3
+	)`
+
+// pendingPlanWordsQuery lists the meanings of today's plan not yet reviewed today, in plan order.
+// Suspended, buried and mastered cards are skipped; meanings whose sibling was reviewed after $4
+// are deferred to the end.
+var pendingPlanWordsQuery = `
+	WITH ` + todaysPlanWordsCTE + `
+	SELECT m.id, m.word_id, m.part_of_speech, m.definition, m.example_sentence, m.example_sentence_translation, w.lemma
+	FROM todays_plan_words tpw
+	JOIN meanings m ON m.id = tpw.meaning_id
+	JOIN words w ON m.word_id = w.id
+	LEFT JOIN user_progress up ON up.meaning_id = m.id AND up.user_id = $1
+	WHERE (up.last_reviewed_at IS NULL OR up.last_reviewed_at < $2)
+	  AND ` + SchedulableCard + `
+	ORDER BY ` + siblingReviewedSince("$4") + `, m.id
+`
 
 // getNextWordFromDailyPlan fetches the next word from the user's daily plan.
-func getNextWordFromDailyPlan(ctx context.Context, db *pgxpool.Pool, userID uuid.UUID) (*models.WordReviewCard, error) {
-	// This query finds the next un-reviewed meaning from the latest daily plan.
-	query := `
-		WITH latest_plan AS (
-			SELECT id FROM daily_plans
-			WHERE user_id = $1 AND created_at >= current_date
-			ORDER BY created_at DESC
-			LIMIT 1
-		),
-		reviewed_words AS (
-			SELECT dpw.meaning_id 
-			FROM daily_plan_words dpw
-			JOIN user_progress up ON dpw.meaning_id = up.meaning_id AND up.user_id = $1
-			WHERE dpw.plan_id = (SELECT id FROM latest_plan)
-			AND up.last_reviewed_at >= current_date
-		)
-		SELECT m.id, m.word_id, m.part_of_speech, m.definition, m.example_sentence, m.example_sentence_translation, w.lemma
-		FROM meanings m
-		JOIN words w ON m.word_id = w.id
-		JOIN daily_plan_words dpw ON m.id = dpw.meaning_id
-		LEFT JOIN reviewed_words rw ON m.id = rw.meaning_id
-		WHERE dpw.plan_id = (SELECT id FROM latest_plan)
-		  AND rw.meaning_id IS NULL
-		ORDER BY m.id
-		LIMIT 1;
-	`
+func getNextWordFromDailyPlan(ctx context.Context, db *pgxpool.Pool, userID uuid.UUID, day StudyDay) (*models.WordReviewCard, error) {
 	var contextualMeaning models.Meaning
-	err := db.QueryRow(ctx, query, userID).Scan(
-		&contextualMeaning.ID, &contextualMeaning.WordID, &contextualMeaning.PartOfSpeech, &contextualMeaning.Definition, &contextualMeaning.ExampleSentence, &contextualMeaning.ExampleSentenceTranslation, &contextualMeaning.Lemma,
-	)
-
-	if err != nil {
-		if errors.Is(err, pgx.ErrNoRows) {
-			return nil, database.ErrNotFound
-		}
-		return nil, err
-	}
-	return buildWordReviewCard(ctx, db, &contextualMeaning)
-}
-
-// peekNextWordFromDailyPlan peeks at the next word in the daily plan without updates.
-func peekNextWordFromDailyPlan(ctx context.Context, db *pgxpool.Pool, userID uuid.UUID) (*models.WordReviewCard, error) {
-	// This logic needs to be adapted to fetch a contextual meaning first, then build the card.
-	// For simplicity, we'll find the next pending item and use it as context.
-	query := `
-		SELECT m.id, m.word_id, m.part_of_speech, m.definition, m.example_sentence, m.example_sentence_translation, w.lemma
-		FROM daily_plan_items dpi
-		JOIN daily_plans dp ON dp.id = dpi.daily_plan_id
-		JOIN meanings m ON m.id = dpi.meaning_id
-		JOIN words w ON w.id = m.word_id
-		WHERE dp.user_id = $1 
-		AND dp.created_at = (SELECT MAX(created_at) FROM daily_plans WHERE user_id = $1)
-		AND dpi.status = 'pending'
-		ORDER BY dpi.order ASC
-		LIMIT 1
-	`
-	var contextualMeaning models.Meaning
-	err := db.QueryRow(ctx, query, userID).Scan(
+	err := db.QueryRow(ctx, pendingPlanWordsQuery+" LIMIT 1", userID, day.Start, day.End, siblingCutoff(ctx, db)).Scan(
 		&contextualMeaning.ID, &contextualMeaning.WordID, &contextualMeaning.PartOfSpeech, &contextualMeaning.Definition, &contextualMeaning.ExampleSentence, &contextualMeaning.ExampleSentenceTranslation, &contextualMeaning.Lemma,
 	)
 
@@ -225,15 +227,35 @@ func buildWordReviewCard(ctx context.Context, db *pgxpool.Pool, contextualMeanin
 }
 
 // UpdateProgress updates a user's progress for a specific meaning based on their self-assessment.
-func UpdateProgress(ctx context.Context, db *pgxpool.Pool, userID uuid.UUID, meaningID int, userChoice string) error {
+// responseMs is the time the user took to answer, nil if the client did not report it.
+func UpdateProgress(ctx context.Context, db *pgxpool.Pool, userID uuid.UUID, meaningID int, userChoice string, responseMs *int) error {
 	// 记录所有操作，帮助调试
 	log.Printf("Updating progress for user %s on meaning %d with choice: %s", userID, meaningID, userChoice)
 
-	// 获取使用的SRS算法
-	algorithm, err := GetSRSAlgorithm(ctx, db)
+	// 按单词模式下，进度记录在该单词的第一个词义上
+	if err := db.QueryRow(ctx, `SELECT tracked_meaning_id($1, $2)`, userID, meaningID).Scan(&meaningID); err != nil {
+		log.Printf("Error resolving tracked meaning for %d: %v", meaningID, err)
+		return err
+	}
+
+	// 获取该用户使用的SRS算法（进行中的实验可能为用户指定算法和参数）
+	scheduler, err := GetUserScheduler(ctx, db, userID)
 	if err != nil {
 		log.Printf("Error getting SRS algorithm: %v, using default (sspmmc)", err)
-		algorithm = "sspmmc" // 默认使用SSP-MMC
+		scheduler = UserScheduler{Algorithm: "sspmmc"} // 默认使用SSP-MMC
+	}
+	algorithm := scheduler.Algorithm
+
+	leech, err := GetLeechSettings(ctx, db)
+	if err != nil {
+		log.Printf("Error getting leech settings: %v, using defaults", err)
+		leech = defaultLeechSettings
+	}
+
+	scheduling, err := GetSchedulingSettings(ctx, db, userID)
+	if err != nil {
+		log.Printf("Error getting scheduling settings: %v", err)
+		return err
 	}
 
 	tx, err := db.Begin(ctx)
@@ -254,10 +276,12 @@ func UpdateProgress(ctx context.Context, db *pgxpool.Pool, userID uuid.UUID, mea
 
 	// 获取当前进度
 	var progress models.UserProgress
+	var lastReviewedAt *time.Time
 	query := `SELECT 
 		srs_stage, 
 		COALESCE(memory_halflife, 4.0) as memory_halflife,
-		COALESCE(review_count, 0) as review_count
+		COALESCE(review_count, 0) as review_count,
+		last_reviewed_at
 		FROM user_progress 
 		WHERE user_id = $1 AND meaning_id = $2;`
 
@@ -265,6 +289,7 @@ func UpdateProgress(ctx context.Context, db *pgxpool.Pool, userID uuid.UUID, mea
 		&progress.SRSStage,
 		&progress.MemoryHalfLife,
 		&progress.ReviewCount,
+		&lastReviewedAt,
 	)
 
 	if err != nil {
@@ -280,8 +305,29 @@ func UpdateProgress(ctx context.Context, db *pgxpool.Pool, userID uuid.UUID, mea
 	} else {
 		log.Printf("Found existing progress for user %s and meaning %d: stage %d", userID, meaningID, progress.SRSStage)
 	}
+	// 曾经记住过的词再次“不认识”记为一次遗忘（lapse）
+	isLapse := userChoice == "不认识" && progress.SRSStage > 0
+
+	// 实际经过的学习时间（扣除假期），用于给逾期后仍记住的词加分
+	var elapsed time.Duration
+	if lastReviewedAt != nil {
+		elapsed, err = studyElapsed(ctx, tx, userID, *lastReviewedAt, time.Now())
+		if err != nil {
+			log.Printf("Error computing elapsed study time: %v", err)
+			return err
+		}
+	}
+
+	logEntry := reviewLogEntry{UserID: userID, MeaningID: meaningID, UserChoice: userChoice,
+		ResponseMs: responseMs, Algorithm: &algorithm, ExperimentID: scheduler.ExperimentID, ArmID: scheduler.ArmID}
+	if lastReviewedAt != nil {
+		logEntry.Elapsed = &elapsed
+		halflife := progress.MemoryHalfLife
+		logEntry.MemoryHalfLife = &halflife
+	}
 
 	// 根据选择的算法更新进度
+	var nextReviewAt time.Time
 	if algorithm == "sspmmc" {
 		// 使用SSP-MMC算法
 		// 将单词意义ID和用户选择传递给sspmmc.go中的函数
@@ -289,7 +335,22 @@ func UpdateProgress(ctx context.Context, db *pgxpool.Pool, userID uuid.UUID, mea
 		progress.UserID = userID
 
 		// 调用SSP-MMC算法更新进度
-		UpdateProgressWithSSPMMC(&progress, &meaning, userChoice)
+		reviewedAt := time.Now()
+		UpdateProgressWithSSPMMC(&progress, &meaning, userChoice, elapsed.Hours())
+		newHalflife := progress.MemoryHalfLife
+		logEntry.NewHalfLife = &newHalflife
+
+		// 按实验参数重新计算间隔（无参数覆盖时与默认结果相同）
+		progress.OptimalInterval = optimalInterval(progress.MemoryHalfLife, scheduler.Params)
+		interval := modifyInterval(sspmmcReviewInterval(progress.OptimalInterval, progress.LastRecallSuccess), scheduler.Params)
+
+		// 间隔扰动与负载均衡，避免同批单词总在同一时间到期
+		progress.NextReviewAt, err = adjustDueDate(ctx, tx, userID, meaningID, progress.ReviewCount,
+			reviewedAt, interval, scheduling)
+		if err != nil {
+			log.Printf("Error adjusting next review time: %v", err)
+			return err
+		}
 
 		// Upsert progress with SSP-MMC fields
 		upsertQuery := `
@@ -305,7 +366,9 @@ func UpdateProgress(ctx context.Context, db *pgxpool.Pool, userID uuid.UUID, mea
 				memory_halflife = EXCLUDED.memory_halflife,
 				optimal_interval = EXCLUDED.optimal_interval,
 				review_count = EXCLUDED.review_count,
-				last_recall_success = EXCLUDED.last_recall_success;`
+				last_recall_success = EXCLUDED.last_recall_success,
+				card_state = ` + reviewedCardState + `,
+				buried_until = NULL;`
 
 		_, err = tx.Exec(ctx, upsertQuery,
 			userID, meaningID, progress.SRSStage, time.Now(), progress.NextReviewAt,
@@ -315,14 +378,23 @@ func UpdateProgress(ctx context.Context, db *pgxpool.Pool, userID uuid.UUID, mea
 			log.Printf("Error upserting progress with SSP-MMC: %v", err)
 			return err
 		}
+		nextReviewAt = progress.NextReviewAt
 	} else {
 		// 使用传统算法（向后兼容）
 		newStage := calculateNextStage(progress.SRSStage, userChoice)
+		if userChoice == "认识" {
+			newStage = lateRecallStage(newStage, elapsed)
+		}
 		log.Printf("Calculated new SRS stage: %d (from %d)", newStage, progress.SRSStage)
 
 		// Calculate next review interval
-		nextInterval := calculateNextInterval(newStage)
-		nextReviewAt := time.Now().Add(nextInterval)
+		nextInterval := modifyInterval(calculateNextInterval(newStage), scheduler.Params)
+		nextReviewAt, err = adjustDueDate(ctx, tx, userID, meaningID, progress.ReviewCount,
+			time.Now(), nextInterval, scheduling)
+		if err != nil {
+			log.Printf("Error adjusting next review time: %v", err)
+			return err
+		}
 		log.Printf("Next review scheduled at: %v (in %v)", nextReviewAt, nextInterval)
 
 		// Upsert progress
@@ -332,7 +404,9 @@ func UpdateProgress(ctx context.Context, db *pgxpool.Pool, userID uuid.UUID, mea
 			ON CONFLICT (user_id, meaning_id) DO UPDATE SET
 				srs_stage = EXCLUDED.srs_stage,
 				last_reviewed_at = EXCLUDED.last_reviewed_at,
-				next_review_at = EXCLUDED.next_review_at;`
+				next_review_at = EXCLUDED.next_review_at,
+				card_state = ` + reviewedCardState + `,
+				buried_until = NULL;`
 
 		_, err = tx.Exec(ctx, upsertQuery, userID, meaningID, newStage, time.Now(), nextReviewAt)
 		if err != nil {
@@ -341,6 +415,24 @@ func UpdateProgress(ctx context.Context, db *pgxpool.Pool, userID uuid.UUID, mea
 		}
 	}
 
+	if err := logReview(ctx, tx, logEntry); err != nil {
+		log.Printf("Error writing review log: %v", err)
+		return err
+	}
+
+	if isLapse {
+		if err := recordLapse(ctx, tx, userID, meaningID, leech); err != nil {
+			log.Printf("Error recording lapse: %v", err)
+			return err
+		}
+	}
+
+	// Webhook 事件写入发件箱，与进度一起提交
+	if err := enqueueReviewWebhooks(ctx, tx, userID, meaning, userChoice, nextReviewAt); err != nil {
+		log.Printf("Error queueing webhook events: %v", err)
+		return err
+	}
+
 	err = tx.Commit(ctx)
 	if err != nil {
 		log.Printf("Error committing transaction: %v", err)
@@ -365,6 +457,15 @@ func calculateNextStage(currentStage int, choice string) int {
 	}
 }
 
+// lateRecallStage raises a stage after a successful review until its interval is at least the time
+// the word was actually remembered, so a late success is not treated like an on-time one.
+func lateRecallStage(stage int, elapsed time.Duration) int {
+	for calculateNextInterval(stage) < elapsed {
+		stage++
+	}
+	return stage
+}
+
 // calculateNextInterval calculates the duration until the next review.
 // This is a simplified SRS interval calculation.
 func calculateNextInterval(stage int) time.Duration {
@@ -442,16 +543,16 @@ func extractPartOfSpeech(definition string) string {
 }
 
 // checkDailyPlanExists 检查用户是否有当日学习计划
-func checkDailyPlanExists(ctx context.Context, db *pgxpool.Pool, userID uuid.UUID) (bool, error) {
+func checkDailyPlanExists(ctx context.Context, db *pgxpool.Pool, userID uuid.UUID, day StudyDay) (bool, error) {
 	query := `
 		SELECT EXISTS(
 			SELECT 1 FROM daily_plans 
-			WHERE user_id = $1 AND created_at >= current_date
+			WHERE user_id = $1 AND created_at >= $2 AND created_at < $3
 		)
 	`
 
 	var exists bool
-	err := db.QueryRow(ctx, query, userID).Scan(&exists)
+	err := db.QueryRow(ctx, query, userID, day.Start, day.End).Scan(&exists)
 	if err != nil {
 		return false, err
 	}
@@ -459,44 +560,19 @@ func checkDailyPlanExists(ctx context.Context, db *pgxpool.Pool, userID uuid.UUI
 	return exists, nil
 }
 
-// getDailyPlanProgress 获取当日计划的进度
-func getDailyPlanProgress(ctx context.Context, db *pgxpool.Pool, userID uuid.UUID) (int, int, error) {
-	// 获取今日计划中的单词总数
-	totalQuery := `
-		WITH latest_plan AS (
-			SELECT id FROM daily_plans
-			WHERE user_id = $1 AND created_at >= current_date
-			ORDER BY created_at DESC
-			LIMIT 1
-		)
-		SELECT COUNT(*) AS total_words
-		FROM daily_plan_words
-		WHERE plan_id = (SELECT id FROM latest_plan)
-	`
-
-	var totalWords int
-	err := db.QueryRow(ctx, totalQuery, userID).Scan(&totalWords)
-	if err != nil {
-		return 0, 0, err
-	}
-
-	// 获取今日已完成的单词数量
-	completedQuery := `
-		WITH latest_plan AS (
-			SELECT id FROM daily_plans
-			WHERE user_id = $1 AND created_at >= current_date
-			ORDER BY created_at DESC
-			LIMIT 1
-		)
-		SELECT COUNT(*) AS completed_words
-		FROM daily_plan_words dpw
-		JOIN user_progress up ON dpw.meaning_id = up.meaning_id AND up.user_id = $1
-		WHERE dpw.plan_id = (SELECT id FROM latest_plan)
-		  AND up.last_reviewed_at >= current_date
+// GetDailyPlanProgress 获取当日计划的进度，返回 (已完成, 总数)
+// 同一天的多个计划会合并计算（见 todaysPlanWordsCTE）；暂停、搁置和已掌握的未完成单词不计入总数
+func GetDailyPlanProgress(ctx context.Context, db querier, userID uuid.UUID, day StudyDay) (int, int, error) {
+	query := `
+		WITH ` + todaysPlanWordsCTE + `
+		SELECT COUNT(*) FILTER (WHERE up.last_reviewed_at >= $2 OR ` + SchedulableCard + `) AS total_words,
+		       COUNT(*) FILTER (WHERE up.last_reviewed_at >= $2) AS completed_words
+		FROM todays_plan_words tpw
+		LEFT JOIN user_progress up ON up.meaning_id = tpw.meaning_id AND up.user_id = $1
 	`
 
-	var completedWords int
-	err = db.QueryRow(ctx, completedQuery, userID).Scan(&completedWords)
+	var totalWords, completedWords int
+	err := db.QueryRow(ctx, query, userID, day.Start, day.End).Scan(&totalWords, &completedWords)
 	if err != nil {
 		return 0, 0, err
 	}
@@ -83,7 +83,8 @@ func calculateOptimalInterval(halflife float64) float64 {
 }
 
 // 更新用户进度
-func UpdateProgressWithSSPMMC(progress *models.UserProgress, meaning *models.Meaning, userChoice string) {
+// elapsedHours 为距上次复习的实际学习时间（不含假期），新词为 0
+func UpdateProgressWithSSPMMC(progress *models.UserProgress, meaning *models.Meaning, userChoice string, elapsedHours float64) {
 	// 将用户选择转换为布尔值表示记忆是否成功
 	recallSuccess := userChoice == "认识"
 	partialSuccess := userChoice == "模糊"
@@ -139,6 +140,11 @@ func UpdateProgressWithSSPMMC(progress *models.UserProgress, meaning *models.Mea
 		newHalflife = newHalflife * 0.8
 	}
 
+	// 逾期后仍然记住，说明记忆至少保持了这么久：半衰期不低于实际间隔
+	if recallSuccess && elapsedHours > newHalflife {
+		newHalflife = math.Min(elapsedHours, maxInterval)
+	}
+
 	// 更新记忆模型参数
 	progress.MemoryHalfLife = newHalflife
 	progress.LastRecallSuccess = recallSuccess
@@ -148,15 +154,7 @@ func UpdateProgressWithSSPMMC(progress *models.UserProgress, meaning *models.Mea
 	progress.OptimalInterval = optimalInterval
 
 	// 更新下次复习时间
-	// 如果记忆成功，使用计算的最佳间隔
-	// 如果记忆失败，我们采用快速复习策略
-	if recallSuccess {
-		progress.NextReviewAt = time.Now().Add(time.Duration(optimalInterval * float64(time.Hour)))
-	} else {
-		// 记忆失败时使用较短的间隔（例如25%的最佳间隔）
-		shortInterval := math.Max(minInterval, optimalInterval*0.25)
-		progress.NextReviewAt = time.Now().Add(time.Duration(shortInterval * float64(time.Hour)))
-	}
+	progress.NextReviewAt = time.Now().Add(sspmmcReviewInterval(optimalInterval, recallSuccess))
 
 	// 更新SRS阶段（向后兼容）
 	if recallSuccess {
@@ -172,6 +170,18 @@ func UpdateProgressWithSSPMMC(progress *models.UserProgress, meaning *models.Mea
 	}
 }
 
+// 计算下次复习的间隔
+// 如果记忆成功，使用计算的最佳间隔
+// 如果记忆失败，我们采用快速复习策略
+func sspmmcReviewInterval(optimalInterval float64, recallSuccess bool) time.Duration {
+	if recallSuccess {
+		return time.Duration(optimalInterval * float64(time.Hour))
+	}
+	// 记忆失败时使用较短的间隔（例如25%的最佳间隔）
+	shortInterval := math.Max(minInterval, optimalInterval*0.25)
+	return time.Duration(shortInterval * float64(time.Hour))
+}
+
 // 获取SSP-MMC算法的介绍信息
 func GetSSPMMCInfo() string {
 	return `SSP-MMC (Stochastic-Shortest-Path-Minimize-Memorization-Cost) 是由墨墨背单词开发的
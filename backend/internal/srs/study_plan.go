@@ -0,0 +1,199 @@
+package srs
+
+import (
+	"context"
+	"errors"
+	"log"
+
+	"sentencease/backend/internal/models"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// unitOrder sorts meanings by the number in their unit ("Unit 2" before "Unit 10"),
+// falling back to the unit name and meaning ID.
+const unitOrder = `NULLIF(regexp_replace(COALESCE(m.unit, ''), '\D', '', 'g'), '')::int NULLS LAST, m.unit, m.id`
+
+// EnsureDailyPlans generates today's daily plan for every active study plan and open group
+// assignment of the user that does not have one yet. It is called lazily on the first learning
+// request of the day. No plans are generated on vacation days.
+func EnsureDailyPlans(ctx context.Context, db *pgxpool.Pool, userID uuid.UUID, day StudyDay) error {
+	// 假期中不生成新的每日计划，未学的词在假期后顺延
+	if on, err := onVacation(ctx, db, userID, day); err != nil || on {
+		return err
+	}
+
+	rows, err := db.Query(ctx,
+		`SELECT id, source, words_per_day FROM study_plans WHERE user_id = $1 AND status = $2 ORDER BY created_at`,
+		userID, models.StudyPlanActive)
+	if err != nil {
+		return err
+	}
+	plans, err := pgx.CollectRows(rows, func(row pgx.CollectableRow) (models.StudyPlan, error) {
+		var p models.StudyPlan
+		err := row.Scan(&p.ID, &p.Source, &p.WordsPerDay)
+		return p, err
+	})
+	if err != nil {
+		return err
+	}
+
+	for _, plan := range plans {
+		if err := generateDailyPlan(ctx, db, userID, plan, day); err != nil {
+			log.Printf("Error generating daily plan from study plan %s for user %s: %v", plan.ID, userID, err)
+		}
+	}
+	return ensureAssignmentPlans(ctx, db, userID, day)
+}
+
+// generateDailyPlan creates the daily plan for one study plan and study day.
+// Words of earlier days that were never studied roll over and count toward the daily quota,
+// so a missed day does not pile up; the rest of the quota is filled with new words in unit order.
+func generateDailyPlan(ctx context.Context, db *pgxpool.Pool, userID uuid.UUID, plan models.StudyPlan, day StudyDay) error {
+	tx, err := db.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	var exists bool
+	err = tx.QueryRow(ctx,
+		`SELECT EXISTS(SELECT 1 FROM daily_plans WHERE study_plan_id = $1 AND plan_date = $2)`,
+		plan.ID, day.Date()).Scan(&exists)
+	if err != nil || exists {
+		return err
+	}
+
+	// 1. 未完成的旧词顺延到今天
+	rolloverQuery := `
+		SELECT DISTINCT tracked_meaning_id($3, dpw.meaning_id) AS meaning_id
+		FROM daily_plans dp
+		JOIN daily_plan_words dpw ON dpw.plan_id = dp.id
+		WHERE dp.study_plan_id = $1 AND dp.plan_date < $2
+		  AND NOT ` + hasStudied("$3", "dpw.meaning_id") + `
+		ORDER BY 1
+		LIMIT $4
+	`
+	rows, err := tx.Query(ctx, rolloverQuery, plan.ID, day.Date(), userID, plan.WordsPerDay)
+	if err != nil {
+		return err
+	}
+	meaningIDs, err := pgx.CollectRows(rows, pgx.RowTo[int])
+	if err != nil {
+		return err
+	}
+	rolledOver := len(meaningIDs)
+
+	// 2. 按单元顺序补充新词
+	if remaining := plan.WordsPerDay - len(meaningIDs); remaining > 0 {
+		newWordsQuery := `
+			SELECT m.id
+			FROM meanings m
+			JOIN words w ON w.id = m.word_id
+			WHERE w.source = $1
+			  AND m.id = tracked_meaning_id($2, m.id)
+			  AND NOT ` + hasStudied("$2", "m.id") + `
+			  AND NOT EXISTS (
+				SELECT 1 FROM daily_plan_words dpw
+				JOIN daily_plans dp ON dp.id = dpw.plan_id
+				WHERE dp.study_plan_id = $3 AND dpw.meaning_id = m.id
+			  )
+			ORDER BY ` + unitOrder + `
+			LIMIT $4
+		`
+		rows, err := tx.Query(ctx, newWordsQuery, plan.Source, userID, plan.ID, remaining)
+		if err != nil {
+			return err
+		}
+		newIDs, err := pgx.CollectRows(rows, pgx.RowTo[int])
+		if err != nil {
+			return err
+		}
+		meaningIDs = append(meaningIDs, newIDs...)
+	}
+
+	// 3. 没有剩余单词，计划完成
+	if len(meaningIDs) == 0 {
+		_, err = tx.Exec(ctx,
+			`UPDATE study_plans SET status = $2, completed_at = now() WHERE id = $1`,
+			plan.ID, models.StudyPlanCompleted)
+		if err != nil {
+			return err
+		}
+		log.Printf("Study plan %s for user %s is finished", plan.ID, userID)
+		return tx.Commit(ctx)
+	}
+
+	var planID uuid.UUID
+	err = tx.QueryRow(ctx,
+		`INSERT INTO daily_plans (user_id, study_plan_id, plan_date) VALUES ($1, $2, $3)
+		 ON CONFLICT (study_plan_id, plan_date) DO NOTHING
+		 RETURNING id`,
+		userID, plan.ID, day.Date()).Scan(&planID)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			// A concurrent request generated the plan first.
+			return nil
+		}
+		return err
+	}
+
+	_, err = tx.Exec(ctx,
+		`INSERT INTO daily_plan_words (plan_id, meaning_id) SELECT $1, unnest($2::int[])`,
+		planID, meaningIDs)
+	if err != nil {
+		return err
+	}
+
+	log.Printf("Generated daily plan %s for user %s from study plan %s: %d words (%d rolled over)",
+		planID, userID, plan.ID, len(meaningIDs), rolledOver)
+	return tx.Commit(ctx)
+}
+
+// ProjectStudyPlan fills in the word counts and the projected completion date of a study plan.
+// The projection assumes the user finishes every daily plan from today on.
+func ProjectStudyPlan(ctx context.Context, db *pgxpool.Pool, userID uuid.UUID, plan *models.StudyPlan, day StudyDay) error {
+	query := `
+		SELECT COUNT(*),
+		       COUNT(*) FILTER (WHERE NOT ` + hasStudied("$2", "m.id") + `)
+		FROM meanings m
+		JOIN words w ON w.id = m.word_id
+		WHERE w.source = $1 AND m.id = tracked_meaning_id($2, m.id)
+	`
+	if err := db.QueryRow(ctx, query, plan.Source, userID).Scan(&plan.TotalWords, &plan.RemainingWords); err != nil {
+		return err
+	}
+
+	plan.ProjectedCompletionDate = nil
+	switch {
+	case plan.Status == models.StudyPlanCompleted && plan.CompletedAt != nil:
+		date := plan.CompletedAt.In(day.Location).Format("2006-01-02")
+		plan.ProjectedCompletionDate = &date
+	case plan.Status == models.StudyPlanActive && plan.RemainingWords > 0:
+		// If today's words are already done, the remaining words start tomorrow.
+		var todayPending bool
+		err := db.QueryRow(ctx, `
+			SELECT NOT EXISTS(SELECT 1 FROM daily_plans WHERE study_plan_id = $1 AND plan_date = $2)
+			    OR EXISTS(
+				SELECT 1 FROM daily_plans dp
+				JOIN daily_plan_words dpw ON dpw.plan_id = dp.id
+				WHERE dp.study_plan_id = $1 AND dp.plan_date = $2
+				  AND NOT `+hasStudied("$3", "dpw.meaning_id")+`
+			)`, plan.ID, day.Date(), userID).Scan(&todayPending)
+		if err != nil {
+			return err
+		}
+
+		days := (plan.RemainingWords + plan.WordsPerDay - 1) / plan.WordsPerDay
+		first := day
+		if !todayPending {
+			first = day.AddDays(1)
+		}
+		date := first.AddDays(days - 1).Date()
+		plan.ProjectedCompletionDate = &date
+	}
+
+	return nil
+}
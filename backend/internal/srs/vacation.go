@@ -0,0 +1,206 @@
+package srs
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"sentencease/backend/internal/database"
+	"sentencease/backend/internal/models"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// MaxVacationDays is the longest vacation that can be taken at once.
+const MaxVacationDays = 90
+
+var (
+	// ErrVacationInPast is returned when a vacation would start before the current study day.
+	ErrVacationInPast = errors.New("vacation cannot start before today")
+	// ErrVacationInvalidRange is returned when a vacation ends before it starts or is too long.
+	ErrVacationInvalidRange = errors.New("vacation must end on or after its start date and last at most 90 days")
+	// ErrVacationOverlap is returned when a vacation overlaps another vacation of the user.
+	ErrVacationOverlap = errors.New("vacation overlaps an existing vacation")
+	// ErrVacationOver is returned when cancelling a vacation that has already ended.
+	ErrVacationOver = errors.New("vacation has already ended")
+)
+
+const vacationColumns = `id, to_char(start_date, 'YYYY-MM-DD'), to_char(end_date, 'YYYY-MM-DD'), starts_at, ends_at, created_at`
+
+// studyDayOf returns the study day with the given calendar date (YYYY-MM-DD).
+func studyDayOf(date string, loc *time.Location, dayStartHour int) (StudyDay, error) {
+	d, err := time.ParseInLocation("2006-01-02", date, loc)
+	if err != nil {
+		return StudyDay{}, err
+	}
+	start := time.Date(d.Year(), d.Month(), d.Day(), dayStartHour, 0, 0, 0, loc)
+	return StudyDay{Start: start, End: start.AddDate(0, 0, 1), Location: loc}, nil
+}
+
+// studyElapsed returns the time between from and to that was not spent on vacation.
+func studyElapsed(ctx context.Context, db querier, userID uuid.UUID, from, to time.Time) (time.Duration, error) {
+	var vacationSeconds float64
+	err := db.QueryRow(ctx, `
+		SELECT COALESCE(SUM(EXTRACT(EPOCH FROM LEAST(ends_at, $3) - GREATEST(starts_at, $2))), 0)::float8
+		FROM vacations
+		WHERE user_id = $1 AND starts_at < $3 AND ends_at > $2`, userID, from, to).Scan(&vacationSeconds)
+	if err != nil {
+		return 0, err
+	}
+	elapsed := to.Sub(from) - time.Duration(vacationSeconds*float64(time.Second))
+	if elapsed < 0 {
+		return 0, nil
+	}
+	return elapsed, nil
+}
+
+// onVacation reports whether the study day falls within one of the user's vacations.
+func onVacation(ctx context.Context, db querier, userID uuid.UUID, day StudyDay) (bool, error) {
+	var on bool
+	err := db.QueryRow(ctx,
+		`SELECT EXISTS(SELECT 1 FROM vacations WHERE user_id = $1 AND starts_at <= $2 AND ends_at > $2)`,
+		userID, day.Start).Scan(&on)
+	return on, err
+}
+
+// ListVacations returns the user's vacations, latest first.
+func ListVacations(ctx context.Context, db *pgxpool.Pool, userID uuid.UUID) ([]models.Vacation, error) {
+	rows, err := db.Query(ctx,
+		`SELECT `+vacationColumns+` FROM vacations WHERE user_id = $1 ORDER BY start_date DESC`, userID)
+	if err != nil {
+		return nil, err
+	}
+	return pgx.CollectRows(rows, pgx.RowToStructByPos[models.Vacation])
+}
+
+// CreateVacation pauses the user's review clock from the start of startDate to the end of endDate.
+// Reviews due at or after the start are postponed by the length of the vacation, and the time away
+// does not count as elapsed time when the words are next reviewed.
+func CreateVacation(ctx context.Context, db *pgxpool.Pool, userID uuid.UUID, startDate, endDate string) (*models.Vacation, error) {
+	loc, dayStartHour, err := GetUserDayConfig(ctx, db, userID)
+	if err != nil {
+		return nil, err
+	}
+	first, err := studyDayOf(startDate, loc, dayStartHour)
+	if err != nil {
+		return nil, err
+	}
+	last, err := studyDayOf(endDate, loc, dayStartHour)
+	if err != nil {
+		return nil, err
+	}
+
+	today := StudyDayAt(time.Now(), loc, dayStartHour)
+	if first.Start.Before(today.Start) {
+		return nil, ErrVacationInPast
+	}
+	if last.Start.Before(first.Start) || last.Start.After(first.AddDays(MaxVacationDays-1).Start) {
+		return nil, ErrVacationInvalidRange
+	}
+
+	tx, err := db.Begin(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback(ctx)
+
+	// 串行化同一用户的假期操作，避免并发创建重叠的假期
+	if _, err := tx.Exec(ctx, `SELECT 1 FROM users WHERE id = $1 FOR UPDATE`, userID); err != nil {
+		return nil, err
+	}
+
+	var overlap bool
+	err = tx.QueryRow(ctx,
+		`SELECT EXISTS(SELECT 1 FROM vacations WHERE user_id = $1 AND starts_at < $3 AND ends_at > $2)`,
+		userID, first.Start, last.End).Scan(&overlap)
+	if err != nil {
+		return nil, err
+	}
+	if overlap {
+		return nil, ErrVacationOverlap
+	}
+
+	rows, err := tx.Query(ctx, `
+		INSERT INTO vacations (user_id, start_date, end_date, starts_at, ends_at)
+		VALUES ($1, $2, $3, $4, $5)
+		RETURNING `+vacationColumns,
+		userID, startDate, endDate, first.Start, last.End)
+	if err != nil {
+		return nil, err
+	}
+	vacation, err := pgx.CollectOneRow(rows, pgx.RowToStructByPos[models.Vacation])
+	if err != nil {
+		return nil, err
+	}
+
+	// 假期开始后到期的复习整体顺延假期时长
+	_, err = tx.Exec(ctx, `
+		UPDATE user_progress SET next_review_at = next_review_at + ($3::timestamptz - $2::timestamptz)
+		WHERE user_id = $1 AND next_review_at >= $2`,
+		userID, vacation.StartsAt, vacation.EndsAt)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, err
+	}
+	return &vacation, nil
+}
+
+// CancelVacation ends a vacation early. A vacation that has not started yet is removed; one in
+// progress ends yesterday. Reviews postponed beyond the new end are brought back by the unused days;
+// cards reviewed since the vacation was created or started were not postponed and keep their dates.
+func CancelVacation(ctx context.Context, db *pgxpool.Pool, userID, vacationID uuid.UUID) error {
+	day, err := GetStudyDay(ctx, db, userID)
+	if err != nil {
+		return err
+	}
+
+	tx, err := db.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	var startsAt, endsAt, createdAt time.Time
+	err = tx.QueryRow(ctx,
+		`SELECT starts_at, ends_at, created_at FROM vacations WHERE id = $1 AND user_id = $2 FOR UPDATE`,
+		vacationID, userID).Scan(&startsAt, &endsAt, &createdAt)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return database.ErrNotFound
+		}
+		return err
+	}
+	if !endsAt.After(day.Start) {
+		return ErrVacationOver
+	}
+
+	// 假期的新结束时间：未开始的假期整体取消，进行中的假期到昨天为止
+	newEnd := startsAt
+	if startsAt.Before(day.Start) {
+		newEnd = day.Start
+		_, err = tx.Exec(ctx, `UPDATE vacations SET end_date = $2, ends_at = $3 WHERE id = $1`,
+			vacationID, day.AddDays(-1).Date(), newEnd)
+	} else {
+		_, err = tx.Exec(ctx, `DELETE FROM vacations WHERE id = $1`, vacationID)
+	}
+	if err != nil {
+		return err
+	}
+
+	// 只把创建假期时顺延过的复习提前：之后（包括假期中）复习过的词已按复习时间重新安排
+	_, err = tx.Exec(ctx, `
+		UPDATE user_progress SET next_review_at = next_review_at - ($3::timestamptz - $2::timestamptz)
+		WHERE user_id = $1 AND next_review_at >= $3
+		  AND (last_reviewed_at IS NULL OR last_reviewed_at < LEAST($4::timestamptz, $5::timestamptz))`,
+		userID, newEnd, endsAt, startsAt, createdAt)
+	if err != nil {
+		return err
+	}
+
+	return tx.Commit(ctx)
+}
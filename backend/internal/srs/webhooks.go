@@ -0,0 +1,58 @@
+package srs
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+
+	"sentencease/backend/internal/models"
+	"sentencease/backend/internal/webhook"
+)
+
+// reviewCreatedData is the data of a review.created webhook event.
+type reviewCreatedData struct {
+	MeaningID    int       `json:"meaningId"`
+	WordID       int       `json:"wordId"`
+	Choice       string    `json:"choice"`
+	ReviewedAt   time.Time `json:"reviewedAt"`
+	NextReviewAt time.Time `json:"nextReviewAt"`
+}
+
+// planCompletedData is the data of a plan.completed webhook event.
+type planCompletedData struct {
+	Date  string `json:"date"`
+	Words int    `json:"words"`
+}
+
+// enqueueReviewWebhooks queues the webhook events of a review in its transaction: review.created,
+// and plan.completed once per study day when the review finishes the day's plan.
+func enqueueReviewWebhooks(ctx context.Context, tx pgx.Tx, userID uuid.UUID, meaning models.Meaning, choice string, nextReviewAt time.Time) error {
+	err := webhook.Enqueue(ctx, tx, userID, models.WebhookReviewCreated, "", reviewCreatedData{
+		MeaningID:    meaning.ID,
+		WordID:       meaning.WordID,
+		Choice:       choice,
+		ReviewedAt:   time.Now(),
+		NextReviewAt: nextReviewAt,
+	})
+	if err != nil {
+		return err
+	}
+
+	// 只有存在订阅时才计算每日计划进度
+	subscribed, err := webhook.Subscribed(ctx, tx, userID, models.WebhookPlanCompleted)
+	if err != nil || !subscribed {
+		return err
+	}
+	day, err := GetStudyDay(ctx, tx, userID)
+	if err != nil {
+		return err
+	}
+	completed, total, err := GetDailyPlanProgress(ctx, tx, userID, day)
+	if err != nil || total == 0 || completed < total {
+		return err
+	}
+	return webhook.Enqueue(ctx, tx, userID, models.WebhookPlanCompleted, day.Date(),
+		planCompletedData{Date: day.Date(), Words: total})
+}
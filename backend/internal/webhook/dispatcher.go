@@ -0,0 +1,176 @@
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"sentencease/backend/internal/models"
+	"sentencease/backend/internal/outbound"
+)
+
+// MaxAttempts is how many times a delivery is tried before it is marked failed. With the backoff
+// below the last attempt happens about 8 hours after the first.
+const MaxAttempts = 10
+
+// claimLease is how long a claimed delivery is hidden from other dispatchers. It must exceed the
+// HTTP timeout so a delivery in flight is not sent twice.
+const claimLease = time.Minute
+
+// backoff returns the delay before retrying a delivery that failed attempts times: 1 minute,
+// doubling up to 4 hours.
+func backoff(attempts int) time.Duration {
+	d := time.Minute
+	for i := 1; i < attempts && d < 4*time.Hour; i++ {
+		d *= 2
+	}
+	return min(d, 4*time.Hour)
+}
+
+// Dispatcher posts queued deliveries. Several dispatchers may run at once; each delivery is
+// claimed by one of them.
+type Dispatcher struct {
+	DB         *pgxpool.Pool
+	HTTPClient *http.Client
+	BatchSize  int
+}
+
+// NewDispatcher creates a dispatcher with a request timeout. Its client only connects to public
+// addresses and does not follow redirects, since webhook URLs are supplied by users.
+func NewDispatcher(db *pgxpool.Pool) *Dispatcher {
+	return &Dispatcher{DB: db, HTTPClient: outbound.NewClient(15 * time.Second), BatchSize: 50}
+}
+
+type delivery struct {
+	id        int64
+	attempts  int
+	eventID   string
+	eventType string
+	url       string
+	secret    string
+	payload   []byte
+}
+
+// RunOnce sends the deliveries that are due, batch by batch, until none are left. It returns the
+// number of deliveries attempted.
+func (d *Dispatcher) RunOnce(ctx context.Context) (int, error) {
+	total := 0
+	for {
+		batch, err := d.claim(ctx)
+		if err != nil {
+			return total, err
+		}
+		for _, del := range batch {
+			d.deliver(ctx, del)
+		}
+		total += len(batch)
+		if len(batch) < d.BatchSize || ctx.Err() != nil {
+			return total, ctx.Err()
+		}
+	}
+}
+
+// claim locks a batch of due deliveries of active webhooks by moving their next attempt past the
+// lease.
+func (d *Dispatcher) claim(ctx context.Context) ([]delivery, error) {
+	rows, err := d.DB.Query(ctx, `
+		UPDATE webhook_deliveries d
+		SET next_attempt_at = now() + make_interval(secs => $2)
+		FROM webhooks w, webhook_events e
+		WHERE d.id IN (
+			SELECT d2.id FROM webhook_deliveries d2
+			JOIN webhooks w2 ON w2.id = d2.webhook_id
+			WHERE d2.status = $3 AND d2.next_attempt_at <= now() AND w2.active
+			ORDER BY d2.next_attempt_at
+			LIMIT $1
+			FOR UPDATE OF d2 SKIP LOCKED
+		)
+		  AND w.id = d.webhook_id AND e.id = d.event_id
+		RETURNING d.id, d.attempts, e.id::text, e.type, w.url, w.secret, e.payload::text
+	`, d.BatchSize, claimLease.Seconds(), models.DeliveryPending)
+	if err != nil {
+		return nil, err
+	}
+	return pgx.CollectRows(rows, func(row pgx.CollectableRow) (delivery, error) {
+		var del delivery
+		var payload string
+		err := row.Scan(&del.id, &del.attempts, &del.eventID, &del.eventType, &del.url, &del.secret, &payload)
+		del.payload = []byte(payload)
+		return del, err
+	})
+}
+
+// deliver posts one delivery, logs the attempt and schedules a retry if it failed.
+func (d *Dispatcher) deliver(ctx context.Context, del delivery) {
+	started := time.Now()
+	statusCode, sendErr := d.post(ctx, del)
+	duration := time.Since(started)
+
+	var errText *string
+	if sendErr != nil {
+		s := sendErr.Error()
+		errText = &s
+	}
+	_, err := d.DB.Exec(ctx, `
+		INSERT INTO webhook_delivery_attempts (delivery_id, status_code, error, duration_ms)
+		VALUES ($1, $2, $3, $4)
+	`, del.id, statusCode, errText, duration.Milliseconds())
+	if err != nil {
+		log.Printf("Webhooks: Error logging attempt for delivery %d: %v", del.id, err)
+	}
+
+	attempts := del.attempts + 1
+	if sendErr == nil {
+		_, err = d.DB.Exec(ctx, `
+			UPDATE webhook_deliveries
+			SET status = $2, attempts = $3, last_error = NULL, delivered_at = now()
+			WHERE id = $1
+		`, del.id, models.DeliveryDelivered, attempts)
+	} else {
+		status := models.DeliveryPending
+		if attempts >= MaxAttempts {
+			status = models.DeliveryFailed
+		}
+		_, err = d.DB.Exec(ctx, `
+			UPDATE webhook_deliveries
+			SET status = $2, attempts = $3, last_error = $4, next_attempt_at = now() + make_interval(secs => $5)
+			WHERE id = $1
+		`, del.id, status, attempts, errText, backoff(attempts).Seconds())
+	}
+	if err != nil {
+		log.Printf("Webhooks: Error updating delivery %d: %v", del.id, err)
+	}
+}
+
+// post sends the payload and returns the response status, nil if no response was received.
+func (d *Dispatcher) post(ctx context.Context, del delivery) (*int, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, del.url, bytes.NewReader(del.payload))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-Webhook-Event", del.eventType)
+	req.Header.Set("X-Webhook-Event-ID", del.eventID)
+	req.Header.Set("X-Webhook-Delivery", strconv.FormatInt(del.id, 10))
+	outbound.Sign(req, del.secret, del.payload)
+
+	resp, err := d.HTTPClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10))
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return &resp.StatusCode, fmt.Errorf("webhook responded with status %d", resp.StatusCode)
+	}
+	return &resp.StatusCode, nil
+}
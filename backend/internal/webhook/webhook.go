@@ -0,0 +1,73 @@
+// Package webhook delivers learning events to user- and admin-registered URLs.
+//
+// Events are written to an outbox (webhook_events and one webhook_deliveries row per subscribed
+// webhook) in the same transaction as the change that caused them, so an event is queued if and
+// only if the change is committed. A Dispatcher then posts them, retrying with exponential backoff.
+package webhook
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+// DB is satisfied by both *pgxpool.Pool and pgx.Tx.
+type DB interface {
+	Exec(ctx context.Context, sql string, arguments ...any) (pgconn.CommandTag, error)
+	QueryRow(ctx context.Context, sql string, args ...any) pgx.Row
+}
+
+// Event is the JSON body posted to webhooks.
+type Event struct {
+	ID        uuid.UUID `json:"id"`
+	Type      string    `json:"type"`
+	UserID    uuid.UUID `json:"userId"`
+	CreatedAt time.Time `json:"createdAt"`
+	Data      any       `json:"data"`
+}
+
+// subscribedWebhooks matches the active webhooks that receive events of type $2 for user $1: the
+// user's own webhooks and every admin webhook.
+const subscribedWebhooks = `
+	SELECT w.id FROM webhooks w
+	WHERE w.active AND $2 = ANY(w.events) AND (w.user_id = $1 OR w.user_id IS NULL)`
+
+// Subscribed reports whether any webhook receives events of the type for the user. Callers use it
+// to skip computing events nobody listens to.
+func Subscribed(ctx context.Context, db DB, userID uuid.UUID, eventType string) (bool, error) {
+	var subscribed bool
+	err := db.QueryRow(ctx, `SELECT EXISTS(`+subscribedWebhooks+`)`, userID, eventType).Scan(&subscribed)
+	return subscribed, err
+}
+
+// Enqueue queues an event for every webhook subscribed to it. Pass the transaction of the change
+// the event reports. Events with a key are queued at most once per user, type and key; nothing is
+// written when no webhook is subscribed.
+func Enqueue(ctx context.Context, db DB, userID uuid.UUID, eventType, key string, data any) error {
+	event := Event{ID: uuid.New(), Type: eventType, UserID: userID, CreatedAt: time.Now(), Data: data}
+	payload, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+	var eventKey *string
+	if key != "" {
+		eventKey = &key
+	}
+
+	_, err = db.Exec(ctx, `
+		WITH subscribed AS (`+subscribedWebhooks+`),
+		event AS (
+			INSERT INTO webhook_events (id, user_id, type, event_key, payload)
+			SELECT $3::uuid, $1, $2, $4::text, $5::jsonb WHERE EXISTS (SELECT 1 FROM subscribed)
+			ON CONFLICT (user_id, type, event_key) DO NOTHING
+			RETURNING id
+		)
+		INSERT INTO webhook_deliveries (webhook_id, event_id)
+		SELECT subscribed.id, event.id FROM subscribed, event
+	`, userID, eventType, event.ID, eventKey, payload)
+	return err
+}
@@ -10,6 +10,7 @@ const SelectWordsPage = () => {
     const [dailyGoal, setDailyGoal] = useState(100);
     const [wordCount, setWordCount] = useState(10);
     const [order, setOrder] = useState('sequential');
+    const [mode, setMode] = useState('unseen');
     const [fetchedWords, setFetchedWords] = useState([]);
 
     const navigate = useNavigate();
@@ -37,6 +38,7 @@ const SelectWordsPage = () => {
                     source: selectedSource,
                     count: wordCount,
                     order: order,
+                    mode: mode,
                 },
             });
             setFetchedWords(response.data);
@@ -159,6 +161,15 @@ const SelectWordsPage = () => {
                     </div>
                 </div>
 
+                <div className="mt-4">
+                    <label className="block mb-1">选词范围:</label>
+                    <div className="flex space-x-2">
+                        {[['unseen', '未学过'], ['due', '待复习'], ['weakest', '最薄弱'], ['all', '全部']].map(([value, label]) => (
+                            <button key={value} onClick={() => setMode(value)} className={`px-4 py-2 rounded ${mode === value ? 'bg-blue-500 text-white' : 'bg-gray-200 dark:bg-gray-600'}`}>{label}</button>
+                        ))}
+                    </div>
+                </div>
+
                 <div className="mt-4">
                     <label className="block mb-1">选择方式:</label>
                     <div className="flex space-x-2">